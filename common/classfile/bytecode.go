@@ -0,0 +1,181 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package classfile
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// opcodes used by static analysis, see https://docs.oracle.com/javase/specs/jvms/se21/html/jvms-6.html
+const (
+	OpIconst0         byte = 0x03
+	OpIconst1         byte = 0x04
+	OpLdc             byte = 0x12
+	OpLdcW            byte = 0x13
+	OpGetstatic       byte = 0xb2
+	OpPutstatic       byte = 0xb3
+	OpGetfield        byte = 0xb4
+	OpPutfield        byte = 0xb5
+	OpInvokevirtual   byte = 0xb6
+	OpInvokespecial   byte = 0xb7
+	OpInvokestatic    byte = 0xb8
+	OpInvokeinterface byte = 0xb9
+	OpInvokedynamic   byte = 0xba
+	OpNew             byte = 0xbb
+
+	opTableswitch  byte = 0xaa
+	opLookupswitch byte = 0xab
+	opWide         byte = 0xc4
+	opIinc         byte = 0x84
+)
+
+// Instruction is a single jvm instruction in bytecode.
+type Instruction struct {
+	// Offset is the offset of instruction in bytecode.
+	Offset int
+
+	// Opcode is the opcode of instruction.
+	Opcode byte
+
+	// Operands are the raw operand bytes following the opcode.
+	Operands []byte
+}
+
+// Index return the constant pool index operand of instructions like ldc, getstatic and invokevirtual.
+func (i Instruction) Index() uint16 {
+	switch {
+	case i.Opcode == OpLdc && len(i.Operands) >= 1:
+		return uint16(i.Operands[0])
+	case len(i.Operands) >= 2:
+		return binary.BigEndian.Uint16(i.Operands)
+	default:
+		return 0
+	}
+}
+
+// IsInvoke return true if the instruction invokes a method.
+func (i Instruction) IsInvoke() bool {
+	return i.Opcode >= OpInvokevirtual && i.Opcode <= OpInvokeinterface
+}
+
+// IsFieldAccess return true if the instruction reads or writes a field.
+func (i Instruction) IsFieldAccess() bool {
+	return i.Opcode >= OpGetstatic && i.Opcode <= OpPutfield
+}
+
+// instructionLengths is the length of each instruction including opcode,
+// 0 means the length is variable or the opcode is not defined.
+var instructionLengths = func() [256]int {
+	lengths := [256]int{}
+	set := func(from, to int, length int) {
+		for op := from; op <= to; op++ {
+			lengths[op] = length
+		}
+	}
+	set(0x00, 0x0f, 1) // nop ... dconst_1
+	set(0x10, 0x10, 2) // bipush
+	set(0x11, 0x11, 3) // sipush
+	set(0x12, 0x12, 2) // ldc
+	set(0x13, 0x14, 3) // ldc_w, ldc2_w
+	set(0x15, 0x19, 2) // iload ... aload
+	set(0x1a, 0x35, 1) // iload_0 ... saload
+	set(0x36, 0x3a, 2) // istore ... astore
+	set(0x3b, 0x83, 1) // istore_0 ... lxor
+	set(0x84, 0x84, 3) // iinc
+	set(0x85, 0x98, 1) // i2l ... dcmpg
+	set(0x99, 0xa8, 3) // ifeq ... jsr
+	set(0xa9, 0xa9, 2) // ret
+	set(0xac, 0xb1, 1) // ireturn ... return
+	set(0xb2, 0xb8, 3) // getstatic ... invokestatic
+	set(0xb9, 0xba, 5) // invokeinterface, invokedynamic
+	set(0xbb, 0xbb, 3) // new
+	set(0xbc, 0xbc, 2) // newarray
+	set(0xbd, 0xbd, 3) // anewarray
+	set(0xbe, 0xbf, 1) // arraylength, athrow
+	set(0xc0, 0xc1, 3) // checkcast, instanceof
+	set(0xc2, 0xc3, 1) // monitorenter, monitorexit
+	set(0xc5, 0xc5, 4) // multianewarray
+	set(0xc6, 0xc7, 3) // ifnull, ifnonnull
+	set(0xc8, 0xc9, 5) // goto_w, jsr_w
+	return lengths
+}()
+
+// Instructions decode the bytecode to instructions.
+func (code *Code) Instructions() ([]Instruction, error) {
+	bytecode := code.Bytecode
+	instructions := make([]Instruction, 0, len(bytecode)/2)
+	for pc := 0; pc < len(bytecode); {
+		length, err := instructionLength(bytecode, pc)
+		if err != nil {
+			return nil, err
+		}
+		if pc+length > len(bytecode) {
+			return nil, fmt.Errorf("truncated instruction %x at %d", bytecode[pc], pc)
+		}
+		instructions = append(instructions, Instruction{
+			Offset:   pc,
+			Opcode:   bytecode[pc],
+			Operands: bytecode[pc+1 : pc+length],
+		})
+		pc += length
+	}
+	return instructions, nil
+}
+
+func instructionLength(bytecode []byte, pc int) (int, error) {
+	opcode := bytecode[pc]
+	if length := instructionLengths[opcode]; length != 0 {
+		return length, nil
+	}
+
+	readInt := func(offset int) (int, error) {
+		if offset+4 > len(bytecode) {
+			return 0, fmt.Errorf("truncated switch instruction at %d", pc)
+		}
+		return int(int32(binary.BigEndian.Uint32(bytecode[offset:]))), nil
+	}
+
+	// operands of switch instructions are aligned to 4 bytes from the start of bytecode
+	padding := (4 - (pc+1)%4) % 4
+	switch opcode {
+	case opTableswitch:
+		low, err := readInt(pc + 1 + padding + 4)
+		if err != nil {
+			return 0, err
+		}
+		high, err := readInt(pc + 1 + padding + 8)
+		if err != nil {
+			return 0, err
+		}
+		return 1 + padding + 12 + (high-low+1)*4, nil
+
+	case opLookupswitch:
+		pairs, err := readInt(pc + 1 + padding + 4)
+		if err != nil {
+			return 0, err
+		}
+		return 1 + padding + 8 + pairs*8, nil
+
+	case opWide:
+		if pc+1 < len(bytecode) && bytecode[pc+1] == opIinc {
+			return 6, nil
+		}
+		return 4, nil
+
+	default:
+		return 0, fmt.Errorf("unknown opcode %x at %d", opcode, pc)
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package classfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// Magic is the magic number at the head of every java class file.
+	Magic uint32 = 0xCAFEBABE
+
+	// javaReleaseOffset is the difference between class major version and java feature release.
+	// e.g. major version 52 is java 8, major version 61 is java 17.
+	javaReleaseOffset = 44
+)
+
+// Version is the version of a java class file.
+type Version struct {
+	Major uint16
+	Minor uint16
+}
+
+// JavaRelease return the lowest java feature release which is able to load the class.
+func (v Version) JavaRelease() int {
+	return int(v.Major) - javaReleaseOffset
+}
+
+// MajorVersionOf return the highest class major version supported by given java feature release.
+func MajorVersionOf(javaRelease int) uint16 {
+	return uint16(javaRelease + javaReleaseOffset)
+}
+
+// ReadVersion read the header of class file and return its version.
+func ReadVersion(r io.Reader) (Version, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Version{}, fmt.Errorf("failed to read class file header: %w", err)
+	}
+
+	if magic := binary.BigEndian.Uint32(header[0:4]); magic != Magic {
+		return Version{}, fmt.Errorf("invalid class file magic %x", magic)
+	}
+
+	return Version{
+		Minor: binary.BigEndian.Uint16(header[4:6]),
+		Major: binary.BigEndian.Uint16(header[6:8]),
+	}, nil
+}
@@ -0,0 +1,98 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package classfile_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/classfile"
+	"github.com/koupleless/arkctl/common/classfile/classfiletest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadVersion(t *testing.T) {
+	version, err := classfile.ReadVersion(bytes.NewReader(classfiletest.NewClass("com/foo/Foo", 61).Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, classfile.Version{Major: 61}, version)
+	assert.Equal(t, 17, version.JavaRelease())
+	assert.Equal(t, uint16(52), classfile.MajorVersionOf(8))
+
+	_, err = classfile.ReadVersion(bytes.NewReader([]byte("PK\x03\x04\x00\x00\x00\x00")))
+	assert.NotNil(t, err)
+}
+
+func TestParse(t *testing.T) {
+	content := classfiletest.NewClass("com/foo/Foo", 52).
+		Method("start", "()V",
+			classfiletest.Ldc("worker"),
+			classfiletest.Op(classfile.OpIconst1),
+			classfiletest.Member(classfile.OpInvokevirtual, "java/lang/Thread", "setDaemon", "(Z)V"),
+			classfiletest.Member(classfile.OpInvokeinterface, "java/util/Map", "put", "(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;"),
+			classfiletest.Op(0xb1),
+		).
+		Bytes()
+
+	class, err := classfile.Parse(bytes.NewReader(content))
+	assert.Nil(t, err)
+	assert.Equal(t, "com/foo/Foo", class.ThisClass)
+	assert.Equal(t, "java/lang/Object", class.SuperClass)
+	assert.Equal(t, "Foo.java", class.SourceFile)
+	assert.Equal(t, 1, len(class.Methods))
+
+	method := class.Methods[0]
+	assert.Equal(t, "start", method.Name)
+	assert.Equal(t, "()V", method.Descriptor)
+
+	instructions, err := method.Code.Instructions()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(instructions))
+	assert.Equal(t, "worker", class.String(instructions[0].Index()))
+
+	ref, ok := class.MemberRef(instructions[2].Index())
+	assert.True(t, ok)
+	assert.Equal(t, classfile.MemberRef{Owner: "java/lang/Thread", Name: "setDaemon", Descriptor: "(Z)V"}, ref)
+	assert.True(t, instructions[2].IsInvoke())
+	assert.Equal(t, 3, method.Code.LineOf(instructions[2].Offset))
+
+	ref, ok = class.MemberRef(instructions[3].Index())
+	assert.True(t, ok)
+	assert.Equal(t, "java/util/Map", ref.Owner)
+	assert.Equal(t, 4, len(instructions[3].Operands))
+}
+
+func TestInstructions_Switch(t *testing.T) {
+	// iload_0, tableswitch (padding 2, default, low 0, high 1, 2 offsets), return
+	code := &classfile.Code{Bytecode: []byte{
+		0x1a,
+		0xaa, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 1,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0xb1,
+	}}
+	instructions, err := code.Instructions()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(instructions))
+	assert.Equal(t, 24, instructions[2].Offset)
+}
+
+func TestParse_Malformed(t *testing.T) {
+	content := classfiletest.NewClass("com/foo/Foo", 52).Bytes()
+	_, err := classfile.Parse(bytes.NewReader(content[:20]))
+	assert.NotNil(t, err)
+}
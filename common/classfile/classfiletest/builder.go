@@ -0,0 +1,182 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package classfiletest assembles minimal java class files for tests.
+package classfiletest
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Class assembles a java class file with methods made of given instructions.
+type Class struct {
+	name    string
+	super   string
+	major   uint16
+	pool    [][]byte
+	indexes map[string]uint16
+	methods [][]byte
+}
+
+// Instruction is an instruction of method, resolved against constant pool when assembled.
+type Instruction func(class *Class) []byte
+
+// NewClass return a class extends java/lang/Object with given internal name, e.g. com/foo/Foo.
+func NewClass(name string, major uint16) *Class {
+	return &Class{
+		name:    name,
+		super:   "java/lang/Object",
+		major:   major,
+		pool:    [][]byte{nil},
+		indexes: map[string]uint16{},
+	}
+}
+
+// Extends set the super class of class.
+func (c *Class) Extends(super string) *Class {
+	c.super = super
+	return c
+}
+
+// Method add a method with given bytecode, line number of each instruction is its index + 1.
+func (c *Class) Method(name, descriptor string, instructions ...Instruction) *Class {
+	code := &bytes.Buffer{}
+	lines := &bytes.Buffer{}
+	for i, instruction := range instructions {
+		writeU2(lines, uint16(code.Len()))
+		writeU2(lines, uint16(i+1))
+		code.Write(instruction(c))
+	}
+
+	lineTable := &bytes.Buffer{}
+	writeU2(lineTable, uint16(len(instructions)))
+	lineTable.Write(lines.Bytes())
+
+	attribute := &bytes.Buffer{}
+	writeU2(attribute, 16) // max stack
+	writeU2(attribute, 16) // max locals
+	writeU4(attribute, uint32(code.Len()))
+	attribute.Write(code.Bytes())
+	writeU2(attribute, 0) // exception table
+	writeU2(attribute, 1)
+	writeU2(attribute, c.utf8("LineNumberTable"))
+	writeU4(attribute, uint32(lineTable.Len()))
+	attribute.Write(lineTable.Bytes())
+
+	method := &bytes.Buffer{}
+	writeU2(method, 0x0001) // public
+	writeU2(method, c.utf8(name))
+	writeU2(method, c.utf8(descriptor))
+	writeU2(method, 1)
+	writeU2(method, c.utf8("Code"))
+	writeU4(method, uint32(attribute.Len()))
+	method.Write(attribute.Bytes())
+	c.methods = append(c.methods, method.Bytes())
+	return c
+}
+
+// Bytes return the assembled class file.
+func (c *Class) Bytes() []byte {
+	thisClass := c.class(c.name)
+	superClass := c.class(c.super)
+	sourceFile := c.utf8("SourceFile")
+	sourceName := c.utf8(c.name[bytes.LastIndexByte([]byte(c.name), '/')+1:] + ".java")
+
+	buf := &bytes.Buffer{}
+	writeU4(buf, 0xCAFEBABE)
+	writeU2(buf, 0)
+	writeU2(buf, c.major)
+	writeU2(buf, uint16(len(c.pool)))
+	for _, entry := range c.pool[1:] {
+		buf.Write(entry)
+	}
+	writeU2(buf, 0x0021) // public super
+	writeU2(buf, thisClass)
+	writeU2(buf, superClass)
+	writeU2(buf, 0) // interfaces
+	writeU2(buf, 0) // fields
+	writeU2(buf, uint16(len(c.methods)))
+	for _, method := range c.methods {
+		buf.Write(method)
+	}
+	writeU2(buf, 1)
+	writeU2(buf, sourceFile)
+	writeU4(buf, 2)
+	writeU2(buf, sourceName)
+	return buf.Bytes()
+}
+
+// Op return an instruction without operands, e.g. iconst_1.
+func Op(opcode byte) Instruction {
+	return func(*Class) []byte {
+		return []byte{opcode}
+	}
+}
+
+// Ldc return a ldc_w instruction loading given string.
+func Ldc(value string) Instruction {
+	return func(c *Class) []byte {
+		index := c.add("string:"+value, append([]byte{8}, u2(c.utf8(value))...))
+		return append([]byte{0x13}, u2(index)...)
+	}
+}
+
+// Member return an instruction referencing a field or method, like getstatic or invokevirtual.
+func Member(opcode byte, owner, name, descriptor string) Instruction {
+	return func(c *Class) []byte {
+		tag := byte(10)
+		switch {
+		case opcode >= 0xb2 && opcode <= 0xb5:
+			tag = 9
+		case opcode == 0xb9:
+			tag = 11
+		}
+		nameAndType := c.add("nat:"+name+descriptor, append(append([]byte{12}, u2(c.utf8(name))...), u2(c.utf8(descriptor))...))
+		index := c.add(string(tag)+owner+"."+name+descriptor, append(append([]byte{tag}, u2(c.class(owner))...), u2(nameAndType)...))
+		if opcode == 0xb9 {
+			return append(append([]byte{opcode}, u2(index)...), 1, 0)
+		}
+		return append([]byte{opcode}, u2(index)...)
+	}
+}
+
+func (c *Class) utf8(value string) uint16 {
+	return c.add("utf8:"+value, append(append([]byte{1}, u2(uint16(len(value)))...), value...))
+}
+
+func (c *Class) class(name string) uint16 {
+	return c.add("class:"+name, append([]byte{7}, u2(c.utf8(name))...))
+}
+
+func (c *Class) add(key string, entry []byte) uint16 {
+	if index, ok := c.indexes[key]; ok {
+		return index
+	}
+	c.pool = append(c.pool, entry)
+	c.indexes[key] = uint16(len(c.pool) - 1)
+	return c.indexes[key]
+}
+
+func u2(value uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, value)
+}
+
+func writeU2(buf *bytes.Buffer, value uint16) {
+	buf.Write(u2(value))
+}
+
+func writeU4(buf *bytes.Buffer, value uint32) {
+	buf.Write(binary.BigEndian.AppendUint32(nil, value))
+}
@@ -0,0 +1,320 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package classfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// constant pool tags, see https://docs.oracle.com/javase/specs/jvms/se21/html/jvms-4.html#jvms-4.4
+const (
+	tagUtf8               = 1
+	tagInteger            = 3
+	tagFloat              = 4
+	tagLong               = 5
+	tagDouble             = 6
+	tagClass              = 7
+	tagString             = 8
+	tagFieldref           = 9
+	tagMethodref          = 10
+	tagInterfaceMethodref = 11
+	tagNameAndType        = 12
+	tagMethodHandle       = 15
+	tagMethodType         = 16
+	tagDynamic            = 17
+	tagInvokeDynamic      = 18
+	tagModule             = 19
+	tagPackage            = 20
+)
+
+type constant struct {
+	tag   byte
+	utf8  string
+	index [2]uint16
+}
+
+// ClassFile is the parsed java class file.
+// Only the parts necessary for static analysis are kept.
+type ClassFile struct {
+	Version     Version
+	AccessFlags uint16
+	ThisClass   string
+	SuperClass  string
+	Interfaces  []string
+	Fields      []Member
+	Methods     []Member
+	SourceFile  string
+
+	constantPool []constant
+}
+
+// Member is a field or method of class.
+type Member struct {
+	AccessFlags uint16
+	Name        string
+	Descriptor  string
+
+	// Code is the bytecode of method, nil if it's a field or abstract/native method.
+	Code *Code
+}
+
+// LineNumber maps the start offset of bytecode to source line.
+type LineNumber struct {
+	StartPC int
+	Line    int
+}
+
+// Code is the Code attribute of method.
+type Code struct {
+	Bytecode    []byte
+	LineNumbers []LineNumber
+}
+
+// MemberRef is the resolved Fieldref, Methodref or InterfaceMethodref constant.
+type MemberRef struct {
+	Owner      string
+	Name       string
+	Descriptor string
+}
+
+// Parse parse the java class file from r.
+func Parse(r io.Reader) (*ClassFile, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{reader: bytes.NewReader(content)}
+	class := p.parseClass()
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse class file: %w", p.err)
+	}
+	return class, nil
+}
+
+// parser keeps the first error, all reads after it become no-op.
+type parser struct {
+	reader *bytes.Reader
+	err    error
+}
+
+func (p *parser) u1() byte {
+	if p.err != nil {
+		return 0
+	}
+	b, err := p.reader.ReadByte()
+	p.err = err
+	return b
+}
+
+func (p *parser) u2() uint16 {
+	return binary.BigEndian.Uint16(p.bytes(2))
+}
+
+func (p *parser) u4() uint32 {
+	return binary.BigEndian.Uint32(p.bytes(4))
+}
+
+func (p *parser) bytes(n int) []byte {
+	buf := make([]byte, n)
+	if p.err != nil {
+		return buf
+	}
+	_, p.err = io.ReadFull(p.reader, buf)
+	return buf
+}
+
+func (p *parser) parseClass() *ClassFile {
+	if magic := p.u4(); p.err == nil && magic != Magic {
+		p.err = fmt.Errorf("invalid class file magic %x", magic)
+	}
+
+	class := &ClassFile{}
+	class.Version.Minor = p.u2()
+	class.Version.Major = p.u2()
+	class.constantPool = p.parseConstantPool()
+	class.AccessFlags = p.u2()
+	class.ThisClass = class.className(p.u2())
+	class.SuperClass = class.className(p.u2())
+
+	interfaceCount := int(p.u2())
+	for i := 0; i < interfaceCount && p.err == nil; i++ {
+		class.Interfaces = append(class.Interfaces, class.className(p.u2()))
+	}
+
+	class.Fields = p.parseMembers(class)
+	class.Methods = p.parseMembers(class)
+
+	attributeCount := int(p.u2())
+	for i := 0; i < attributeCount && p.err == nil; i++ {
+		name := class.utf8(p.u2())
+		content := p.bytes(int(p.u4()))
+		if name == "SourceFile" && len(content) == 2 {
+			class.SourceFile = class.utf8(binary.BigEndian.Uint16(content))
+		}
+	}
+	return class
+}
+
+func (p *parser) parseConstantPool() []constant {
+	count := int(p.u2())
+	pool := make([]constant, count)
+	for i := 1; i < count && p.err == nil; i++ {
+		c := constant{tag: p.u1()}
+		switch c.tag {
+		case tagUtf8:
+			c.utf8 = string(p.bytes(int(p.u2())))
+		case tagClass, tagString, tagMethodType, tagModule, tagPackage:
+			c.index[0] = p.u2()
+		case tagFieldref, tagMethodref, tagInterfaceMethodref, tagNameAndType, tagDynamic, tagInvokeDynamic:
+			c.index[0] = p.u2()
+			c.index[1] = p.u2()
+		case tagInteger, tagFloat:
+			p.bytes(4)
+		case tagLong, tagDouble:
+			p.bytes(8)
+			// long and double take two entries in constant pool
+			i++
+		case tagMethodHandle:
+			p.u1()
+			c.index[0] = p.u2()
+		default:
+			if p.err == nil {
+				p.err = fmt.Errorf("unknown constant pool tag %d at %d", c.tag, i)
+			}
+		}
+		if i < count {
+			pool[i] = c
+		}
+	}
+	return pool
+}
+
+func (p *parser) parseMembers(class *ClassFile) []Member {
+	count := int(p.u2())
+	members := make([]Member, 0, count)
+	for i := 0; i < count && p.err == nil; i++ {
+		member := Member{
+			AccessFlags: p.u2(),
+			Name:        class.utf8(p.u2()),
+			Descriptor:  class.utf8(p.u2()),
+		}
+		attributeCount := int(p.u2())
+		for j := 0; j < attributeCount && p.err == nil; j++ {
+			name := class.utf8(p.u2())
+			content := p.bytes(int(p.u4()))
+			if name == "Code" && p.err == nil {
+				member.Code = class.parseCode(content)
+			}
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// parseCode parse the Code attribute, malformed attributes are ignored silently.
+func (c *ClassFile) parseCode(content []byte) *Code {
+	p := &parser{reader: bytes.NewReader(content)}
+	p.u2() // max stack
+	p.u2() // max locals
+	code := &Code{Bytecode: p.bytes(int(p.u4()))}
+
+	exceptionTableLength := int(p.u2())
+	p.bytes(exceptionTableLength * 8)
+
+	attributeCount := int(p.u2())
+	for i := 0; i < attributeCount && p.err == nil; i++ {
+		name := c.utf8(p.u2())
+		attribute := p.bytes(int(p.u4()))
+		if name != "LineNumberTable" || p.err != nil {
+			continue
+		}
+
+		lines := &parser{reader: bytes.NewReader(attribute)}
+		lineCount := int(lines.u2())
+		for j := 0; j < lineCount && lines.err == nil; j++ {
+			code.LineNumbers = append(code.LineNumbers, LineNumber{
+				StartPC: int(lines.u2()),
+				Line:    int(lines.u2()),
+			})
+		}
+	}
+	return code
+}
+
+func (c *ClassFile) constant(index uint16) constant {
+	if int(index) >= len(c.constantPool) {
+		return constant{}
+	}
+	return c.constantPool[index]
+}
+
+func (c *ClassFile) utf8(index uint16) string {
+	return c.constant(index).utf8
+}
+
+func (c *ClassFile) className(index uint16) string {
+	if index == 0 {
+		return ""
+	}
+	return c.utf8(c.constant(index).index[0])
+}
+
+// ClassName return the internal name of class referenced by constant pool entry at index,
+// e.g. java/lang/Thread.
+func (c *ClassFile) ClassName(index uint16) string {
+	return c.className(index)
+}
+
+// String return the string literal referenced by constant pool entry at index,
+// empty if the entry is not a string.
+func (c *ClassFile) String(index uint16) string {
+	entry := c.constant(index)
+	if entry.tag != tagString {
+		return ""
+	}
+	return c.utf8(entry.index[0])
+}
+
+// MemberRef resolve the field or method referenced by constant pool entry at index.
+func (c *ClassFile) MemberRef(index uint16) (MemberRef, bool) {
+	entry := c.constant(index)
+	switch entry.tag {
+	case tagFieldref, tagMethodref, tagInterfaceMethodref:
+	default:
+		return MemberRef{}, false
+	}
+
+	nameAndType := c.constant(entry.index[1])
+	return MemberRef{
+		Owner:      c.className(entry.index[0]),
+		Name:       c.utf8(nameAndType.index[0]),
+		Descriptor: c.utf8(nameAndType.index[1]),
+	}, true
+}
+
+// LineOf return the source line of bytecode at offset, 0 if unknown.
+func (code *Code) LineOf(offset int) int {
+	line, startPC := 0, -1
+	for _, lineNumber := range code.LineNumbers {
+		if lineNumber.StartPC <= offset && lineNumber.StartPC > startPC {
+			line, startPC = lineNumber.Line, lineNumber.StartPC
+		}
+	}
+	return line
+}
@@ -41,6 +41,10 @@ type Command interface {
 	// is stderr is not empty, send an error
 	Wait() <-chan error
 
+	// Done return a channel closed once the command exits, after which GetExitError tells how it exits.
+	// Wait may finish before the command exits if stderr is not empty.
+	Done() <-chan struct{}
+
 	// GetExitState return the exit state of command
 	GetExitError() error
 
@@ -71,6 +75,7 @@ func BuildCommandWithWorkDir(
 		args:           args,
 		output:         make(chan string, 1),
 		completeSignal: make(chan error, 1),
+		exited:         make(chan struct{}),
 		cancel:         cancelFunc,
 	}
 }
@@ -85,6 +90,7 @@ type command struct {
 	cancel         context.CancelFunc
 	output         chan string
 	completeSignal chan error
+	exited         chan struct{}
 	exitState      error
 }
 
@@ -157,6 +163,7 @@ func (c *command) Exec() error {
 
 	go func() {
 		c.exitState = execCmd.Wait()
+		close(c.exited)
 		closeCompleteSignal(nil)
 	}()
 
@@ -167,6 +174,10 @@ func (c *command) Wait() <-chan error {
 	return c.completeSignal
 }
 
+func (c *command) Done() <-chan struct{} {
+	return c.exited
+}
+
 func (c *command) Kill() error {
 	c.cancel()
 	return nil
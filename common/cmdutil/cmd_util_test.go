@@ -71,6 +71,25 @@ func TestCommand_StdErr(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestCommand_Done(t *testing.T) {
+	cmd := BuildCommand(context.Background(), "sh", "-c", "echo failed >&2; exit 3")
+
+	err := cmd.Exec()
+	assert.Nil(t, err)
+
+	for range cmd.Output() {
+	}
+	for err := range cmd.Wait() {
+		assert.Equal(t, "failed\n", err.Error())
+	}
+
+	// the exit state is known once done, even if wait finishes as stderr is closed
+	<-cmd.Done()
+	err = cmd.GetExitError()
+	assert.NotNil(t, err)
+	assert.Equal(t, "exit status 3", err.Error())
+}
+
 func TestCommand_WrongCommannd(t *testing.T) {
 	cmd := BuildCommand(context.Background(), "not_exist_command")
 	err := cmd.Exec()
@@ -0,0 +1,153 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// Type is the type of event.
+type Type string
+
+const (
+	// TypeStageStarted is emitted before a stage runs.
+	TypeStageStarted Type = "stage.started"
+
+	// TypeStageFinished is emitted after a stage runs or is skipped, with its status and duration.
+	TypeStageFinished Type = "stage.finished"
+
+	// TypeLog is a line of output of the build tool or a custom stage.
+	TypeLog Type = "log"
+
+	// TypeUploadProgress is the bytes of bundle uploaded to target.
+	TypeUploadProgress Type = "upload.progress"
+
+	// TypeBizState is the state of biz, e.g. INSTALLING, ACTIVATED, BROKEN, UNINSTALLING and UNINSTALLED
+	// when it's deployed, or the state reported by the base when it's queried.
+	TypeBizState Type = "biz.state"
+
+	// TypeModuleFinished is emitted after the biz of a module is deployed or fails to deploy,
+	// when several modules are deployed in one run.
+	TypeModuleFinished Type = "module.finished"
+
+	// TypeError is an error with the suggestions telling how to fix it.
+	TypeError Type = "error"
+
+	// TypeDone is the last event, with the exit code of command.
+	TypeDone Type = "done"
+)
+
+// the states of biz when it's deployed, the states reported by the base are emitted as they are
+const (
+	StateInstalling   = "INSTALLING"
+	StateActivated    = "ACTIVATED"
+	StateBroken       = "BROKEN"
+	StateUninstalling = "UNINSTALLING"
+	StateUninstalled  = "UNINSTALLED"
+)
+
+// Event is a JSON object in the event stream, the fields not used by its type are omitted, so are the zero numbers.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type Type      `json:"type"`
+
+	Module     string   `json:"module,omitempty"`
+	Stage      string   `json:"stage,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	DurationMs int64    `json:"durationMs,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Plan       []string `json:"plan,omitempty"`
+
+	Line string `json:"line,omitempty"`
+
+	Done  int64 `json:"done,omitempty"`
+	Total int64 `json:"total,omitempty"`
+
+	// Biz is the biz in the format of {bizName}:{bizVersion}
+	Biz   string `json:"biz,omitempty"`
+	State string `json:"state,omitempty"`
+
+	Message     string   `json:"message,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	ExitCode    int      `json:"exitCode,omitempty"`
+}
+
+// Emitter writes events to w as newline delimited JSON, it's safe for concurrent use.
+type Emitter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	now     func() time.Time
+}
+
+// NewEmitter return an emitter writing to w.
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{encoder: json.NewEncoder(w), now: time.Now}
+}
+
+// Emit write the event, the time of event is set if it's zero.
+func (e *Emitter) Emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = e.now()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_ = e.encoder.Encode(event)
+}
+
+const (
+	// OutputText is the human output printed by pterm, which is the default.
+	OutputText = "text"
+
+	// OutputEvents is the event stream, with the human output suppressed.
+	OutputEvents = "events"
+)
+
+var defaultEmitter *Emitter
+
+// SetOutput switch to the event stream written to w if output is events.
+func SetOutput(output string, w io.Writer) error {
+	switch output {
+	case "", OutputText:
+		return nil
+	case OutputEvents:
+		defaultEmitter = NewEmitter(w)
+		pterm.DisableOutput()
+		return nil
+	}
+	return fmt.Errorf("unsupported output %s, should be one of %s and %s", output, OutputText, OutputEvents)
+}
+
+// Enabled return true if the event stream is the output.
+func Enabled() bool {
+	return defaultEmitter != nil
+}
+
+// Emit write the event to the event stream, it does nothing unless the event stream is the output.
+func Emit(event Event) {
+	if defaultEmitter != nil {
+		defaultEmitter.Emit(event)
+	}
+}
+
+// EmitBizState emit the state of biz.
+func EmitBizState(bizName, bizVersion, state string) {
+	Emit(Event{Type: TypeBizState, Biz: bizName + ":" + bizVersion, State: state})
+}
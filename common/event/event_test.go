@@ -0,0 +1,67 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	emitter := NewEmitter(buf)
+	emitter.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	emitter.Emit(Event{Type: TypeStageStarted, Stage: "build"})
+	emitter.Emit(Event{Type: TypeDone, ExitCode: 3})
+	assert.Equal(t, `{"time":"2024-01-01T00:00:00Z","type":"stage.started","stage":"build"}
+{"time":"2024-01-01T00:00:00Z","type":"done","exitCode":3}
+`, buf.String())
+}
+
+func TestEmitter_Concurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	emitter := NewEmitter(buf)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			emitter.Emit(Event{Type: TypeLog, Line: strings.Repeat("x", 100)})
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 50)
+	for _, line := range lines {
+		event := Event{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, TypeLog, event.Type)
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	assert.Nil(t, SetOutput(OutputText, &bytes.Buffer{}))
+	assert.False(t, Enabled())
+	assert.EqualError(t, SetOutput("yaml", &bytes.Buffer{}), "unsupported output yaml, should be one of text and events")
+}
@@ -0,0 +1,133 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Code is the exit code of arkctl, shared by all commands so that scripts could tell why it fails.
+type Code int
+
+const (
+	OK Code = 0
+
+	// Failure is the code of failures not classified below.
+	Failure Code = 1
+
+	// Usage is the code of invalid args, flags or config.
+	Usage Code = 2
+
+	// BuildFailed is the code of failing to build the biz bundle.
+	BuildFailed Code = 3
+
+	// TargetUnreachable is the code of failing to reach the target base, or the pod it runs in.
+	TargetUnreachable Code = 4
+
+	// InstallRejected is the code of the target base refusing to install or uninstall the biz.
+	InstallRejected Code = 5
+
+	// ActivationTimeout is the code of the biz not activated in time.
+	ActivationTimeout Code = 6
+
+	// ProbeFailed is the code of the biz installed but failing the smoke test of its web endpoint.
+	ProbeFailed Code = 7
+)
+
+// Help is the document of exit codes, which is shown in the help of all commands.
+const Help = `
+Exit Codes:
+  0  success
+  1  failure not classified below
+  2  usage error, e.g. invalid args, flags or config
+  3  build failure
+  4  target base or pod unreachable
+  5  install or uninstall rejected by target base
+  6  biz not activated in time
+  7  biz installed but failing the smoke test of its web endpoint
+`
+
+// Error is an error with the exit code telling its kind.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap return err with the given exit code, or nil if err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of return the exit code of err, which is Failure if err is not wrapped with a code.
+func Of(err error) Code {
+	if err == nil {
+		return OK
+	}
+	codeErr := &Error{}
+	if errors.As(err, &codeErr) {
+		return codeErr.Code
+	}
+	return Failure
+}
+
+// unreachableMessages are the messages of kubectl and curl, telling the pod or the base in it is unreachable.
+var unreachableMessages = []string{
+	"connection refused",
+	"Failed to connect to",
+	"Unable to connect to the server",
+	"unable to upgrade connection",
+	"no such host",
+}
+
+// OfTargetError return the exit code of err got from the target base, which is
+// TargetUnreachable if the base could not be connected, ActivationTimeout if it does not respond in time,
+// the code wrapped in err if any, or fallback otherwise.
+func OfTargetError(err error, fallback Code) Code {
+	if code := Of(err); code != Failure {
+		return code
+	}
+
+	opErr := &net.OpError{}
+	dnsErr := &net.DNSError{}
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), errors.As(err, &dnsErr):
+		return TargetUnreachable
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return TargetUnreachable
+	case errors.Is(err, context.DeadlineExceeded):
+		return ActivationTimeout
+	}
+	for _, message := range unreachableMessages {
+		if strings.Contains(err.Error(), message) {
+			return TargetUnreachable
+		}
+	}
+	return fallback
+}
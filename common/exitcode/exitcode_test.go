@@ -0,0 +1,52 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, OK, Of(nil))
+	assert.Equal(t, Failure, Of(errors.New("boom")))
+	assert.Equal(t, BuildFailed, Of(fmt.Errorf("deploy failed: %w", Wrap(BuildFailed, errors.New("boom")))))
+	assert.Nil(t, Wrap(Usage, nil))
+}
+
+func TestOfTargetError(t *testing.T) {
+	// nothing listens on the port of closed listener
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	assert.Nil(t, listener.Close())
+	_, err = http.Post("http://"+listener.Addr().String()+"/installBiz", "application/json", nil)
+	assert.Equal(t, TargetUnreachable, OfTargetError(err, InstallRejected))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, ActivationTimeout, OfTargetError(fmt.Errorf("install biz: %w", ctx.Err()), InstallRejected))
+
+	assert.Equal(t, TargetUnreachable, OfTargetError(errors.New(`curl: (7) Failed to connect to 127.0.0.1 port 1238`), InstallRejected))
+	assert.Equal(t, InstallRejected, OfTargetError(errors.New("install biz failed: biz already installed"), InstallRejected))
+	assert.Equal(t, BuildFailed, OfTargetError(Wrap(BuildFailed, errors.New("boom")), InstallRejected))
+}
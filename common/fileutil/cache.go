@@ -0,0 +1,383 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheMaxSize is the default max size of download cache.
+	DefaultCacheMaxSize int64 = 2 << 30
+
+	cacheIndexFile = "index.json"
+	cacheBlobDir   = "blobs"
+	cachePartDir   = "partial"
+	cacheLockFile  = "index.lock"
+)
+
+// CacheEntry is a downloaded file in cache.
+type CacheEntry struct {
+	// Url is where the file is downloaded from.
+	Url string `json:"url"`
+
+	// Digest is the content digest of file, e.g. sha256:{hex}.
+	Digest string `json:"digest"`
+
+	// Path is the local path of file.
+	Path string `json:"path"`
+
+	// Size is the size of file in bytes.
+	Size int64 `json:"size"`
+
+	// ETag and LastModified are used to revalidate the cached file.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	// LastAccess is the last time the file is used, used for eviction.
+	LastAccess time.Time `json:"lastAccess"`
+}
+
+// Cache is a content addressed cache of downloaded files,
+// files are stored at {dir}/blobs/sha256/{hex}/{fileName} and indexed by their urls.
+type Cache struct {
+	// Dir is the root dir of cache.
+	Dir string
+
+	// MaxSize is the max size in bytes of all cached files, the least recently used ones are evicted when exceeded.
+	MaxSize int64
+
+	// lock guards the cache against other goroutines, and the lock file against other arkctl processes.
+	lock sync.Mutex
+}
+
+type cacheIndex struct {
+	Entries map[string]*CacheEntry `json:"entries"`
+}
+
+var (
+	defaultCache = &Cache{
+		Dir:     filepath.Join(homeDir(), ".arkctl", "cache"),
+		MaxSize: DefaultCacheMaxSize,
+	}
+)
+
+// DefaultCache return the download cache at ~/.arkctl/cache.
+func DefaultCache() *Cache {
+	return defaultCache
+}
+
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.TempDir()
+}
+
+// Digest return the sha256 digest of file at localPath, in the format of sha256:{hex}.
+func Digest(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Lookup return the cached file downloaded from url.
+func (c *Cache) Lookup(url string) (*CacheEntry, bool) {
+	release, err := c.acquire()
+	if err != nil {
+		return nil, false
+	}
+	defer release()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, false
+	}
+	entry, ok := index.Entries[url]
+	if !ok {
+		return nil, false
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// LookupDigest return the cached file with given digest.
+func (c *Cache) LookupDigest(digest string) (*CacheEntry, bool) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, false
+	}
+	for i := range entries {
+		if entries[i].Digest == digest {
+			if _, err := os.Stat(entries[i].Path); err == nil {
+				return &entries[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Touch update the last access time of cached file downloaded from url.
+func (c *Cache) Touch(url string) {
+	release, err := c.acquire()
+	if err != nil {
+		return
+	}
+	defer release()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return
+	}
+	if entry, ok := index.Entries[url]; ok {
+		entry.LastAccess = time.Now()
+		_ = c.writeIndex(index)
+	}
+}
+
+// PartialPath return where to keep the partially downloaded file of url, so that the download can be resumed.
+func (c *Cache) PartialPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, cachePartDir, hex.EncodeToString(sum[:]))
+}
+
+// Put move the downloaded file into cache, and evict the least recently used files if cache is full.
+func (c *Cache) Put(entry CacheEntry, downloadedPath string, fileName string) (*CacheEntry, error) {
+	release, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	algorithm, hexDigest, ok := strings.Cut(entry.Digest, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid digest %s", entry.Digest)
+	}
+	blobDir := filepath.Join(c.Dir, cacheBlobDir, algorithm, hexDigest)
+	if err := os.MkdirAll(blobDir, 0755); err != nil {
+		return nil, err
+	}
+
+	entry.Path = filepath.Join(blobDir, fileName)
+	if _, err := os.Stat(entry.Path); err == nil {
+		// same content is already cached
+		_ = os.Remove(downloadedPath)
+	} else if err := os.Rename(downloadedPath, entry.Path); err != nil {
+		return nil, err
+	}
+	entry.LastAccess = time.Now()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	replaced, ok := index.Entries[entry.Url]
+	index.Entries[entry.Url] = &entry
+	if ok && replaced.Digest != entry.Digest && !isReferenced(index, replaced.Digest) {
+		// the content of url is changed, e.g. a SNAPSHOT, the blob is never used again
+		if err := os.RemoveAll(filepath.Dir(replaced.Path)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.evict(index, entry.Digest); err != nil {
+		return nil, err
+	}
+	return &entry, c.writeIndex(index)
+}
+
+// List return all cached files, the most recently used first.
+func (c *Cache) List() ([]CacheEntry, error) {
+	release, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CacheEntry, 0, len(index.Entries))
+	for _, entry := range index.Entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.After(entries[j].LastAccess)
+	})
+	return entries, nil
+}
+
+// Clean remove all cached files, including partially downloaded ones.
+func (c *Cache) Clean() error {
+	release, err := c.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for _, name := range []string{cacheBlobDir, cachePartDir, cacheIndexFile} {
+		if err := os.RemoveAll(filepath.Join(c.Dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isReferenced return true if any entry in index is of the blob with digest.
+func isReferenced(index *cacheIndex, digest string) bool {
+	for _, entry := range index.Entries {
+		if entry.Digest == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire lock the cache, return the func releasing it.
+func (c *Cache) acquire() (func(), error) {
+	c.lock.Lock()
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		c.lock.Unlock()
+		return nil, err
+	}
+	unlock, err := lockFile(filepath.Join(c.Dir, cacheLockFile))
+	if err != nil {
+		c.lock.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		c.lock.Unlock()
+	}, nil
+}
+
+// evict remove the least recently used blobs until the cache size is within MaxSize.
+// The blob with keepDigest is never evicted.
+func (c *Cache) evict(index *cacheIndex, keepDigest string) error {
+	type blob struct {
+		digest     string
+		path       string
+		size       int64
+		lastAccess time.Time
+	}
+
+	blobs := map[string]*blob{}
+	var total int64
+	for _, entry := range index.Entries {
+		b, ok := blobs[entry.Digest]
+		if !ok {
+			b = &blob{digest: entry.Digest, path: filepath.Dir(entry.Path), size: entry.Size}
+			blobs[entry.Digest] = b
+			total += entry.Size
+		}
+		if entry.LastAccess.After(b.lastAccess) {
+			b.lastAccess = entry.LastAccess
+		}
+	}
+	if c.MaxSize <= 0 || total <= c.MaxSize {
+		return nil
+	}
+
+	lru := make([]*blob, 0, len(blobs))
+	for _, b := range blobs {
+		lru = append(lru, b)
+	}
+	sort.Slice(lru, func(i, j int) bool {
+		return lru[i].lastAccess.Before(lru[j].lastAccess)
+	})
+
+	for _, b := range lru {
+		if total <= c.MaxSize {
+			break
+		}
+		if b.digest == keepDigest {
+			continue
+		}
+		if err := os.RemoveAll(b.path); err != nil {
+			return err
+		}
+		total -= b.size
+		for url, entry := range index.Entries {
+			if entry.Digest == b.digest {
+				delete(index.Entries, url)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cache) readIndex() (*cacheIndex, error) {
+	index := &cacheIndex{Entries: map[string]*CacheEntry{}}
+	content, err := os.ReadFile(filepath.Join(c.Dir, cacheIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, index); err != nil {
+		return nil, fmt.Errorf("corrupted cache index, run arkctl cache clean: %w", err)
+	}
+	if index.Entries == nil {
+		index.Entries = map[string]*CacheEntry{}
+	}
+	return index, nil
+}
+
+// writeIndex write index to a temp file and rename it, so that the index is never half written.
+func (c *Cache) writeIndex(index *cacheIndex) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(c.Dir, cacheIndexFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), filepath.Join(c.Dir, cacheIndexFile))
+}
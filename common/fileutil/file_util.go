@@ -17,8 +17,14 @@ package fileutil
 import (
 	"context"
 	"fmt"
-	"github.com/koupleless/arkctl/common/osutil"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/osutil"
 )
 
 // FileUrl is the url of file
@@ -29,40 +35,252 @@ func (url FileUrl) GetFileUrlType() FileUrlType {
 	case strings.HasPrefix(string(url), osutil.GetLocalFileProtocol()):
 		return FileUrlTypeLocal
 
+	case strings.HasPrefix(string(url), "http://") || strings.HasPrefix(string(url), "https://"):
+		return FileUrlTypeHttp
+
+	case strings.HasPrefix(string(url), MavenProtocol):
+		return FileUrlTypeMaven
+
+	case strings.HasPrefix(string(url), S3Protocol):
+		return FileUrlTypeS3
+
 	default:
-		panic(fmt.Sprintf("unknown file url type %s", url))
+		return FileUrlTypeUnknown
 	}
 }
 
+// IsRemote return true if the file is not on local file system.
+func (url FileUrl) IsRemote() bool {
+	return url.GetFileUrlType() != FileUrlTypeLocal && url.GetFileUrlType() != FileUrlTypeUnknown
+}
+
+// ParseFileUrl convert the file path or url given by user to FileUrl, relative paths are resolved against current dir.
+func ParseFileUrl(pathOrUrl string) (FileUrl, error) {
+	if strings.Contains(pathOrUrl, "://") {
+		return FileUrl(pathOrUrl), nil
+	}
+	abs, err := filepath.Abs(pathOrUrl)
+	if err != nil {
+		return "", err
+	}
+	return FileUrl(osutil.GetLocalFileProtocol() + abs), nil
+}
+
 type FileUrlType string
 
 const (
-	FileUrlTypeLocal FileUrlType = "local"
+	FileUrlTypeLocal   FileUrlType = "local"
+	FileUrlTypeHttp    FileUrlType = "http"
+	FileUrlTypeMaven   FileUrlType = "maven"
+	FileUrlTypeS3      FileUrlType = "s3"
+	FileUrlTypeUnknown FileUrlType = "unknown"
 )
 
 // FileUtils is an interface for all fileutil
 type FileUtils interface {
-	// Download file from fileUrl to local file system.
+	// Download file from fileUrl to local file system, and return the local file url.
+	// Remote files are downloaded into DefaultCache, maven coordinates are resolved from local and remote repositories.
 	Download(ctx context.Context, fileUrl FileUrl) (string, error)
+
+	// Open the file for random access. Remote files are read with range requests if possible,
+	// so that reading a small part of a big file like the manifest of jar does not download the whole file.
+	Open(ctx context.Context, fileUrl FileUrl) (RandomAccessFile, error)
+
+	// Upload the local file to fileUrl, e.g. s3://bucket/key.
+	Upload(ctx context.Context, localPath string, fileUrl FileUrl) error
+
+	// Presign return a http url, with which the file can be downloaded without credentials until expires.
+	Presign(ctx context.Context, fileUrl FileUrl, expires time.Duration) (string, error)
 }
 
 var (
-	defaultFileUtil FileUtils = &fileUtil{}
+	defaultHttpDownloader = &httpDownloader{
+		client: http.DefaultClient,
+		cache:  DefaultCache(),
+	}
+
+	defaultMavenResolver = &mavenResolver{
+		http: defaultHttpDownloader,
+	}
+
+	defaultS3Client = &s3Client{
+		config: S3ConfigFromEnv(),
+		http:   defaultHttpDownloader,
+		now:    time.Now,
+	}
+
+	defaultFileUtil FileUtils = &fileUtil{
+		http:  defaultHttpDownloader,
+		maven: defaultMavenResolver,
+		s3:    defaultS3Client,
+	}
 )
 
+// SetMavenSettings set the path of maven settings.xml used to resolve maven coordinates.
+func SetMavenSettings(settingsPath string) {
+	defaultMavenResolver.settingsPath = settingsPath
+}
+
+// MavenLocations return where the jar with given maven coordinate is resolved from, without fetching anything.
+func MavenLocations(coordinate MavenCoordinate) ([]string, error) {
+	return defaultMavenResolver.Locations(coordinate)
+}
+
+// SetS3Config set the config of object storage used by s3:// file urls.
+func SetS3Config(config S3Config) {
+	defaultS3Client.config = config
+}
+
+// GetS3Config return the config of object storage used by s3:// file urls.
+func GetS3Config() S3Config {
+	return defaultS3Client.config
+}
+
 // DefaultFileUtil return a default FileUtils.
 func DefaultFileUtil() FileUtils {
 	return defaultFileUtil
 }
 
 type fileUtil struct {
+	http  *httpDownloader
+	maven *mavenResolver
+	s3    *s3Client
 }
 
-func (f fileUtil) Download(_ context.Context, fileUrl FileUrl) (string, error) {
+func (f fileUtil) Download(ctx context.Context, fileUrl FileUrl) (string, error) {
 	switch fileUrl.GetFileUrlType() {
 	case FileUrlTypeLocal:
 		return (string)(fileUrl), nil
+	case FileUrlTypeHttp:
+		localPath, err := f.http.Download(ctx, string(fileUrl))
+		if err != nil {
+			return "", err
+		}
+		return osutil.GetLocalFileProtocol() + localPath, nil
+	case FileUrlTypeMaven:
+		rawCoordinate, expectedDigest, err := SplitChecksum(string(fileUrl))
+		if err != nil {
+			return "", err
+		}
+		coordinate, err := ParseMavenCoordinate(rawCoordinate)
+		if err != nil {
+			return "", err
+		}
+		localPath, err := f.maven.Resolve(ctx, coordinate)
+		if err != nil {
+			return "", err
+		}
+		if err := verifyDigest(localPath, expectedDigest); err != nil {
+			return "", fmt.Errorf("%s: %w", coordinate, err)
+		}
+		return osutil.GetLocalFileProtocol() + localPath, nil
+	case FileUrlTypeS3:
+		rawUrl, expectedDigest, err := SplitChecksum(string(fileUrl))
+		if err != nil {
+			return "", err
+		}
+		location, err := ParseS3Url(rawUrl)
+		if err != nil {
+			return "", err
+		}
+		localPath, err := f.s3.Download(ctx, location)
+		if err != nil {
+			return "", err
+		}
+		if err := verifyDigest(localPath, expectedDigest); err != nil {
+			return "", fmt.Errorf("%s: %w", location, err)
+		}
+		return osutil.GetLocalFileProtocol() + localPath, nil
+	default:
+		return "", fmt.Errorf("unknown download operation for file url %s", fileUrl)
+	}
+}
+
+func (f fileUtil) Open(ctx context.Context, fileUrl FileUrl) (RandomAccessFile, error) {
+	switch fileUrl.GetFileUrlType() {
+	case FileUrlTypeHttp:
+		return f.http.open(ctx, string(fileUrl), nil)
+	case FileUrlTypeS3:
+		rawUrl, _, err := SplitChecksum(string(fileUrl))
+		if err != nil {
+			return nil, err
+		}
+		location, err := ParseS3Url(rawUrl)
+		if err != nil {
+			return nil, err
+		}
+		return f.s3.Open(ctx, location)
 	default:
-		panic(fmt.Sprintf("unknown download operation for file url type %s", fileUrl))
+		localUrl, err := f.Download(ctx, fileUrl)
+		if err != nil {
+			return nil, err
+		}
+		return openLocalFile(strings.TrimPrefix(localUrl, osutil.GetLocalFileProtocol()))
+	}
+}
+
+func (f fileUtil) Upload(ctx context.Context, localPath string, fileUrl FileUrl) error {
+	switch fileUrl.GetFileUrlType() {
+	case FileUrlTypeLocal:
+		return copyFile(localPath, strings.TrimPrefix(string(fileUrl), osutil.GetLocalFileProtocol()))
+	case FileUrlTypeS3:
+		location, err := ParseS3Url(string(fileUrl))
+		if err != nil {
+			return err
+		}
+		return f.s3.Upload(ctx, localPath, location)
+	default:
+		return fmt.Errorf("unknown upload operation for file url %s", fileUrl)
+	}
+}
+
+func (f fileUtil) Presign(_ context.Context, fileUrl FileUrl, expires time.Duration) (string, error) {
+	switch fileUrl.GetFileUrlType() {
+	case FileUrlTypeHttp:
+		return string(fileUrl), nil
+	case FileUrlTypeS3:
+		location, err := ParseS3Url(string(fileUrl))
+		if err != nil {
+			return "", err
+		}
+		return f.s3.Presign(location, expires)
+	default:
+		return "", fmt.Errorf("unknown presign operation for file url %s", fileUrl)
+	}
+}
+
+func copyFile(source, target string) error {
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(targetFile, sourceFile); err != nil {
+		targetFile.Close()
+		return err
+	}
+	return targetFile.Close()
+}
+
+// verifyDigest return the error if the digest of file at localPath is not the expected one, nothing is expected if empty.
+func verifyDigest(localPath, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	digest, err := Digest(localPath)
+	if err != nil {
+		return err
+	}
+	if digest != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, digest)
 	}
+	return nil
 }
@@ -0,0 +1,271 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// httpHeaders are the extra headers like Authorization sent to given host, "" means all hosts.
+	httpHeaders     = map[string]http.Header{}
+	httpHeadersLock sync.RWMutex
+)
+
+// AddHttpHeader add a header sent when downloading files from given host, e.g. Authorization.
+// If host is empty, the header is sent to all hosts.
+func AddHttpHeader(host, name, value string) {
+	httpHeadersLock.Lock()
+	defer httpHeadersLock.Unlock()
+
+	if _, ok := httpHeaders[host]; !ok {
+		httpHeaders[host] = http.Header{}
+	}
+	httpHeaders[host].Set(name, value)
+}
+
+func setHttpHeaders(req *http.Request) {
+	httpHeadersLock.RLock()
+	defer httpHeadersLock.RUnlock()
+
+	for _, host := range []string{"", req.URL.Host, req.URL.Hostname()} {
+		for name, values := range httpHeaders[host] {
+			req.Header[name] = values
+		}
+	}
+}
+
+// SplitChecksum split the expected checksum given in url fragment like {url}#sha256={hex}.
+// The returned digest is in the format of sha256:{hex}, empty if not given.
+func SplitChecksum(rawUrl string) (string, string, error) {
+	requestUrl, fragment, found := strings.Cut(rawUrl, "#")
+	if !found {
+		return rawUrl, "", nil
+	}
+
+	algorithm, value, ok := strings.Cut(fragment, "=")
+	if !ok || algorithm != "sha256" {
+		return "", "", fmt.Errorf("unsupported checksum %s, only sha256={hex} is supported", fragment)
+	}
+	if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != 32 {
+		return "", "", fmt.Errorf("invalid sha256 checksum %s", value)
+	}
+	return requestUrl, "sha256:" + strings.ToLower(value), nil
+}
+
+// partialMeta is saved next to partially downloaded file, so that the download is resumed only if the file is unchanged.
+type partialMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type httpDownloader struct {
+	client *http.Client
+	cache  *Cache
+}
+
+// Download download the file at rawUrl into cache and return its local path.
+// The download is skipped if the file is cached and unchanged,
+// and partially downloaded file is resumed if the server supports range requests.
+func (d *httpDownloader) Download(ctx context.Context, rawUrl string) (string, error) {
+	return d.download(ctx, rawUrl, nil)
+}
+
+// download is the same as Download, except that the extra header is sent along with the configured ones.
+func (d *httpDownloader) download(ctx context.Context, rawUrl string, header http.Header) (string, error) {
+	requestUrl, expectedDigest, err := SplitChecksum(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	if expectedDigest != "" {
+		if entry, ok := d.cache.LookupDigest(expectedDigest); ok {
+			d.cache.Touch(entry.Url)
+			return entry.Path, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	setHttpHeaders(req)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	partialPath := d.cache.PartialPath(requestUrl)
+	meta := readPartialMeta(partialPath)
+	offset := int64(0)
+	if info, err := os.Stat(partialPath); err == nil && (meta.ETag != "" || meta.LastModified != "") {
+		offset = info.Size()
+	}
+
+	cached, hasCached := d.cache.Lookup(requestUrl)
+	switch {
+	case offset > 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if meta.ETag != "" {
+			req.Header.Set("If-Range", meta.ETag)
+		} else {
+			req.Header.Set("If-Range", meta.LastModified)
+		}
+	case hasCached && cached.ETag != "":
+		req.Header.Set("If-None-Match", cached.ETag)
+	case hasCached && cached.LastModified != "":
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY
+	body := io.Reader(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if !hasCached {
+			return "", fmt.Errorf("download %s failed: unexpected status %s", requestUrl, resp.Status)
+		}
+		d.cache.Touch(requestUrl)
+		return cached.Path, nil
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return "", fmt.Errorf("download %s failed: unexpected content range %s", requestUrl, resp.Header.Get("Content-Range"))
+		}
+		flag |= os.O_APPEND
+	case http.StatusOK:
+		// the server ignores range request or the file is changed, download from scratch
+		flag |= os.O_TRUNC
+		offset = 0
+		meta = partialMeta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		if err := writePartialMeta(partialPath, meta); err != nil {
+			return "", err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset == 0 {
+			return "", fmt.Errorf("download %s failed with status %s", requestUrl, resp.Status)
+		}
+		if resp.Header.Get("Content-Range") != fmt.Sprintf("bytes */%d", offset) {
+			// the file is changed and shorter than the partial one, download from scratch
+			_ = os.Remove(partialPath)
+			_ = os.Remove(partialPath + ".json")
+			return d.download(ctx, rawUrl, header)
+		}
+		// the partial file is complete, but failed to be put into cache last time
+		flag |= os.O_APPEND
+		body = strings.NewReader("")
+	default:
+		return "", fmt.Errorf("download %s failed with status %s", requestUrl, resp.Status)
+	}
+
+	file, err := os.OpenFile(partialPath, flag, 0644)
+	if err != nil {
+		return "", err
+	}
+	size, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// keep the partial file so that the next download resumes from here
+		return "", fmt.Errorf("download %s failed: %w", requestUrl, err)
+	}
+
+	digest, err := Digest(partialPath)
+	if err != nil {
+		return "", err
+	}
+	for _, expected := range []string{expectedDigest, serverDigest(resp)} {
+		if expected != "" && expected != digest {
+			_ = os.Remove(partialPath)
+			_ = os.Remove(partialPath + ".json")
+			return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", requestUrl, expected, digest)
+		}
+	}
+
+	fileName := "download"
+	if parsed, err := url.Parse(requestUrl); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+		fileName = path.Base(parsed.Path)
+	}
+	entry, err := d.cache.Put(CacheEntry{
+		Url:          requestUrl,
+		Digest:       digest,
+		Size:         offset + size,
+		ETag:         meta.ETag,
+		LastModified: meta.LastModified,
+	}, partialPath, fileName)
+	if err != nil {
+		return "", err
+	}
+	_ = os.Remove(partialPath + ".json")
+	return entry.Path, nil
+}
+
+// serverDigest return the sha256 digest announced by server, empty if not announced.
+func serverDigest(resp *http.Response) string {
+	// artifactory and nexus announce the checksum of whole file
+	if checksum := resp.Header.Get("X-Checksum-Sha256"); checksum != "" {
+		return "sha256:" + strings.ToLower(checksum)
+	}
+
+	// rfc3230 digest applies to the whole file only if the response is not partial
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	for _, digest := range strings.Split(resp.Header.Get("Digest"), ",") {
+		algorithm, value, ok := strings.Cut(strings.TrimSpace(digest), "=")
+		if !ok || !strings.EqualFold(algorithm, "sha-256") {
+			continue
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			return "sha256:" + hex.EncodeToString(decoded)
+		}
+	}
+	return ""
+}
+
+func readPartialMeta(partialPath string) partialMeta {
+	meta := partialMeta{}
+	if content, err := os.ReadFile(partialPath + ".json"); err == nil {
+		_ = json.Unmarshal(content, &meta)
+	}
+	return meta
+}
+
+func writePartialMeta(partialPath string, meta partialMeta) error {
+	if err := os.MkdirAll(filepath.Dir(partialPath), 0755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(partialPath+".json", content, 0644)
+}
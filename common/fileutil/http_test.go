@@ -0,0 +1,286 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockFileServer serve content with etag and range support, and count the full downloads.
+func mockFileServer(t *testing.T, content []byte) (*httptest.Server, *atomic.Int32, *[]string) {
+	downloads := &atomic.Int32{}
+	ranges := &[]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+		} else if r.Header.Get("If-None-Match") == "" {
+			downloads.Add(1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "foo-ark-biz.jar", time.Unix(0, 0), bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, downloads, ranges
+}
+
+func mockDownloader(t *testing.T) *httpDownloader {
+	return &httpDownloader{
+		client: http.DefaultClient,
+		cache:  &Cache{Dir: t.TempDir(), MaxSize: DefaultCacheMaxSize},
+	}
+}
+
+func sha256Of(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestGetFileUrlType(t *testing.T) {
+	assert.Equal(t, FileUrlTypeLocal, FileUrl("file:///foo.jar").GetFileUrlType())
+	assert.Equal(t, FileUrlTypeHttp, FileUrl("https://artifacts.example/foo.jar").GetFileUrlType())
+	assert.Equal(t, FileUrlTypeUnknown, FileUrl("foo.jar").GetFileUrlType())
+	assert.True(t, FileUrl("http://artifacts.example/foo.jar").IsRemote())
+	assert.False(t, FileUrl("foo.jar").IsRemote())
+
+	_, err := DefaultFileUtil().Download(context.Background(), "foo.jar")
+	assert.NotNil(t, err)
+}
+
+func TestHttpDownload_Cached(t *testing.T) {
+	content := []byte("biz bundle content")
+	server, downloads, _ := mockFileServer(t, content)
+	AddHttpHeader(strings.TrimPrefix(server.URL, "http://"), "Authorization", "Bearer token")
+	downloader := mockDownloader(t)
+
+	localPath, err := downloader.Download(context.Background(), server.URL+"/repo/foo-ark-biz.jar")
+	assert.Nil(t, err)
+	assert.Equal(t, "foo-ark-biz.jar", filepath.Base(localPath))
+	assert.Equal(t, content, mustReadFile(t, localPath))
+
+	// revalidated by etag, not downloaded again
+	cachedPath, err := downloader.Download(context.Background(), server.URL+"/repo/foo-ark-biz.jar")
+	assert.Nil(t, err)
+	assert.Equal(t, localPath, cachedPath)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// found by checksum without request
+	cachedPath, err = downloader.Download(context.Background(), server.URL+"/another/foo-ark-biz.jar#sha256="+sha256Of(content))
+	assert.Nil(t, err)
+	assert.Equal(t, localPath, cachedPath)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	entries, err := downloader.cache.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "sha256:"+sha256Of(content), entries[0].Digest)
+}
+
+func TestHttpDownload_ChecksumMismatch(t *testing.T) {
+	server, _, _ := mockFileServer(t, []byte("biz bundle content"))
+	AddHttpHeader("", "Authorization", "Bearer token")
+	downloader := mockDownloader(t)
+
+	_, err := downloader.Download(context.Background(), server.URL+"/foo-ark-biz.jar#sha256="+sha256Of([]byte("another")))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	_, err = downloader.Download(context.Background(), server.URL+"/foo-ark-biz.jar#md5=foo")
+	assert.NotNil(t, err)
+}
+
+func TestHttpDownload_Resume(t *testing.T) {
+	content := []byte("biz bundle content")
+	server, downloads, ranges := mockFileServer(t, content)
+	AddHttpHeader("", "Authorization", "Bearer token")
+	downloader := mockDownloader(t)
+
+	url := server.URL + "/foo-ark-biz.jar"
+	partialPath := downloader.cache.PartialPath(url)
+	assert.Nil(t, writePartialMeta(partialPath, partialMeta{ETag: `"v1"`}))
+	assert.Nil(t, os.WriteFile(partialPath, content[:5], 0644))
+
+	localPath, err := downloader.Download(context.Background(), url)
+	assert.Nil(t, err)
+	assert.Equal(t, content, mustReadFile(t, localPath))
+	assert.Equal(t, []string{"bytes=5-"}, *ranges)
+	assert.Equal(t, int32(0), downloads.Load())
+}
+
+func TestHttpDownload_ResumeComplete(t *testing.T) {
+	content := []byte("biz bundle content")
+	server, downloads, ranges := mockFileServer(t, content)
+	AddHttpHeader("", "Authorization", "Bearer token")
+	downloader := mockDownloader(t)
+
+	// the partial file is complete, but failed to be put into cache
+	url := server.URL + "/foo-ark-biz.jar"
+	partialPath := downloader.cache.PartialPath(url)
+	assert.Nil(t, writePartialMeta(partialPath, partialMeta{ETag: `"v1"`}))
+	assert.Nil(t, os.WriteFile(partialPath, content, 0644))
+
+	localPath, err := downloader.Download(context.Background(), url)
+	assert.Nil(t, err)
+	assert.Equal(t, content, mustReadFile(t, localPath))
+	assert.Equal(t, []string{"bytes=18-"}, *ranges)
+	assert.Equal(t, int32(0), downloads.Load())
+}
+
+func TestHttpDownload_ResumeShrunk(t *testing.T) {
+	content := []byte("biz bundle content")
+	server, downloads, ranges := mockFileServer(t, content)
+	AddHttpHeader("", "Authorization", "Bearer token")
+	downloader := mockDownloader(t)
+
+	// the file is changed to be shorter than the partial one, but the server keeps its etag
+	url := server.URL + "/foo-ark-biz.jar"
+	partialPath := downloader.cache.PartialPath(url)
+	assert.Nil(t, writePartialMeta(partialPath, partialMeta{ETag: `"v1"`}))
+	assert.Nil(t, os.WriteFile(partialPath, append(content, "of old version"...), 0644))
+
+	localPath, err := downloader.Download(context.Background(), url)
+	assert.Nil(t, err)
+	assert.Equal(t, content, mustReadFile(t, localPath))
+	assert.Equal(t, []string{"bytes=32-"}, *ranges)
+	assert.Equal(t, int32(1), downloads.Load())
+}
+
+func TestHttpDownload_RangeIgnored(t *testing.T) {
+	content := []byte("biz bundle content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the whole file is responded regardless of the range
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	downloader := mockDownloader(t)
+
+	url := server.URL + "/foo-ark-biz.jar"
+	partialPath := downloader.cache.PartialPath(url)
+	assert.Nil(t, writePartialMeta(partialPath, partialMeta{ETag: `"v1"`}))
+	assert.Nil(t, os.WriteFile(partialPath, content[:5], 0644))
+
+	localPath, err := downloader.Download(context.Background(), url)
+	assert.Nil(t, err)
+	assert.Equal(t, content, mustReadFile(t, localPath))
+	entry, ok := downloader.cache.Lookup(url)
+	assert.True(t, ok)
+	assert.Equal(t, int64(len(content)), entry.Size)
+}
+
+func TestCache_Evict(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir(), MaxSize: 10}
+	put := func(url string, content string) *CacheEntry {
+		downloaded := filepath.Join(t.TempDir(), "download")
+		assert.Nil(t, os.WriteFile(downloaded, []byte(content), 0644))
+		entry, err := cache.Put(CacheEntry{
+			Url:    url,
+			Digest: "sha256:" + sha256Of([]byte(content)),
+			Size:   int64(len(content)),
+		}, downloaded, "foo.jar")
+		assert.Nil(t, err)
+		return entry
+	}
+
+	first := put("http://foo/1.jar", "123456")
+	put("http://foo/2.jar", "abcdef")
+
+	_, ok := cache.Lookup("http://foo/1.jar")
+	assert.False(t, ok)
+	_, err := os.Stat(first.Path)
+	assert.True(t, os.IsNotExist(err))
+	_, ok = cache.Lookup("http://foo/2.jar")
+	assert.True(t, ok)
+
+	assert.Nil(t, cache.Clean())
+	entries, err := cache.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestCache_PutReplaced(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir(), MaxSize: DefaultCacheMaxSize}
+	put := func(url string, content string) *CacheEntry {
+		downloaded := filepath.Join(t.TempDir(), "download")
+		assert.Nil(t, os.WriteFile(downloaded, []byte(content), 0644))
+		entry, err := cache.Put(CacheEntry{
+			Url:    url,
+			Digest: "sha256:" + sha256Of([]byte(content)),
+			Size:   int64(len(content)),
+		}, downloaded, "foo.jar")
+		assert.Nil(t, err)
+		return entry
+	}
+
+	// the blob of a SNAPSHOT is removed once its content changes
+	first := put("http://foo/1.0.0-SNAPSHOT.jar", "123456")
+	put("http://foo/1.0.0-SNAPSHOT.jar", "abcdef")
+	_, err := os.Stat(first.Path)
+	assert.True(t, os.IsNotExist(err))
+
+	// unless another url still refers to it
+	shared := put("http://foo/1.0.0.jar", "abcdef")
+	put("http://foo/1.0.0-SNAPSHOT.jar", "ghijkl")
+	_, err = os.Stat(shared.Path)
+	assert.Nil(t, err)
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.lock")
+	unlock, err := lockFile(path)
+	assert.Nil(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlockAgain, err := lockFile(path)
+		assert.Nil(t, err)
+		close(locked)
+		unlockAgain()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the lock is acquired twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lock is not released")
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	return content
+}
@@ -0,0 +1,401 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/osutil"
+)
+
+const (
+	// MavenProtocol is the protocol of maven coordinate file urls, e.g. mvn://com.foo:foo-biz:1.0.0:ark-biz
+	MavenProtocol = "mvn://"
+
+	mavenCentralId  = "central"
+	mavenCentralUrl = "https://repo.maven.apache.org/maven2"
+	mavenMetadata   = "maven-metadata.xml"
+	snapshotSuffix  = "-SNAPSHOT"
+)
+
+// MavenCoordinate is the coordinate of a jar in maven repository.
+type MavenCoordinate struct {
+	GroupId    string
+	ArtifactId string
+	Version    string
+	Classifier string
+}
+
+// ParseMavenCoordinate parse the coordinate in the format of group:artifact:version[:classifier],
+// with or without the mvn:// prefix.
+func ParseMavenCoordinate(coordinate string) (MavenCoordinate, error) {
+	parts := strings.Split(strings.TrimPrefix(coordinate, MavenProtocol), ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return MavenCoordinate{}, fmt.Errorf("invalid maven coordinate %s, should be in the format of group:artifact:version[:classifier]", coordinate)
+	}
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, "/\\") {
+			return MavenCoordinate{}, fmt.Errorf("invalid maven coordinate %s, should be in the format of group:artifact:version[:classifier]", coordinate)
+		}
+	}
+
+	parsed := MavenCoordinate{
+		GroupId:    parts[0],
+		ArtifactId: parts[1],
+		Version:    parts[2],
+	}
+	if len(parts) == 4 {
+		parsed.Classifier = parts[3]
+	}
+	return parsed, nil
+}
+
+func (c MavenCoordinate) String() string {
+	coordinate := c.GroupId + ":" + c.ArtifactId + ":" + c.Version
+	if c.Classifier != "" {
+		coordinate += ":" + c.Classifier
+	}
+	return coordinate
+}
+
+// IsSnapshot return true if the version is a snapshot, whose content changes over time.
+func (c MavenCoordinate) IsSnapshot() bool {
+	return strings.HasSuffix(c.Version, snapshotSuffix)
+}
+
+// dir return the slash separated dir of artifact relative to repository root.
+func (c MavenCoordinate) dir() string {
+	return strings.ReplaceAll(c.GroupId, ".", "/") + "/" + c.ArtifactId + "/" + c.Version
+}
+
+// fileName return the jar file name of given version, which is the timestamped version for remote snapshots.
+func (c MavenCoordinate) fileName(version string) string {
+	name := c.ArtifactId + "-" + version
+	if c.Classifier != "" {
+		name += "-" + c.Classifier
+	}
+	return name + ".jar"
+}
+
+// mavenSettings is the part of maven settings.xml used to resolve artifacts.
+// see https://maven.apache.org/settings.html
+type mavenSettings struct {
+	LocalRepository string         `xml:"localRepository"`
+	Servers         []mavenServer  `xml:"servers>server"`
+	Mirrors         []mavenMirror  `xml:"mirrors>mirror"`
+	Profiles        []mavenProfile `xml:"profiles>profile"`
+	ActiveProfiles  []string       `xml:"activeProfiles>activeProfile"`
+}
+
+type mavenServer struct {
+	Id       string `xml:"id"`
+	Username string `xml:"username"`
+	Password string `xml:"password"`
+}
+
+type mavenMirror struct {
+	Id       string `xml:"id"`
+	Url      string `xml:"url"`
+	MirrorOf string `xml:"mirrorOf"`
+}
+
+type mavenProfile struct {
+	Id         string `xml:"id"`
+	Activation struct {
+		ActiveByDefault bool `xml:"activeByDefault"`
+	} `xml:"activation"`
+	Repositories []mavenRepository `xml:"repositories>repository"`
+}
+
+type mavenRepository struct {
+	Id  string `xml:"id"`
+	Url string `xml:"url"`
+
+	username string
+	password string
+}
+
+// header return the basic auth header of repository, nil if no credential is configured.
+func (r mavenRepository) header() http.Header {
+	if r.username == "" && r.password == "" {
+		return nil
+	}
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(r.username, r.password)
+	return req.Header
+}
+
+func (r mavenRepository) isLocal() bool {
+	return strings.HasPrefix(r.Url, osutil.GetLocalFileProtocol())
+}
+
+// mavenProperty matches the properties like ${env.HOME} and ${user.home} in settings.xml.
+var mavenProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func interpolateMavenProperty(value string) string {
+	return mavenProperty.ReplaceAllStringFunc(strings.TrimSpace(value), func(property string) string {
+		name := property[2 : len(property)-1]
+		switch {
+		case strings.HasPrefix(name, "env."):
+			return os.Getenv(strings.TrimPrefix(name, "env."))
+		case name == "user.home":
+			return homeDir()
+		default:
+			return property
+		}
+	})
+}
+
+// loadMavenSettings read settings.xml at given path, empty settings is returned if the file does not exist.
+func loadMavenSettings(settingsPath string) (*mavenSettings, error) {
+	settings := &mavenSettings{}
+	content, err := os.ReadFile(settingsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(content, settings); err != nil {
+		return nil, fmt.Errorf("failed to parse maven settings %s: %w", settingsPath, err)
+	}
+	return settings, nil
+}
+
+// localRepository return the dir of local repository, ~/.m2/repository by default.
+func (s *mavenSettings) localRepository() string {
+	if s.LocalRepository != "" {
+		return interpolateMavenProperty(s.LocalRepository)
+	}
+	return filepath.Join(homeDir(), ".m2", "repository")
+}
+
+// remoteRepositories return the repositories of active profiles and central,
+// with mirrors and server credentials applied.
+func (s *mavenSettings) remoteRepositories() []mavenRepository {
+	activeProfiles := map[string]bool{}
+	for _, id := range s.ActiveProfiles {
+		activeProfiles[strings.TrimSpace(id)] = true
+	}
+
+	var declared []mavenRepository
+	for _, profile := range s.Profiles {
+		if profile.Activation.ActiveByDefault || activeProfiles[profile.Id] {
+			declared = append(declared, profile.Repositories...)
+		}
+	}
+	declared = append(declared, mavenRepository{Id: mavenCentralId, Url: mavenCentralUrl})
+
+	var repositories []mavenRepository
+	seen := map[string]bool{}
+	for _, repository := range declared {
+		repository.Url = interpolateMavenProperty(repository.Url)
+		for _, mirror := range s.Mirrors {
+			if mirror.matches(repository) {
+				repository = mavenRepository{Id: mirror.Id, Url: interpolateMavenProperty(mirror.Url)}
+				break
+			}
+		}
+		if seen[repository.Id] {
+			continue
+		}
+		seen[repository.Id] = true
+
+		for _, server := range s.Servers {
+			if server.Id == repository.Id {
+				repository.username = interpolateMavenProperty(server.Username)
+				repository.password = interpolateMavenProperty(server.Password)
+			}
+		}
+		repositories = append(repositories, repository)
+	}
+	return repositories
+}
+
+// matches return true if the mirror is used for given repository.
+// see https://maven.apache.org/guides/mini/guide-mirror-settings.html#advanced-mirror-specification
+func (m mavenMirror) matches(repository mavenRepository) bool {
+	matched := false
+	for _, pattern := range strings.Split(m.MirrorOf, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "!"+repository.Id:
+			return false
+		case pattern == "*" || pattern == repository.Id:
+			matched = true
+		case pattern == "external:*":
+			matched = matched || !repository.isLocal() && !strings.Contains(repository.Url, "://localhost")
+		}
+	}
+	return matched
+}
+
+// snapshotMetadata is the part of maven-metadata.xml used to resolve the timestamped version of snapshots.
+type snapshotMetadata struct {
+	Timestamp        string `xml:"versioning>snapshot>timestamp"`
+	BuildNumber      string `xml:"versioning>snapshot>buildNumber"`
+	SnapshotVersions []struct {
+		Classifier string `xml:"classifier"`
+		Extension  string `xml:"extension"`
+		Value      string `xml:"value"`
+	} `xml:"versioning>snapshotVersions>snapshotVersion"`
+}
+
+type mavenResolver struct {
+	// settingsPath is the path of maven settings.xml, ~/.m2/settings.xml by default.
+	settingsPath string
+
+	http *httpDownloader
+}
+
+// Resolve return the local path of jar with given coordinate.
+// The jar is looked up in local repository first, then downloaded from the remote repositories in settings.xml.
+func (r *mavenResolver) Resolve(ctx context.Context, coordinate MavenCoordinate) (string, error) {
+	settings, err := r.settings()
+	if err != nil {
+		return "", err
+	}
+
+	localPath := filepath.Join(settings.localRepository(), filepath.FromSlash(coordinate.dir()), coordinate.fileName(coordinate.Version))
+	if _, err := os.Stat(localPath); err == nil {
+		return localPath, nil
+	}
+
+	failures := []string{"local repository: not found"}
+	for _, repository := range settings.remoteRepositories() {
+		resolved, err := r.resolveFrom(ctx, repository, coordinate)
+		if err == nil {
+			return resolved, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s (%s): %s", repository.Id, repository.Url, err))
+	}
+	return "", fmt.Errorf("failed to resolve %s:\n  %s", coordinate, strings.Join(failures, "\n  "))
+}
+
+// Locations return where the jar with given coordinate is resolved from without fetching anything,
+// which is the path in local repository if present, or else the urls in remote repositories tried in order.
+// The urls of snapshots are in the plain version, as the timestamped one is known after reading the metadata.
+func (r *mavenResolver) Locations(coordinate MavenCoordinate) ([]string, error) {
+	settings, err := r.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	localPath := filepath.Join(settings.localRepository(), filepath.FromSlash(coordinate.dir()), coordinate.fileName(coordinate.Version))
+	if _, err := os.Stat(localPath); err == nil {
+		return []string{localPath}, nil
+	}
+
+	var locations []string
+	for _, repository := range settings.remoteRepositories() {
+		locations = append(locations, strings.TrimSuffix(repository.Url, "/")+"/"+coordinate.dir()+"/"+coordinate.fileName(coordinate.Version))
+	}
+	return locations, nil
+}
+
+// settings load the maven settings.xml, which is ~/.m2/settings.xml if not set.
+func (r *mavenResolver) settings() (*mavenSettings, error) {
+	settingsPath := r.settingsPath
+	if settingsPath == "" {
+		settingsPath = filepath.Join(homeDir(), ".m2", "settings.xml")
+	}
+	return loadMavenSettings(settingsPath)
+}
+
+func (r *mavenResolver) resolveFrom(ctx context.Context, repository mavenRepository, coordinate MavenCoordinate) (string, error) {
+	version := coordinate.Version
+	if coordinate.IsSnapshot() {
+		// snapshots without metadata are not deployed with unique versions, fallback to the plain version
+		if timestamped, err := r.snapshotVersion(ctx, repository, coordinate); err == nil {
+			version = timestamped
+		}
+	}
+
+	artifactUrl := strings.TrimSuffix(repository.Url, "/") + "/" + coordinate.dir() + "/" + coordinate.fileName(version)
+	if repository.isLocal() {
+		localPath := filepath.FromSlash(strings.TrimPrefix(artifactUrl, osutil.GetLocalFileProtocol()))
+		if _, err := os.Stat(localPath); err != nil {
+			return "", errors.New("not found")
+		}
+		return localPath, nil
+	}
+
+	// released and timestamped versions never change, so there is no need to revalidate them
+	if !strings.HasSuffix(version, snapshotSuffix) {
+		if entry, ok := r.http.cache.Lookup(artifactUrl); ok {
+			r.http.cache.Touch(artifactUrl)
+			return entry.Path, nil
+		}
+	}
+	return r.http.download(ctx, artifactUrl, repository.header())
+}
+
+// snapshotVersion return the timestamped version of latest snapshot in repository, e.g. 1.0.0-20240101.101010-1.
+func (r *mavenResolver) snapshotVersion(ctx context.Context, repository mavenRepository, coordinate MavenCoordinate) (string, error) {
+	content, err := r.readRepositoryFile(ctx, repository, coordinate.dir()+"/"+mavenMetadata)
+	if err != nil {
+		return "", err
+	}
+	metadata := &snapshotMetadata{}
+	if err := xml.Unmarshal(content, metadata); err != nil {
+		return "", err
+	}
+
+	for _, snapshotVersion := range metadata.SnapshotVersions {
+		if snapshotVersion.Classifier == coordinate.Classifier && snapshotVersion.Extension == "jar" {
+			return snapshotVersion.Value, nil
+		}
+	}
+	if metadata.Timestamp != "" && metadata.BuildNumber != "" {
+		return strings.TrimSuffix(coordinate.Version, snapshotSuffix) + "-" + metadata.Timestamp + "-" + metadata.BuildNumber, nil
+	}
+	return "", fmt.Errorf("no snapshot version found in %s", mavenMetadata)
+}
+
+// readRepositoryFile read the small file like maven-metadata.xml in repository without caching it.
+func (r *mavenResolver) readRepositoryFile(ctx context.Context, repository mavenRepository, name string) ([]byte, error) {
+	fileUrl := strings.TrimSuffix(repository.Url, "/") + "/" + name
+	if repository.isLocal() {
+		return os.ReadFile(filepath.FromSlash(strings.TrimPrefix(fileUrl, osutil.GetLocalFileProtocol())))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHttpHeaders(req)
+	for name, values := range repository.header() {
+		req.Header[name] = values
+	}
+
+	resp, err := r.http.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s failed with status %s", fileUrl, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,195 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMavenSettings(t *testing.T, content string) string {
+	settingsPath := filepath.Join(t.TempDir(), "settings.xml")
+	assert.Nil(t, os.WriteFile(settingsPath, []byte(content), 0644))
+	return settingsPath
+}
+
+func mockRepositoryFile(t *testing.T, repository, name, content string) {
+	filePath := filepath.Join(repository, filepath.FromSlash(name))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+}
+
+func TestParseMavenCoordinate(t *testing.T) {
+	coordinate, err := ParseMavenCoordinate("mvn://com.foo:foo-biz:1.0.0:ark-biz")
+	assert.Nil(t, err)
+	assert.Equal(t, MavenCoordinate{GroupId: "com.foo", ArtifactId: "foo-biz", Version: "1.0.0", Classifier: "ark-biz"}, coordinate)
+	assert.Equal(t, "com.foo:foo-biz:1.0.0:ark-biz", coordinate.String())
+	assert.Equal(t, "com/foo/foo-biz/1.0.0", coordinate.dir())
+	assert.Equal(t, "foo-biz-1.0.0-ark-biz.jar", coordinate.fileName(coordinate.Version))
+	assert.False(t, coordinate.IsSnapshot())
+	assert.Equal(t, FileUrlTypeMaven, FileUrl("mvn://com.foo:foo-biz:1.0.0").GetFileUrlType())
+	assert.True(t, FileUrl("mvn://com.foo:foo-biz:1.0.0").IsRemote())
+
+	for _, invalid := range []string{"mvn://com.foo:foo-biz", "mvn://com.foo::1.0.0", "mvn://com.foo:foo-biz:1.0.0:ark-biz:jar", "mvn://com.foo:../foo:1.0.0"} {
+		_, err := ParseMavenCoordinate(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestMavenResolve_LocalRepository(t *testing.T) {
+	localRepository := t.TempDir()
+	mockRepositoryFile(t, localRepository, "com/foo/foo-biz/1.0.0/foo-biz-1.0.0-ark-biz.jar", "local")
+	resolver := &mavenResolver{
+		settingsPath: mockMavenSettings(t, `<settings><localRepository>`+localRepository+`</localRepository></settings>`),
+		http:         mockDownloader(t),
+	}
+
+	localPath, err := resolver.Resolve(context.Background(), MavenCoordinate{GroupId: "com.foo", ArtifactId: "foo-biz", Version: "1.0.0", Classifier: "ark-biz"})
+	assert.Nil(t, err)
+	assert.Equal(t, "local", string(mustReadFile(t, localPath)))
+}
+
+func TestMavenResolve_FileRepositorySnapshot(t *testing.T) {
+	remoteRepository := t.TempDir()
+	mockRepositoryFile(t, remoteRepository, "com/foo/foo-biz/1.0.0-SNAPSHOT/maven-metadata.xml", `<metadata>
+  <versioning>
+    <snapshot><timestamp>20240101.101010</timestamp><buildNumber>2</buildNumber></snapshot>
+    <snapshotVersions>
+      <snapshotVersion><extension>jar</extension><value>1.0.0-20240101.101010-2</value></snapshotVersion>
+      <snapshotVersion><classifier>ark-biz</classifier><extension>jar</extension><value>1.0.0-20240101.101010-2</value></snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`)
+	mockRepositoryFile(t, remoteRepository, "com/foo/foo-biz/1.0.0-SNAPSHOT/foo-biz-1.0.0-20240101.101010-1-ark-biz.jar", "outdated")
+	mockRepositoryFile(t, remoteRepository, "com/foo/foo-biz/1.0.0-SNAPSHOT/foo-biz-1.0.0-20240101.101010-2-ark-biz.jar", "latest")
+
+	resolver := &mavenResolver{
+		settingsPath: mockMavenSettings(t, `<settings>
+  <localRepository>`+t.TempDir()+`</localRepository>
+  <profiles>
+    <profile>
+      <id>company</id>
+      <repositories>
+        <repository><id>company</id><url>`+osutil.GetLocalFileProtocol()+filepath.ToSlash(remoteRepository)+`</url></repository>
+      </repositories>
+    </profile>
+  </profiles>
+  <activeProfiles><activeProfile>company</activeProfile></activeProfiles>
+</settings>`),
+		http: mockDownloader(t),
+	}
+
+	localPath, err := resolver.Resolve(context.Background(), MavenCoordinate{GroupId: "com.foo", ArtifactId: "foo-biz", Version: "1.0.0-SNAPSHOT", Classifier: "ark-biz"})
+	assert.Nil(t, err)
+	assert.Equal(t, "latest", string(mustReadFile(t, localPath)))
+}
+
+func TestMavenResolve_HttpMirror(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "deployer" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		requests++
+		if r.URL.Path != "/maven/com/foo/foo-biz/1.0.0/foo-biz-1.0.0.jar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("remote"))
+	}))
+	defer server.Close()
+	t.Setenv("MAVEN_PASSWORD", "secret")
+
+	resolver := &mavenResolver{
+		settingsPath: mockMavenSettings(t, `<settings>
+  <localRepository>`+t.TempDir()+`</localRepository>
+  <mirrors>
+    <mirror><id>company</id><mirrorOf>*</mirrorOf><url>`+server.URL+`/maven/</url></mirror>
+  </mirrors>
+  <servers>
+    <server><id>company</id><username>deployer</username><password>${env.MAVEN_PASSWORD}</password></server>
+  </servers>
+</settings>`),
+		http: mockDownloader(t),
+	}
+
+	coordinate := MavenCoordinate{GroupId: "com.foo", ArtifactId: "foo-biz", Version: "1.0.0"}
+	localPath, err := resolver.Resolve(context.Background(), coordinate)
+	assert.Nil(t, err)
+	assert.Equal(t, "remote", string(mustReadFile(t, localPath)))
+
+	// released versions are served from cache without revalidation
+	_, err = resolver.Resolve(context.Background(), coordinate)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+
+	_, err = resolver.Resolve(context.Background(), MavenCoordinate{GroupId: "com.foo", ArtifactId: "bar-biz", Version: "1.0.0"})
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "company ("+server.URL+"/maven/)"))
+}
+
+func TestMavenLocations(t *testing.T) {
+	localRepository := t.TempDir()
+	mockRepositoryFile(t, localRepository, "com/foo/foo-biz/1.0.0/foo-biz-1.0.0-ark-biz.jar", "local")
+	resolver := &mavenResolver{
+		settingsPath: mockMavenSettings(t, `<settings>
+  <localRepository>`+localRepository+`</localRepository>
+  <mirrors>
+    <mirror><id>company</id><mirrorOf>*</mirrorOf><url>http://maven.example/maven/</url></mirror>
+  </mirrors>
+</settings>`),
+		http: mockDownloader(t),
+	}
+
+	locations, err := resolver.Locations(MavenCoordinate{GroupId: "com.foo", ArtifactId: "foo-biz", Version: "1.0.0", Classifier: "ark-biz"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(localRepository, "com", "foo", "foo-biz", "1.0.0", "foo-biz-1.0.0-ark-biz.jar")}, locations)
+
+	locations, err = resolver.Locations(MavenCoordinate{GroupId: "com.foo", ArtifactId: "bar-biz", Version: "1.0.0-SNAPSHOT"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"http://maven.example/maven/com/foo/bar-biz/1.0.0-SNAPSHOT/bar-biz-1.0.0-SNAPSHOT.jar"}, locations)
+}
+
+func TestVerifyDigest(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "foo-ark-biz.jar")
+	assert.Nil(t, os.WriteFile(localPath, []byte("biz"), 0644))
+
+	assert.Nil(t, verifyDigest(localPath, ""))
+	assert.Nil(t, verifyDigest(localPath, "sha256:"+sha256Of([]byte("biz"))))
+	assert.EqualError(t, verifyDigest(localPath, "sha256:0000"), "checksum mismatch: expected sha256:0000, got sha256:"+sha256Of([]byte("biz")))
+	// the failure of reading file is not a mismatch
+	assert.ErrorIs(t, verifyDigest(localPath+".missing", "sha256:0000"), os.ErrNotExist)
+}
+
+func TestMavenMirrorMatches(t *testing.T) {
+	central := mavenRepository{Id: "central", Url: mavenCentralUrl}
+	local := mavenRepository{Id: "local", Url: "file:///repo"}
+
+	assert.True(t, mavenMirror{MirrorOf: "*"}.matches(local))
+	assert.True(t, mavenMirror{MirrorOf: "external:*"}.matches(central))
+	assert.False(t, mavenMirror{MirrorOf: "external:*"}.matches(local))
+	assert.True(t, mavenMirror{MirrorOf: "central, company"}.matches(central))
+	assert.False(t, mavenMirror{MirrorOf: "*,!central"}.matches(central))
+}
@@ -0,0 +1,66 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ProgressFunc is called with the bytes transferred so far and the total bytes.
+type ProgressFunc func(done, total int64)
+
+type progressKey struct{}
+
+// progressInterval is the min interval between two calls of ProgressFunc, except the last one.
+const progressInterval = 200 * time.Millisecond
+
+// WithProgress return a context reporting the progress of uploads to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressOf return the ProgressFunc of ctx, or a func doing nothing if there is none.
+func progressOf(ctx context.Context) ProgressFunc {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		return fn
+	}
+	return func(int64, int64) {}
+}
+
+// progressReader report the bytes read from reader, at most once per progressInterval until all bytes are read.
+type progressReader struct {
+	reader   io.Reader
+	done     int64
+	total    int64
+	progress ProgressFunc
+	last     time.Time
+}
+
+func newProgressReader(reader io.Reader, total int64, progress ProgressFunc) *progressReader {
+	progress(0, total)
+	return &progressReader{reader: reader, total: total, progress: progress, last: time.Now()}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.done += int64(n)
+	if n > 0 && (r.done >= r.total || time.Since(r.last) >= progressInterval) {
+		r.last = time.Now()
+		r.progress(r.done, r.total)
+	}
+	return n, err
+}
@@ -0,0 +1,282 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// rangeTailSize is the size of file tail read when the file is opened,
+	// which is enough to hold the zip central directory of most biz bundles.
+	rangeTailSize = 256 << 10
+
+	// rangeBlockSize is the size of blocks read from the rest of file.
+	rangeBlockSize = 64 << 10
+)
+
+// RandomAccessFile is a file which could be read at any offset without reading the whole file.
+type RandomAccessFile interface {
+	io.ReaderAt
+	io.Closer
+
+	// Size return the size of file in bytes.
+	Size() int64
+}
+
+// localFile is a RandomAccessFile on local file system.
+type localFile struct {
+	*os.File
+	size int64
+}
+
+func (f *localFile) Size() int64 {
+	return f.size
+}
+
+func openLocalFile(localPath string) (RandomAccessFile, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &localFile{File: file, size: info.Size()}, nil
+}
+
+// rangeFile is a RandomAccessFile read with http range requests, the read blocks are kept in memory.
+type rangeFile struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	header http.Header
+	size   int64
+
+	// etag is sent with If-Match, so that the file changed between requests is not read as a mix.
+	etag string
+
+	// tail is the content of file since tailOffset, read when the file is opened.
+	tail       []byte
+	tailOffset int64
+
+	lock   sync.Mutex
+	blocks map[int64][]byte
+}
+
+// open open the file at rawUrl for random access. The file is read with range requests,
+// unless it's already cached or the server ignores range requests, in which case the whole file is downloaded.
+func (d *httpDownloader) open(ctx context.Context, rawUrl string, header http.Header) (RandomAccessFile, error) {
+	requestUrl, expectedDigest, err := SplitChecksum(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	_, cached := d.cache.Lookup(requestUrl)
+	if expectedDigest != "" {
+		_, cached = d.cache.LookupDigest(expectedDigest)
+	}
+	if cached {
+		return d.openDownloaded(ctx, rawUrl, header)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHttpHeaders(req)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=-%d", rangeTailSize))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the server ignores range requests
+		return d.openDownloaded(ctx, rawUrl, header)
+	default:
+		return nil, fmt.Errorf("download %s failed with status %s", requestUrl, resp.Status)
+	}
+
+	start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+	if !ok {
+		return d.openDownloaded(ctx, rawUrl, header)
+	}
+	tail, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download %s failed: %w", requestUrl, err)
+	}
+	return &rangeFile{
+		ctx:        ctx,
+		client:     d.client,
+		url:        requestUrl,
+		header:     req.Header,
+		size:       size,
+		etag:       resp.Header.Get("ETag"),
+		tail:       tail,
+		tailOffset: start,
+		blocks:     map[int64][]byte{},
+	}, nil
+}
+
+func (d *httpDownloader) openDownloaded(ctx context.Context, rawUrl string, header http.Header) (RandomAccessFile, error) {
+	localPath, err := d.download(ctx, rawUrl, header)
+	if err != nil {
+		return nil, err
+	}
+	return openLocalFile(localPath)
+}
+
+// parseContentRange parse the start and total size in content range like bytes 100-199/200.
+func parseContentRange(contentRange string) (int64, int64, bool) {
+	unit, value, found := strings.Cut(contentRange, " ")
+	if !found || unit != "bytes" {
+		return 0, 0, false
+	}
+	byteRange, total, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(byteRange, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+func (f *rangeFile) Size() int64 {
+	return f.size
+}
+
+func (f *rangeFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.blocks = map[int64][]byte{}
+	return nil
+}
+
+func (f *rangeFile) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+
+	n := 0
+	for n < len(p) && offset+int64(n) < f.size {
+		position := offset + int64(n)
+		if position >= f.tailOffset {
+			n += copy(p[n:], f.tail[position-f.tailOffset:])
+			continue
+		}
+
+		// read all blocks needed at once, but not those in tail
+		last := offset + int64(len(p)) - 1
+		if last >= f.tailOffset {
+			last = f.tailOffset - 1
+		}
+		block, err := f.block(position/rangeBlockSize, last/rangeBlockSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], block[position%rangeBlockSize:])
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// block return the content of block at index, the blocks till last are read together if it's not read yet.
+func (f *rangeFile) block(index, last int64) ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if block, ok := f.blocks[index]; ok {
+		return block, nil
+	}
+	for last > index {
+		if _, ok := f.blocks[last]; !ok {
+			break
+		}
+		last--
+	}
+
+	start := index * rangeBlockSize
+	end := (last+1)*rangeBlockSize - 1
+	if end >= f.tailOffset {
+		end = f.tailOffset - 1
+	}
+
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = f.header.Clone()
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if f.etag != "" {
+		req.Header.Set("If-Match", f.etag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, fmt.Errorf("read %s failed: the file is changed while reading", f.url)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("read %s failed with status %s", f.url, resp.Status)
+	}
+	if contentStart, _, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || contentStart != start {
+		return nil, fmt.Errorf("read %s failed: unexpected content range %s", f.url, resp.Header.Get("Content-Range"))
+	}
+
+	content := make([]byte, end-start+1)
+	if _, err := io.ReadFull(resp.Body, content); err != nil {
+		return nil, fmt.Errorf("read %s failed: %w", f.url, err)
+	}
+	for i := index; i <= last; i++ {
+		blockEnd := (i - index + 1) * rangeBlockSize
+		if blockEnd > int64(len(content)) {
+			blockEnd = int64(len(content))
+		}
+		f.blocks[i] = content[(i-index)*rangeBlockSize : blockEnd]
+	}
+	return f.blocks[index], nil
+}
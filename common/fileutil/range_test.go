@@ -0,0 +1,125 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockBigJar return a jar with the manifest after a big incompressible entry.
+func mockBigJar(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	lib, err := writer.CreateHeader(&zip.FileHeader{Name: "lib/big.jar", Method: zip.Store})
+	assert.Nil(t, err)
+	random := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(random)
+	_, err = lib.Write(random)
+	assert.Nil(t, err)
+	manifest, err := writer.Create("META-INF/MANIFEST.MF")
+	assert.Nil(t, err)
+	_, err = manifest.Write([]byte("Ark-Biz-Name: biz\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return buf.Bytes()
+}
+
+// countingWriter count the bytes written to response.
+type countingWriter struct {
+	http.ResponseWriter
+	written *atomic.Int64
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	w.written.Add(int64(len(p)))
+	return w.ResponseWriter.Write(p)
+}
+
+func readManifest(t *testing.T, file RandomAccessFile) string {
+	zipReader, err := zip.NewReader(file, file.Size())
+	assert.Nil(t, err)
+	manifest, err := zipReader.Open("META-INF/MANIFEST.MF")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(manifest)
+	assert.Nil(t, err)
+	return string(content)
+}
+
+func TestHttpOpen_Range(t *testing.T) {
+	content := mockBigJar(t)
+	written := &atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(countingWriter{ResponseWriter: w, written: written}, r, "biz.jar", time.Unix(0, 0), bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	file, err := mockDownloader(t).open(context.Background(), server.URL+"/biz.jar", nil)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	assert.Equal(t, int64(len(content)), file.Size())
+	assert.Equal(t, "Ark-Biz-Name: biz\n", readManifest(t, file))
+	assert.Less(t, written.Load(), int64(1<<20))
+
+	// the blocks before tail are read on demand
+	head := make([]byte, 4)
+	_, err = file.ReadAt(head, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, content[:4], head)
+	middle := make([]byte, rangeBlockSize+10)
+	_, err = file.ReadAt(middle, 3*rangeBlockSize-5)
+	assert.Nil(t, err)
+	assert.Equal(t, content[3*rangeBlockSize-5:4*rangeBlockSize+5], middle)
+}
+
+func TestHttpOpen_FallbackToDownload(t *testing.T) {
+	content := mockBigJar(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ignore range requests
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	downloader := mockDownloader(t)
+	file, err := downloader.open(context.Background(), server.URL+"/biz.jar", nil)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	assert.Equal(t, "Ark-Biz-Name: biz\n", readManifest(t, file))
+	_, cached := downloader.cache.Lookup(server.URL + "/biz.jar")
+	assert.True(t, cached)
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, size, ok := parseContentRange("bytes 100-199/200")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(200), size)
+
+	_, _, ok = parseContentRange("bytes 100-199/*")
+	assert.False(t, ok)
+}
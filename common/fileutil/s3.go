@@ -0,0 +1,332 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileutil
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// S3Protocol is the protocol of object storage file urls, e.g. s3://bucket/path/to/foo-ark-biz.jar
+	S3Protocol = "s3://"
+
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3DefaultRegion   = "us-east-1"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateFormat      = "20060102T150405Z"
+
+	// s3MaxPresignExpires is the max expiration of presigned url allowed by sigv4.
+	s3MaxPresignExpires = 7 * 24 * time.Hour
+)
+
+// S3Config is the config of S3 compatible object storage, like aws s3, minio and aliyun oss.
+type S3Config struct {
+	// Endpoint is the url of storage service like http://127.0.0.1:9000, aws s3 endpoint of region if empty.
+	Endpoint string
+
+	// Region is the region used to sign requests, us-east-1 if empty.
+	Region string
+
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// PathStyle address the bucket as {endpoint}/{bucket} instead of {bucket}.{endpoint host}.
+	PathStyle bool
+}
+
+// S3ConfigFromEnv return the S3Config given by the standard aws environment variables.
+// Path style is used if a custom endpoint is given, which is what most self-hosted storages expect.
+func S3ConfigFromEnv() S3Config {
+	config := S3Config{
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		AccessKeyId:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	config.PathStyle = config.Endpoint != ""
+	return config
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// S3Location is the location of an object in storage.
+type S3Location struct {
+	Bucket string
+	Key    string
+}
+
+// ParseS3Url parse the url in the format of s3://bucket/key.
+func ParseS3Url(rawUrl string) (S3Location, error) {
+	bucket, key, found := strings.Cut(strings.TrimPrefix(rawUrl, S3Protocol), "/")
+	if !strings.HasPrefix(rawUrl, S3Protocol) || !found || bucket == "" || key == "" {
+		return S3Location{}, fmt.Errorf("invalid s3 url %s, should be in the format of s3://bucket/key", rawUrl)
+	}
+	return S3Location{Bucket: bucket, Key: key}, nil
+}
+
+func (l S3Location) String() string {
+	return S3Protocol + l.Bucket + "/" + l.Key
+}
+
+type s3Client struct {
+	config S3Config
+	http   *httpDownloader
+
+	// now return the time used to sign requests.
+	now func() time.Time
+}
+
+func (c *s3Client) region() string {
+	if c.config.Region == "" {
+		return s3DefaultRegion
+	}
+	return c.config.Region
+}
+
+// objectUrl return the http url of object, with the key escaped the way sigv4 expects.
+func (c *s3Client) objectUrl(location S3Location) (*url.URL, error) {
+	endpoint := c.config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + c.region() + ".amazonaws.com"
+		if c.region() == s3DefaultRegion {
+			endpoint = "https://s3.amazonaws.com"
+		}
+	}
+	objectUrl, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint %s: %w", endpoint, err)
+	}
+
+	objectPath := "/" + location.Key
+	if c.config.PathStyle {
+		objectPath = "/" + location.Bucket + objectPath
+	} else {
+		objectUrl.Host = location.Bucket + "." + objectUrl.Host
+	}
+	objectUrl.Path += objectPath
+	objectUrl.RawPath = s3Escape(objectUrl.Path, false)
+	return objectUrl, nil
+}
+
+// Upload put the local file to given location.
+func (c *s3Client) Upload(ctx context.Context, localPath string, location S3Location) error {
+	objectUrl, err := c.objectUrl(location)
+	if err != nil {
+		return err
+	}
+	digest, err := Digest(localPath)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	body := newProgressReader(file, info.Size(), progressOf(ctx))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectUrl.String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/java-archive")
+	c.sign(req, strings.TrimPrefix(digest, "sha256:"), c.now())
+
+	resp, err := c.http.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload %s failed with status %s: %s", location, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Download download the object into cache and return its local path.
+func (c *s3Client) Download(ctx context.Context, location S3Location) (string, error) {
+	objectUrl, err := c.objectUrl(location)
+	if err != nil {
+		return "", err
+	}
+	// the headers added by downloader like range are not signed, which is allowed by sigv4
+	req := &http.Request{Method: http.MethodGet, URL: objectUrl, Header: http.Header{}}
+	c.sign(req, s3UnsignedPayload, c.now())
+	return c.http.download(ctx, objectUrl.String(), req.Header)
+}
+
+// Open open the object for random access with range requests.
+func (c *s3Client) Open(ctx context.Context, location S3Location) (RandomAccessFile, error) {
+	objectUrl, err := c.objectUrl(location)
+	if err != nil {
+		return nil, err
+	}
+	req := &http.Request{Method: http.MethodGet, URL: objectUrl, Header: http.Header{}}
+	c.sign(req, s3UnsignedPayload, c.now())
+	return c.http.open(ctx, objectUrl.String(), req.Header)
+}
+
+// Presign return a http url granting read access to the object without credentials until expires.
+func (c *s3Client) Presign(location S3Location, expires time.Duration) (string, error) {
+	if expires <= 0 || expires > s3MaxPresignExpires {
+		return "", fmt.Errorf("invalid expiration %s, should be within %s", expires, s3MaxPresignExpires)
+	}
+	objectUrl, err := c.objectUrl(location)
+	if err != nil {
+		return "", err
+	}
+
+	now := c.now().UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", c.config.AccessKeyId+"/"+c.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3DateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if c.config.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+
+	canonicalQuery := s3CanonicalQuery(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectUrl.EscapedPath(),
+		canonicalQuery,
+		"host:" + objectUrl.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	objectUrl.RawQuery = canonicalQuery + "&X-Amz-Signature=" + c.signature(now, canonicalRequest)
+	return objectUrl.String(), nil
+}
+
+// sign add the sigv4 authorization header to req, all headers present in req are signed.
+// see https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (c *s3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(s3DateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, c.config.AccessKeyId, c.scope(now), signedHeaders, c.signature(now, canonicalRequest)))
+}
+
+func (c *s3Client) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + c.region() + "/" + s3Service + "/aws4_request"
+}
+
+func (c *s3Client) signature(now time.Time, canonicalRequest string) string {
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3DateFormat),
+		c.scope(now),
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + c.config.SecretAccessKey)
+	for _, part := range []string{now.Format("20060102"), c.region(), s3Service, "aws4_request"} {
+		key = hmacSha256(key, part)
+	}
+	return hex.EncodeToString(hmacSha256(key, stringToSign))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalQuery return the query sorted by name, with names and values escaped the way sigv4 expects.
+func s3CanonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent encode all characters except the unreserved ones, and slash unless escapeSlash.
+func s3Escape(value string, escapeSlash bool) string {
+	escaped := &strings.Builder{}
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/' && !escapeSlash:
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,122 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package style
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// Mode tells how arkctl prints.
+type Mode struct {
+	// CI prints stable plain text without colors, and fails instead of prompting.
+	CI bool
+
+	// NoColor prints without colors.
+	NoColor bool
+
+	// Quiet prints nothing but errors and their suggestions.
+	Quiet bool
+}
+
+var (
+	ci bool
+
+	// isTerminal tells whether the file descriptor is a terminal, replaced in tests.
+	isTerminal = term.IsTerminal
+
+	// stderr is where errors are printed, which is not silenced in quiet mode.
+	stderr io.Writer = os.Stderr
+)
+
+// DetectCI return true if arkctl runs in CI, which is told by CI=true or stdout not being a terminal.
+func DetectCI() bool {
+	if inCI, err := strconv.ParseBool(os.Getenv("CI")); err == nil && inCI {
+		return true
+	}
+	return !isTerminal(int(os.Stdout.Fd()))
+}
+
+// SetMode apply the mode to all printers, colors are also disabled by NO_COLOR, see https://no-color.org
+// Quiet mode disables the output of all printers, use PrintError and PrintSuggestion for what is still printed.
+func SetMode(mode Mode) {
+	ci = mode.CI
+	pterm.EnableStyling()
+	pterm.EnableOutput()
+	switch {
+	case mode.CI:
+		pterm.DisableStyling()
+	case mode.NoColor, os.Getenv("NO_COLOR") != "":
+		pterm.DisableColor()
+	}
+	if mode.Quiet {
+		pterm.DisableOutput()
+	}
+}
+
+// CI return true if arkctl runs in CI mode.
+func CI() bool {
+	return ci
+}
+
+// Interactive return true if user could be prompted, which requires a terminal stdin out of CI mode.
+func Interactive() bool {
+	return !ci && isTerminal(int(os.Stdin.Fd()))
+}
+
+// PrintError print err to stderr with the error prefix, even in quiet mode. Nothing is printed if err is nil.
+func PrintError(err error) {
+	if err != nil {
+		PrintErrorf("%s", err)
+	}
+}
+
+// PrintErrorf print the formatted error message to stderr with the error prefix, even in quiet mode.
+func PrintErrorf(format string, a ...interface{}) {
+	_, _ = fmt.Fprint(stderr, pterm.Error.Sprintfln(format, a...))
+}
+
+// printedError is an error printed already, which is not printed again when arkctl exits.
+type printedError struct {
+	error
+}
+
+func (e *printedError) Unwrap() error {
+	return e.error
+}
+
+// Printed mark err as printed already, e.g. by PrintError, return nil if err is nil.
+func Printed(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &printedError{err}
+}
+
+// IsPrinted return true if err or the error it wraps is marked by Printed.
+func IsPrinted(err error) bool {
+	return errors.As(err, new(*printedError))
+}
+
+// PrintSuggestion print the suggestion to fix an error to stderr, even in quiet mode.
+func PrintSuggestion(suggestion string) {
+	_, _ = fmt.Fprint(stderr, InfoPrefix("Suggestion").Sprintfln("%s", suggestion))
+}
@@ -0,0 +1,103 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package style
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreMode restore the state of printers and terminal detection changed by the test.
+func restoreMode(t *testing.T) {
+	output, rawOutput, printColor := pterm.Output, pterm.RawOutput, pterm.PrintColor
+	previousCI, previousTerminal, previousStderr := ci, isTerminal, stderr
+	t.Cleanup(func() {
+		pterm.Output, pterm.RawOutput, pterm.PrintColor = output, rawOutput, printColor
+		ci, isTerminal, stderr = previousCI, previousTerminal, previousStderr
+	})
+}
+
+func TestDetectCI(t *testing.T) {
+	restoreMode(t)
+	terminal := true
+	isTerminal = func(int) bool { return terminal }
+
+	t.Setenv("CI", "true")
+	assert.True(t, DetectCI())
+
+	t.Setenv("CI", "false")
+	assert.False(t, DetectCI())
+
+	// stdout is not a terminal, e.g. piped
+	terminal = false
+	assert.True(t, DetectCI())
+}
+
+func TestSetMode(t *testing.T) {
+	restoreMode(t)
+	isTerminal = func(int) bool { return true }
+	t.Setenv("NO_COLOR", "")
+
+	SetMode(Mode{CI: true})
+	assert.True(t, CI())
+	assert.False(t, Interactive())
+	assert.True(t, pterm.RawOutput)
+	assert.False(t, pterm.PrintColor)
+
+	SetMode(Mode{NoColor: true})
+	assert.False(t, CI())
+	assert.True(t, Interactive())
+	assert.False(t, pterm.RawOutput)
+	assert.False(t, pterm.PrintColor)
+
+	SetMode(Mode{})
+	assert.True(t, pterm.Output)
+	assert.True(t, pterm.PrintColor)
+}
+
+func TestSetMode_Quiet(t *testing.T) {
+	restoreMode(t)
+	buf := &bytes.Buffer{}
+	stderr = buf
+
+	SetMode(Mode{CI: true, Quiet: true})
+	assert.False(t, pterm.Output)
+
+	pterm.Info.WithWriter(buf).Println("deploying")
+	pterm.Success.WithWriter(buf).Println("deployed")
+	assert.Empty(t, buf.String())
+
+	// errors and their suggestions are still printed
+	PrintError(nil)
+	PrintError(errors.New("install biz failed"))
+	PrintSuggestion("check the logs of base")
+	assert.Equal(t, "ERROR: install biz failed\nSuggestion: check the logs of base\n", buf.String())
+
+}
+
+func TestPrinted(t *testing.T) {
+	assert.Nil(t, Printed(nil))
+	assert.False(t, IsPrinted(errors.New("install biz failed")))
+
+	err := Printed(errors.New("install biz failed"))
+	assert.Equal(t, "install biz failed", err.Error())
+	assert.True(t, IsPrinted(err))
+	assert.True(t, IsPrinted(fmt.Errorf("1 of 2 modules failed: %w", err)))
+}
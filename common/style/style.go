@@ -26,6 +26,16 @@ func InfoPrefix(prefix string) *pterm.PrefixPrinter {
 	}
 }
 
+func WarningPrefix(prefix string) *pterm.PrefixPrinter {
+	return &pterm.PrefixPrinter{
+		Prefix: pterm.Prefix{
+			Text:  prefix,
+			Style: pterm.Warning.Prefix.Style,
+		},
+		MessageStyle: pterm.Warning.MessageStyle,
+	}
+}
+
 func ErrorPrefix(prefix string) *pterm.PrefixPrinter {
 	return &pterm.PrefixPrinter{
 		Prefix: pterm.Prefix{
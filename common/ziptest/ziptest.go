@@ -0,0 +1,65 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ziptest assembles zip archives and biz bundles for tests.
+package ziptest
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+)
+
+// Zip return a zip archive of entries keyed by their names, which are written in order of names.
+func Zip(t testing.TB, entries map[string][]byte) []byte {
+	t.Helper()
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, name := range names {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write(entries[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// BizJar write the zip archive of entries as fileName in a temp dir, return its local file url.
+// The manifest of biz is one of the entries, e.g. META-INF/MANIFEST.MF: "Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n".
+func BizJar(t testing.TB, fileName string, entries map[string][]byte) fileutil.FileUrl {
+	t.Helper()
+	bizPath := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(bizPath, Zip(t, entries), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileutil.FileUrl(osutil.GetLocalFileProtocol() + bizPath)
+}
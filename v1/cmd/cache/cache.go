@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var CacheCommand = &cobra.Command{
+	Use:   "cache",
+	Short: "manage the cache of downloaded biz bundles",
+	Long: `
+Remote biz bundles are downloaded into ~/.arkctl/cache, the least recently used ones
+are evicted when the cache size exceeds cache.maxSize in config file (2GiB by default).
+`,
+}
+
+var cacheListCommand = &cobra.Command{
+	Use:     "ls",
+	Short:   "list cached biz bundles",
+	Aliases: []string{"list"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := fileutil.DefaultCache().List()
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		data := pterm.TableData{{"DIGEST", "SIZE", "LAST ACCESS", "URL"}}
+		for _, entry := range entries {
+			total += entry.Size
+			data = append(data, []string{
+				shortDigest(entry.Digest),
+				formatSize(entry.Size),
+				entry.LastAccess.Format(time.DateTime),
+				entry.Url,
+			})
+		}
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+		pterm.Info.Printfln("%d bundles, %s in total, cache dir: %s", len(entries), formatSize(total), fileutil.DefaultCache().Dir)
+		return nil
+	},
+}
+
+var cacheCleanCommand = &cobra.Command{
+	Use:   "clean",
+	Short: "remove all cached biz bundles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := fileutil.DefaultCache().Clean(); err != nil {
+			return err
+		}
+		pterm.Info.Println(pterm.Green("clean cache success!"))
+		return nil
+	},
+}
+
+func shortDigest(digest string) string {
+	_, hex, _ := strings.Cut(digest, ":")
+	if len(hex) > 12 {
+		return hex[:12]
+	}
+	return hex
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	CacheCommand.AddCommand(cacheListCommand)
+	CacheCommand.AddCommand(cacheCleanCommand)
+	root.RootCmd.AddCommand(CacheCommand)
+}
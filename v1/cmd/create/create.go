@@ -3,12 +3,14 @@ package create
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 
+	"github.com/koupleless/arkctl/common/exitcode"
 	"github.com/koupleless/arkctl/v1/cmd/root"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/encoding/simplifiedchinese"
@@ -37,23 +39,24 @@ var createCmd = &cobra.Command{
 
 示例:
   arkctl create -p /path/to/project -a myapp`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		projectPath, err := cmd.Flags().GetString("projectPath")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "获取项目路径失败: %v\n", err)
-			os.Exit(1)
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("获取项目路径失败: %w", err))
 		}
 
 		applicationName, err := cmd.Flags().GetString("applicationName")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "获取应用名称失败: %v\n", err)
-			os.Exit(1)
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("获取应用名称失败: %w", err))
+		}
+		if projectPath == "" || applicationName == "" {
+			return exitcode.Wrap(exitcode.Usage, errors.New("项目路径和应用名称必填: arkctl create -p <项目路径> -a <应用名称>"))
 		}
 
 		if err := runJavaProgram(projectPath, applicationName); err != nil {
-			fmt.Fprintf(os.Stderr, "执行 create 命令失败: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("执行 create 命令失败: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -156,7 +159,4 @@ func init() {
 
 	createCmd.Flags().StringP("projectPath", "p", "", "项目路径 (必填)")
 	createCmd.Flags().StringP("applicationName", "a", "", "应用名称 (必填)")
-
-	createCmd.MarkFlagRequired("projectPath")
-	createCmd.MarkFlagRequired("applicationName")
 }
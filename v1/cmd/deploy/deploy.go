@@ -18,46 +18,128 @@
 package deploy
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/koupleless/arkctl/common/cmdutil"
 	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
 	"github.com/koupleless/arkctl/common/fileutil"
 	"github.com/koupleless/arkctl/common/osutil"
 	"github.com/koupleless/arkctl/common/runtime"
 	"github.com/koupleless/arkctl/common/style"
 	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/config"
 	"github.com/koupleless/arkctl/v1/service/ark"
+	"github.com/koupleless/arkctl/v1/service/build"
+	"github.com/koupleless/arkctl/v1/service/pipeline"
+	"github.com/koupleless/arkctl/v1/service/probe"
+	"github.com/koupleless/arkctl/v1/service/sbom"
 
 	"github.com/google/uuid"
+	"github.com/manifoldco/promptui"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
 	portFlag int
 
-	subBundlePath string
+	subBundlePaths   []string
+	changedSinceFlag string
+	keepGoingFlag    bool
+	nothingChanged   bool // pre detected, no module changed since --changed-since
+
+	headerFlags  []string
+	checksumFlag string
 
 	defaultArg string
 	doBuild    bool
 
+	buildToolFlag    string
+	profileFlags     []string
+	buildArgFlags    []string
+	offlineFlag      bool
+	mavenSettingFlag string
+	skipBuildFlag    bool
+	forceBuildFlag   bool
+	buildTool        build.Tool    // pre detected build tool
+	buildOptions     build.Options // pre merged from flags and project config
+
 	podFlag      string
 	podNamespace string // pre parsed pod namespace
 	podName      string // pre parsed pod name
+
+	viaFlag      string
+	s3PrefixFlag string
+
+	publicKeyFlag        string
+	signatureFlag        string
+	requireSignatureFlag bool
+
+	devVersionFlag      bool
+	keepDevVersionsFlag int
+
+	sbomFlag string
+
+	installTimeoutFlag time.Duration
+
+	probePathFlags         []string
+	probePortFlag          int
+	probeStatusFlags       []int
+	probeTimeoutFlag       time.Duration
+	uninstallOnFailureFlag bool
+	probeConfig            config.ProbeConfig // pre merged from flags and project config
+
+	watchFlag         bool
+	watchDebounceFlag time.Duration
+
+	dryRunFlag bool
+
+	outputFlag string
+
+	onlyStageFlags []string
+	skipStageFlags []string
+	deployPipeline *pipeline.Pipeline // pre built with the custom stages in project config
 )
 
 const (
-	ctxKeyArkBizBundlePathInSidePod = "arkBizBundlePathInSidePod"
-	ctxKeyArkService                = "ark.Service"
-	ctxKeyBizModel                  = "ark.BizModel"
-	ctxKeyArkContainerRuntimeInfo   = "ark.ContainerRuntimeInfo"
+	viaS3 = "s3"
+
+	// presignExpires is how long the base could download the bundle uploaded to object storage.
+	presignExpires = time.Hour
+
+	// probeInterval is how long to wait before probing the biz again.
+	probeInterval = time.Second
+)
+
+var (
+	ctxKeyArkBizBundlePathInSidePod = pipeline.Key[string]("arkBizBundlePathInSidePod")
+	ctxKeyArkBizBundleUrl           = pipeline.Key[fileutil.FileUrl]("arkBizBundleUrl")
+	ctxKeyArkService                = pipeline.Key[ark.Service]("ark.Service")
+	ctxKeyBizModel                  = pipeline.Key[*ark.BizModel]("ark.BizModel")
+	ctxKeyArkContainerRuntimeInfo   = pipeline.Key[*ark.ArkContainerRuntimeInfo]("ark.ContainerRuntimeInfo")
+	ctxKeyBuildStartTime            = pipeline.Key[time.Time]("build.StartTime")
+	ctxKeyBuildOutput               = pipeline.Key[[]string]("build.Output")
+	ctxKeyBuildFingerprint          = pipeline.Key[string]("build.Fingerprint")
+	ctxKeyReusedBundles             = pipeline.Key[[]string]("build.ReusedBundles")
+	ctxKeyBuildModules              = pipeline.Key[[]build.ReactorModule]("build.Modules")
+	ctxKeyBizBundle                 = pipeline.Key[string]("build.BizBundle")
+	ctxKeySbomPath                  = pipeline.Key[string]("sbom.Path")
+	ctxKeySbomDigest                = pipeline.Key[string]("sbom.Digest")
 )
 
 var DeployCommand = &cobra.Command{
@@ -74,7 +156,7 @@ Scenario 0: Build bundle at current workspace and deploy it to a local running a
 Scenario 1: Build bundle at given path and deploy it to local running ark container with given port:
 	arkctl deploy --port ${your ark container portFlag} ${path/to/your/project}
 
-Scenario 2: Deploy a local pre-built bundle to local running ark container:
+Scenario 2: Deploy a local pre-built bundle (jar, zip archive or exploded directory) to local running ark container:
 	arkctl deploy ${path/to/your/pre/built/bundle.jar}
 
 Scenario 3: Build and deploy a bundle at current dir to a remote running ark container in k8s cluster with default port:
@@ -82,17 +164,144 @@ Scenario 3: Build and deploy a bundle at current dir to a remote running ark con
 
 Scenario 4: Build an maven multi module project and deploy a sub module to a running ark container:
 	arkctl deploy --sub ${path/to/your/sub/module}
+
+Scenario 5: Download a remote bundle and deploy it to local running ark container:
+	arkctl deploy --header "Authorization: Bearer ${token}" https://artifacts.example/foo-ark-biz.jar
+
+Scenario 6: Resolve a bundle by maven coordinate from ~/.m2/repository or repositories in settings.xml and deploy it:
+	arkctl deploy mvn://${groupId}:${artifactId}:${version}:ark-biz
+
+Scenario 7: Upload the bundle to S3 compatible object storage, and let the base in k8s cluster install it by presigned url:
+	arkctl deploy --via s3 --s3-prefix s3://${bucket}/${path} --pod ${namespace}/${name}
+
+Scenario 8: Deploy the bundle as a dev version like 1.0.0-dev.20240101120000.abc1234, keeping the last 3 dev versions installed:
+	arkctl deploy --dev-version --keep-dev-versions 3
+
+Scenario 9: Generate the SBOM of bundle and attach its digest to the deploy record in ~/.arkctl/deploys.jsonl:
+	arkctl deploy --sbom cyclonedx-json
+
+Scenario 10: Build a gradle project with the koupleless gradle plugin and deploy it:
+	arkctl deploy --build-tool gradle ${path/to/your/project}
+
+Scenario 11: Build with maven profiles offline, which could also be shared in the build section of .arkctl.yaml:
+	arkctl deploy --profile dev --offline --settings .mvn/settings.xml --maven-arg=-T --maven-arg=4
+
+Scenario 12: Watch the project, and rebuild and redeploy it to local running ark container on every change:
+	arkctl deploy --watch
+
+Scenario 13: Reinstall the bundle built before without checking the class version, or run the build stage only:
+	arkctl deploy --skip build --skip check-class-version
+	arkctl deploy --only build
+
+Scenario 14: Print what deploy will do to a shared base in k8s cluster, without building, uploading or installing anything:
+	arkctl deploy --dry-run --pod ${namespace}/${name}
+
+Scenario 15: Stream the deploy as newline delimited JSON events, so that editors and CI can render their own UI:
+	arkctl deploy --output events
+
+Scenario 16: Build the modules changed since origin/main and those depending on them in one run, and deploy each of them:
+	arkctl deploy --changed-since origin/main --keep-going
+
+Scenario 17: Verify the biz serves traffic after install, and uninstall it if not:
+	arkctl deploy --probe /actuator/health --uninstall-on-failure
 `,
 	Args: func(cmd *cobra.Command, args []string) error {
+		if err := event.SetOutput(outputFlag, os.Stdout); err != nil {
+			return err
+		}
 		if len(args) == 0 {
 			defaultArg = runtime.MustReturnResult(os.Getwd())
 		} else {
 			defaultArg = args[len(args)-1]
-			if !filepath.IsAbs(defaultArg) {
+			if !filepath.IsAbs(defaultArg) && !fileutil.FileUrl(defaultArg).IsRemote() {
 				defaultArg = filepath.Join(runtime.MustReturnResult(os.Getwd()), defaultArg)
 			}
 		}
-		doBuild = !strings.HasSuffix(defaultArg, ".jar")
+		// pre-built bundles are detected by content, so that exploded directories and zip archives are supported
+		doBuild = false
+		if !fileutil.FileUrl(defaultArg).IsRemote() {
+			info, err := os.Stat(defaultArg)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				return fmt.Errorf("file not exist: %s", defaultArg)
+			case err != nil:
+				return err
+			case info.IsDir():
+				doBuild = !ark.IsBizBundle(defaultArg)
+			default:
+				// a file is never a project to build
+				if err := ark.CheckBizBundle(defaultArg); err != nil {
+					return err
+				}
+			}
+		}
+		projectDir := defaultArg
+		if !doBuild {
+			projectDir = runtime.MustReturnResult(os.Getwd())
+		}
+		projectConfig, err := config.LoadProjectConfig(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to load project config: %w", err)
+		}
+		if doBuild {
+			if err := prepareBuild(projectConfig.Build); err != nil {
+				return err
+			}
+		}
+
+		for _, header := range headerFlags {
+			name, value, found := strings.Cut(header, ":")
+			if !found {
+				return fmt.Errorf("invalid header %s, should be in the format of name: value", header)
+			}
+			fileutil.AddHttpHeader("", strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+
+		if s3PrefixFlag == "" {
+			s3PrefixFlag = viper.GetString("s3.prefix")
+		}
+		switch {
+		case viaFlag != "" && viaFlag != viaS3:
+			return fmt.Errorf("unsupported --via %s, only s3 is supported", viaFlag)
+		case viaFlag == viaS3 && fileutil.FileUrl(defaultArg).GetFileUrlType() != fileutil.FileUrlTypeS3:
+			if bucket := strings.TrimPrefix(s3PrefixFlag, fileutil.S3Protocol); bucket == s3PrefixFlag || strings.Trim(bucket, "/") == "" {
+				return fmt.Errorf("--via s3 requires --s3-prefix or s3.prefix in config, in the format of s3://bucket/path")
+			}
+		}
+
+		if publicKeyFlag == "" {
+			publicKeyFlag = viper.GetString("signature.publicKey")
+		}
+		if !cmd.Flags().Changed("require-signature") && viper.IsSet("signature.required") {
+			requireSignatureFlag = viper.GetBool("signature.required")
+		}
+		if requireSignatureFlag && publicKeyFlag == "" {
+			return fmt.Errorf("--require-signature requires --public-key or signature.publicKey in config")
+		}
+
+		if len(subBundlePaths) > 0 && changedSinceFlag != "" {
+			return fmt.Errorf("--sub and --changed-since can not be used together")
+		}
+		if changedSinceFlag != "" && (!doBuild || skipBuildFlag) {
+			return fmt.Errorf("--changed-since requires a project to build, instead of a pre-built bundle or --skip-build")
+		}
+		if watchFlag && changedSinceFlag != "" {
+			return fmt.Errorf("--watch and --changed-since can not be used together")
+		}
+		if watchFlag && (!doBuild || skipBuildFlag) {
+			return fmt.Errorf("--watch requires a project to build, instead of a pre-built bundle or --skip-build")
+		}
+		if watchFlag && dryRunFlag {
+			return fmt.Errorf("--watch and --dry-run can not be used together")
+		}
+
+		prepareProbe(cmd, projectConfig.Probe)
+
+		switch sbom.Format(sbomFlag) {
+		case "", sbom.FormatCycloneDXJson, sbom.FormatSpdxJson:
+		default:
+			return fmt.Errorf("unsupported --sbom %s, should be one of %s and %s", sbomFlag, sbom.FormatCycloneDXJson, sbom.FormatSpdxJson)
+		}
 
 		if podFlag != "" && strings.Contains(podFlag, "/") {
 			podNamespace, podName = strings.Split(podFlag, "/")[0], strings.Split(podFlag, "/")[1]
@@ -100,123 +309,625 @@ Scenario 4: Build an maven multi module project and deploy a sub module to a run
 			podNamespace, podName = "default", podFlag
 		}
 
-		return nil
+		deployPipeline, err = newDeployPipeline(projectConfig.Stages, projectConfig.Dir)
+		return err
 	},
-	Run: executeDeploy,
+	SilenceUsage: true,
+	RunE:         executeDeploy,
+}
+
+// prepareBuild decide the build tool and options by flags and the build section of project config,
+// the flags take precedence, and the list ones are appended to those in project config.
+func prepareBuild(buildConfig config.BuildConfig) (err error) {
+	switch {
+	case buildToolFlag != "":
+		buildTool, err = build.ParseTool(buildToolFlag)
+	case buildConfig.Tool != "":
+		buildTool, err = build.ParseTool(buildConfig.Tool)
+	default:
+		if buildTool, err = build.DetectTool(defaultArg); err != nil {
+			// keep the former behavior of building with maven, which tells what's wrong
+			buildTool, err = build.ToolMaven, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	buildOptions = build.Options{
+		Profiles: append(append([]string{}, buildConfig.Profiles...), profileFlags...),
+		Args:     append(append([]string{}, buildConfig.Args...), buildArgFlags...),
+		Offline:  offlineFlag || buildConfig.Offline,
+		Settings: buildConfig.Settings,
+	}
+	if mavenSettingFlag != "" {
+		buildOptions.Settings = runtime.MustReturnResult(filepath.Abs(mavenSettingFlag))
+	}
+	buildOptions.KeepGoing = keepGoingFlag
+	for _, module := range subBundlePaths {
+		if filepath.IsAbs(module) {
+			if module, err = filepath.Rel(defaultArg, module); err != nil {
+				return err
+			}
+		}
+		buildOptions.Modules = append(buildOptions.Modules, filepath.Clean(module))
+	}
+	if changedSinceFlag == "" {
+		return nil
+	}
+
+	files, err := changedFiles(defaultArg, changedSinceFlag)
+	if err != nil {
+		return fmt.Errorf("failed to list the files changed since %s: %w", changedSinceFlag, err)
+	}
+	modules, err := build.ChangedModules(defaultArg, buildTool, files)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(modules) == 0:
+		nothingChanged = true
+	case slices.Contains(modules, "."):
+		// the root project is changed, which affects all modules
+	default:
+		// the modules depending on the changed ones are built and deployed as well
+		buildOptions.Modules = modules
+		buildOptions.AlsoMakeDependents = true
+	}
+	return nil
+}
+
+// changedFiles return the files changed in dir since the commit where ref branches off HEAD,
+// including the uncommitted and untracked ones, relative to dir.
+func changedFiles(dir, ref string) ([]string, error) {
+	base, err := gitOutput(dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if len(base) == 0 {
+		return nil, fmt.Errorf("no common commit of %s and HEAD", ref)
+	}
+	changed, err := gitOutput(dir, "diff", "--name-only", "--relative", base[0])
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitOutput(dir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, untracked...), nil
+}
+
+// gitOutput run git with args in dir, return the non-empty lines of its output.
+func gitOutput(dir string, args ...string) ([]string, error) {
+	gitcmd := cmdutil.BuildCommandWithWorkDir(context.Background(), dir, "git", args...)
+	if err := gitcmd.Exec(); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for line := range gitcmd.Output() {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	stderr := &strings.Builder{}
+	for err := range gitcmd.Wait() {
+		stderr.WriteString(err.Error())
+	}
+	<-gitcmd.Done()
+	if err := gitcmd.GetExitError(); err != nil {
+		return nil, fmt.Errorf("git %s: %w %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return lines, nil
 }
 
-func execMavenBuild(ctx *contextutil.Context) bool {
-	if !doBuild {
-		return true
+// prepareProbe decide the probe config by flags and the probe section of project config,
+// the flags take precedence, and the probe paths are appended to those in project config.
+func prepareProbe(cmd *cobra.Command, c config.ProbeConfig) {
+	probeConfig = c
+	probeConfig.Paths = append(probeConfig.Paths, probePathFlags...)
+	if cmd.Flags().Changed("probe-port") || probeConfig.Port == 0 {
+		probeConfig.Port = probePortFlag
 	}
+	if cmd.Flags().Changed("probe-status") || len(probeConfig.Status) == 0 {
+		probeConfig.Status = probeStatusFlags
+	}
+	// the timeout of 0 in project config probes until the path responds, which is not replaced by the default
+	if cmd.Flags().Changed("probe-timeout") || probeConfig.Timeout == nil {
+		probeConfig.Timeout = &probeTimeoutFlag
+	}
+	if cmd.Flags().Changed("uninstall-on-failure") {
+		probeConfig.UninstallOnFailure = uninstallOnFailureFlag
+	}
+}
 
-	style.InfoPrefix("Stage").Println("BuildBundle")
+// skipBuild skip building if the bundle is pre-built, or the user chooses to deploy the bundle built before.
+func skipBuild(_ *contextutil.Context) string {
+	switch {
+	case !doBuild:
+		return "pre-built bundle"
+	case skipBuildFlag:
+		return "--skip-build, deploy the bundle built before"
+	}
+	return ""
+}
+
+func execBuild(ctx *contextutil.Context) error {
 	style.InfoPrefix("BuildDirectory").Println(defaultArg)
+	style.InfoPrefix("BuildTool").Println(string(buildTool))
+
+	fingerprint := ""
+	if !forceBuildFlag {
+		var err error
+		if fingerprint, err = build.Fingerprint(defaultArg, buildTool, buildOptions); err != nil {
+			pterm.Warning.Printfln("failed to fingerprint the project, build it anyway: %s", err)
+		} else if bundles, ok := build.ReusableBundles(defaultArg, buildModule(), fingerprint); ok {
+			ctxKeyReusedBundles.Put(ctx, bundles)
+			pterm.Info.Println("sources not changed since last build, reuse the bundle built before, use --force-build to rebuild")
+			pterm.Println()
+			return nil
+		}
+	}
 
-	mvn := cmdutil.BuildCommandWithWorkDir(
-		ctx,
-		defaultArg,
-		"mvn",
-		"clean", "package", "-Dmaven.test.skip=true")
-	style.InfoPrefix("Command").Println(mvn.String())
+	executable, args := buildTool.Command(defaultArg, buildOptions)
+	buildcmd := cmdutil.BuildCommandWithWorkDir(ctx, defaultArg, executable, args...)
+	style.InfoPrefix("Command").Println(buildcmd.String())
 
-	if err := mvn.Exec(); err != nil {
-		pterm.Error.PrintOnErrorf("Build bundle failed: %s!", err)
-		printSuggestion(err)
-		return false
+	var logWriter io.Writer = io.Discard
+	logPath := ""
+	logFile, err := build.CreateBuildLog(defaultArg, time.Now())
+	if err != nil {
+		pterm.Warning.Printfln("failed to keep the build log: %s", err)
+	} else {
+		defer logFile.Close()
+		logWriter, logPath = logFile, logFile.Name()
+		style.InfoPrefix("BuildLog").Println(logPath)
 	}
 
-	mvnOutput := []string{}
+	// the lines of gradle are collected by maven log as well, which tracks nothing of them
+	mavenLog := build.NewMavenLog()
+	printOutput := printStageOutput(stageBuild)
+	if buildTool == build.ToolMaven {
+		progress := newMavenProgress()
+		defer progress.stop()
+		mavenLog.OnModule = progress.update
+		// the whole output is kept in build log, only the progress of modules is printed
+		printOutput = func(line string) {
+			event.Emit(event.Event{Type: event.TypeLog, Stage: stageBuild, Line: line})
+		}
+	}
+
+	// the bundles built before are stale, the file system may keep mtime in seconds only
+	ctxKeyBuildStartTime.Put(ctx, time.Now().Add(-time.Second))
+	if err := buildcmd.Exec(); err != nil {
+		return withSuggestion(fmt.Errorf("build bundle failed: %w", err), nil)
+	}
+
+	outputDone := make(chan struct{})
 	go func() {
-		for line := range mvn.Output() {
-			pterm.Println(line)
-			mvnOutput = append(mvnOutput, line)
+		defer close(outputDone)
+		for line := range buildcmd.Output() {
+			_, _ = fmt.Fprintln(logWriter, line)
+			printOutput(line)
+			mavenLog.Write(line)
 		}
 	}()
 
-	if err := <-mvn.Wait(); err != nil {
-		pterm.Error.PrintOnErrorf("Build bundle failed: %s!", err)
-		printSuggestion(err)
-		return false
+	// the stderr of build is reported as error, but only the exit code tells whether it fails
+	stderr := &strings.Builder{}
+	for err := range buildcmd.Wait() {
+		stderr.WriteString(err.Error())
 	}
-
-	if err := mvn.GetExitError(); err != nil {
-		pterm.Error.PrintOnErrorf("Build bundle failed: %s!", err)
-		printSuggestionWithMore(err, mvnOutput)
-		return false
+	<-outputDone
+	<-buildcmd.Done()
+	_, _ = fmt.Fprint(logWriter, stderr.String())
+
+	// the modules built are deployed with --keep-going even if others fail
+	ctxKeyBuildOutput.Put(ctx, mavenLog.Lines())
+	ctxKeyBuildModules.Put(ctx, mavenLog.Modules())
+	if err := buildcmd.GetExitError(); err != nil {
+		var stderrLines []string
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			stderrLines = strings.Split(output, "\n")
+		}
+		return withSuggestion(buildError(err, mavenLog, stderrLines, logPath), append(mavenLog.Lines(), stderrLines...))
+	}
+	// the bundles are recorded with the fingerprint once located, the failed build is never reused
+	if fingerprint != "" {
+		ctxKeyBuildFingerprint.Put(ctx, fingerprint)
 	}
 
 	pterm.Info.Printfln(pterm.Green("build bundle success!"))
 	pterm.Println()
-	return true
+	return nil
 }
 
-func execParseBizModel(ctx *contextutil.Context) bool {
-	style.InfoPrefix("Stage").Println("ParseBizModel")
+// buildError return the error of failed build, with the failing module and the errors reported by maven,
+// or the stderr of build if maven reports none, like that of gradle, instead of the whole output kept in build log.
+func buildError(err error, mavenLog *build.MavenLog, stderrLines []string, logPath string) error {
+	message := "build bundle failed"
+	if module := mavenLog.FailedModule(); module != "" {
+		message += " in module " + module
+	}
+	err = fmt.Errorf("%s: %w", message, err)
+	if logPath != "" {
+		err = fmt.Errorf("%w, see the whole output at %s", err, logPath)
+	}
+
+	var errorLines []string
+	for _, block := range mavenLog.Errors() {
+		errorLines = append(errorLines, block...)
+	}
+	if len(errorLines) == 0 {
+		errorLines = stderrLines
+	}
+	if len(errorLines) > 0 {
+		err = fmt.Errorf("%w\n%s", err, strings.Join(errorLines, "\n"))
+	}
+	return err
+}
+
+func execParseBizModel(ctx *contextutil.Context) error {
 	bundlePath := osutil.GetLocalFileProtocol() + defaultArg
-	if doBuild {
-		searchdir := defaultArg
-		if subBundlePath != "" {
-			searchdir = filepath.Join(searchdir, subBundlePath)
+	if fileutil.FileUrl(defaultArg).IsRemote() {
+		remoteUrl := defaultArg
+		if checksumFlag != "" {
+			remoteUrl += "#" + strings.Replace(checksumFlag, ":", "=", 1)
+		}
+		// validate the remote bundle by its manifest before downloading the whole bundle
+		if _, err := ark.ParseBizModel(ctx, fileutil.FileUrl(remoteUrl)); err != nil {
+			return fmt.Errorf("failed to parse remote bundle %s: %w", defaultArg, err)
+		}
+		style.InfoPrefix("Download").Println(defaultArg)
+		localUrl, err := fileutil.DefaultFileUtil().Download(ctx, fileutil.FileUrl(remoteUrl))
+		if err != nil {
+			return fmt.Errorf("failed to download bundle %s: %w", defaultArg, err)
+		}
+		bundlePath = localUrl
+	}
+	if bundle, ok := ctxKeyBizBundle.Get(ctx); ok {
+		bundlePath = osutil.GetLocalFileProtocol() + bundle
+	} else if doBuild {
+		bundles, err := locateBuiltBundles(ctx, bundleSearchDirs())
+		if err != nil {
+			return fmt.Errorf("failed to locate built biz bundle: %s", err)
 		}
 
-		filepath.Walk(searchdir, func(path string, info os.FileInfo, err error) error {
-			if !info.IsDir() && strings.HasSuffix(info.Name(), "-ark-biz.jar") {
-				bundlePath = path
-			}
-			return nil
-		})
-
-		if !strings.HasSuffix(bundlePath, "-ark-biz.jar") {
-			pterm.Error.Println("can not find pre built biz bundle in build dir!")
-			return false
+		builtBundle, err := selectBizBundle(bundles)
+		if err != nil {
+			return err
 		}
-		bundlePath = osutil.GetLocalFileProtocol() + bundlePath
+		bundlePath = osutil.GetLocalFileProtocol() + builtBundle
 	}
 
 	bizModel, err := ark.ParseBizModel(ctx, fileutil.FileUrl(bundlePath))
 	if errors.Is(err, os.ErrNotExist) {
-		style.ErrorPrefix("File Not Exist").Println(defaultArg)
-		return false
+		// the error tells the path of bundle built, located or downloaded, which may differ from the given one
+		return fmt.Errorf("biz bundle not found: %w", err)
 	}
 	if err != nil {
-		pterm.Error.PrintOnError(fmt.Errorf("failed to parse bundle: %s", err))
-		return false
+		return fmt.Errorf("failed to parse bundle %s: %w", strings.TrimPrefix(bundlePath, osutil.GetLocalFileProtocol()), err)
 	}
 
-	ctx.Put(ctxKeyBizModel, bizModel)
+	ctxKeyBizModel.Put(ctx, bizModel)
 	style.InfoPrefix("BizBundleInfo").Println(string(runtime.MustReturnResult(json.Marshal(*bizModel))))
+	style.InfoPrefix("BizBundleDigest").Println(bizModel.Digest)
 	pterm.Info.Println(pterm.Green("parse biz bundle success!"))
 	pterm.Println()
 
-	return true
+	return nil
 }
 
-func execUploadBizBundle(ctx *contextutil.Context) bool {
-	bizModel := ctx.Value(ctxKeyBizModel).(*ark.BizModel)
+// bundleSearchDirs return the directories where the bundles to deploy are built in.
+func bundleSearchDirs() []string {
+	if len(buildOptions.Modules) == 0 || buildOptions.AlsoMakeDependents {
+		// the dependents of changed modules may be anywhere in the project
+		return []string{defaultArg}
+	}
+	dirs := make([]string, 0, len(buildOptions.Modules))
+	for _, module := range buildOptions.Modules {
+		dirs = append(dirs, filepath.Join(defaultArg, module))
+	}
+	return dirs
+}
 
-	if podFlag != "" && bizModel.BizUrl.GetFileUrlType() == fileutil.FileUrlTypeLocal {
+// locateBuiltBundles return the bundles reused or built by execBuild in searchdirs,
+// the built ones are recorded with the fingerprint of project so that they could be reused next time.
+func locateBuiltBundles(ctx *contextutil.Context, searchdirs []string) ([]string, error) {
+	if bundles, ok := ctxKeyReusedBundles.Get(ctx); ok {
+		return bundles, nil
+	}
 
-		targetPath := fmt.Sprintf("/tmp/%s",
-			bizModel.BizName+"-"+
-				bizModel.BizVersion+"-"+
-				runtime.MustReturnResult(uuid.NewUUID()).String()+"-"+
-				"ark-biz.jar",
-		)
+	buildOutput, _ := ctxKeyBuildOutput.Get(ctx)
+	buildStartTime, _ := ctxKeyBuildStartTime.Get(ctx)
+	var bundles []string
+	for _, searchdir := range searchdirs {
+		var found []string
+		var err error
+		switch buildTool {
+		case build.ToolGradle:
+			found, err = build.LocateGradleBizBundles(defaultArg, searchdir, buildStartTime)
+		default:
+			found, err = build.LocateBizBundles(defaultArg, searchdir, buildOutput, buildStartTime)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, bundle := range found {
+			if !slices.Contains(bundles, bundle) {
+				bundles = append(bundles, bundle)
+			}
+		}
+	}
+
+	if fingerprint, ok := ctxKeyBuildFingerprint.Get(ctx); ok && len(bundles) > 0 {
+		record := build.BuildRecord{Fingerprint: fingerprint, Bundles: bundles, Time: time.Now()}
+		if err := build.SaveBuildRecord(defaultArg, buildModule(), record); err != nil {
+			pterm.Warning.Printfln("failed to record the build: %s", err)
+		}
+	}
+	return bundles, nil
+}
+
+// buildModule return the modules built, which are the relative paths of sub modules or "." for the whole project.
+func buildModule() string {
+	if len(buildOptions.Modules) > 0 {
+		return strings.Join(buildOptions.Modules, ",")
+	}
+	return "."
+}
+
+// selectBizBundle return the only one of bundles, or the one selected by user if several modules build bundles.
+func selectBizBundle(bundles []string) (string, error) {
+	switch len(bundles) {
+	case 0:
+		return "", errors.New("can not find built biz bundle in the build directory of modules")
+	case 1:
+		return bundles[0], nil
+	}
+
+	items := make([]string, 0, len(bundles))
+	for _, bundle := range bundles {
+		if relative, err := filepath.Rel(defaultArg, bundle); err == nil {
+			bundle = relative
+		}
+		items = append(items, bundle)
+	}
+	p := &promptui.Select{
+		Label: "BizBundle",
+		Items: items,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}:",
+			Active:   "\U000025B6 {{ . | blue }}",
+			Inactive: "  {{ . | white }}",
+			Selected: "\U000025B6 {{ . | red | blue }}",
+		},
+	}
+	// the bundle is selected by prompt only if user could answer it
+	if !event.Enabled() && style.Interactive() {
+		if idx, _, err := p.Run(); err == nil {
+			return bundles[idx], nil
+		}
+	}
+	return "", fmt.Errorf("%d modules build biz bundles, select one with --sub: %s", len(bundles), strings.Join(items, ", "))
+}
+
+// verify the detached signature of biz bundle against the configured public key
+func execVerifySignature(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	publicKeyContent, err := os.ReadFile(os.ExpandEnv(publicKeyFlag))
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %s", err)
+	}
+	publicKey, err := ark.ParsePublicKey(publicKeyContent)
+	if err != nil {
+		return err
+	}
+
+	signatureUrl, found := bizSignatureUrl(bizModel)
+	var signature []byte
+	if found {
+		style.InfoPrefix("Signature").Println(string(signatureUrl))
+		var localSignature string
+		if localSignature, err = fileutil.DefaultFileUtil().Download(ctx, signatureUrl); err == nil {
+			signature, err = os.ReadFile(strings.TrimPrefix(localSignature, osutil.GetLocalFileProtocol()))
+		}
+	} else {
+		err = errors.New("signature of maven coordinate must be given by --signature")
+	}
+	if err != nil {
+		if requireSignatureFlag {
+			return fmt.Errorf("refuse to deploy unsigned bundle: %s", err)
+		}
+		pterm.Warning.Printfln("skip signature verification, signature not found: %s", err)
+		pterm.Println()
+		return nil
+	}
+
+	localPath := strings.TrimPrefix(string(bizModel.BizUrl), osutil.GetLocalFileProtocol())
+	if err := ark.VerifySignature(localPath, signature, publicKey); err != nil {
+		return fmt.Errorf("failed to verify signature of biz bundle: %s", err)
+	}
+	pterm.Info.Println(pterm.Green("verify signature success!"))
+	pterm.Println()
+	return nil
+}
+
+// bizSignatureUrl return the url of detached signature of biz bundle, and whether it could be found.
+func bizSignatureUrl(bizModel *ark.BizModel) (fileutil.FileUrl, bool) {
+	// the signature of remote bundle is next to the remote one instead of the downloaded one
+	signatureUrl, found := fileutil.FileUrl(signatureFlag), signatureFlag != ""
+	switch {
+	case found && !strings.Contains(signatureFlag, "://"):
+		signatureUrl = fileutil.FileUrl(osutil.GetLocalFileProtocol() + runtime.MustReturnResult(filepath.Abs(signatureFlag)))
+	case !found && fileutil.FileUrl(defaultArg).IsRemote():
+		signatureUrl, found = ark.SignatureUrl(fileutil.FileUrl(defaultArg))
+	case !found:
+		signatureUrl, found = ark.SignatureUrl(bizModel.BizUrl)
+	}
+	return signatureUrl, found
+}
+
+// stamp the biz version with time and git sha, so that the builds deployed before are kept for comparison
+func execStampDevVersion(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	devVersion, output := stampedDevVersion(ctx, bizModel)
+
+	stamped, err := ark.Repackage(ctx, bizModel.BizUrl, output, ark.RepackageOptions{BizVersion: devVersion})
+	if err != nil {
+		return fmt.Errorf("failed to stamp dev version: %w", err)
+	}
+	ctxKeyBizModel.Put(ctx, stamped)
+	style.InfoPrefix("DevVersion").Println(devVersion)
+	pterm.Info.Println(pterm.Green("stamp dev version success!"))
+	pterm.Println()
+	return nil
+}
+
+// stampedDevVersion return the dev version of biz, and where the bundle stamped with it is saved.
+func stampedDevVersion(ctx *contextutil.Context, bizModel *ark.BizModel) (string, string) {
+	workDir := runtime.MustReturnResult(os.Getwd())
+	if doBuild {
+		workDir = defaultArg
+	}
+	devVersion := ark.DevVersion(bizModel.BizVersion, time.Now(), gitShortSha(ctx, workDir))
+	return devVersion, stampedBundlePath(bizModel.BizName, devVersion)
+}
+
+// stampedBundlePath return where the bundle stamped with dev version is saved.
+func stampedBundlePath(bizName, devVersion string) string {
+	return filepath.Join(os.TempDir(), "arkctl-dev", bizName+"-"+devVersion+"-ark-biz.jar")
+}
+
+// removeStampedBundle remove the bundle stamped with dev version, once the base never loads it again.
+func removeStampedBundle(bizName, devVersion string) {
+	if err := os.Remove(stampedBundlePath(bizName, devVersion)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		pterm.Warning.Printfln("failed to remove the bundle stamped with dev version: %s", err)
+	}
+}
+
+// gitShortSha return the short sha of HEAD in dir, or empty if dir is not in a git repository.
+func gitShortSha(ctx *contextutil.Context, dir string) string {
+	gitcmd := cmdutil.BuildCommandWithWorkDir(ctx, dir, "git", "rev-parse", "--short", "HEAD")
+	if err := gitcmd.Exec(); err != nil {
+		return ""
+	}
+	sha := &strings.Builder{}
+	for line := range gitcmd.Output() {
+		sha.WriteString(strings.TrimSpace(line))
+	}
+	for range gitcmd.Wait() {
+	}
+	<-gitcmd.Done()
+	if gitcmd.GetExitError() != nil {
+		return ""
+	}
+	return sha.String()
+}
+
+// generate the SBOM of biz bundle, which is saved next to the deploy records
+func execGenerateSbom(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	bom, err := sbom.Generate(ctx, bizModel.BizUrl)
+	if err != nil {
+		return fmt.Errorf("failed to generate sbom: %s", err)
+	}
+	content := &bytes.Buffer{}
+	if err := bom.Write(content, sbom.Format(sbomFlag)); err != nil {
+		return err
+	}
+
+	sbomPath := bizSbomPath(bizModel)
+	if err := os.MkdirAll(filepath.Dir(sbomPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(sbomPath, content.Bytes(), 0644); err != nil {
+		return err
+	}
+	sbomDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(content.Bytes()))
+	ctxKeySbomPath.Put(ctx, sbomPath)
+	ctxKeySbomDigest.Put(ctx, sbomDigest)
+
+	style.InfoPrefix("Sbom").Println(sbomPath)
+	style.InfoPrefix("SbomDigest").Println(sbomDigest)
+	pterm.Info.Println(pterm.Green("generate sbom success!"))
+	pterm.Println()
+	return nil
+}
+
+// bizSbomPath return where the SBOM of biz is saved, which is next to the deploy records.
+func bizSbomPath(bizModel *ark.BizModel) string {
+	return filepath.Join(filepath.Dir(ark.DefaultDeployRecordPath()), "sbom",
+		fmt.Sprintf("%s-%s.%s.json", bizModel.BizName, bizModel.BizVersion, strings.TrimSuffix(sbomFlag, "-json")))
+}
+
+// queryBaseHealth return the health of target base.
+func queryBaseHealth(ctx *contextutil.Context) (*ark.HealthResponse, error) {
+	if podFlag != "" {
+		health := &ark.HealthResponse{}
+		if err := execArkApiInKubePod(ctx, "health", nil, health); err != nil {
+			return nil, err
+		}
+		return health, ark.IsSuccessResponse(&health.GenericArkResponseBase)
+	}
+
+	arkService := ctxKeyArkService.MustGet(ctx)
+	return arkService.Health(ctx, ark.HealthRequest{
+		HostName: "127.0.0.1",
+		Port:     portFlag,
+	})
+}
+
+// check the class files in biz bundle can be loaded by the jvm of target base
+func execCheckClassVersion(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	health, err := queryBaseHealth(ctx)
+	if err != nil {
+		// the base might not expose health api, let the install stage tell whether it's reachable
+		pterm.Warning.Printfln("skip class version check, failed to query java version of target base: %s", err)
+		pterm.Println()
+		return nil
+	}
+
+	javaVersion := health.Data.HealthData.Jvm.JavaVersion
+	style.InfoPrefix("BaseJavaVersion").Println(javaVersion)
+	if err := ark.CheckClassVersion(ctx, bizModel.BizUrl, javaVersion); err != nil {
+		return withHint(err, "compile your biz module with a java release not higher than the base, e.g. <maven.compiler.release>")
+	}
+
+	pterm.Info.Println(pterm.Green("check class version success!"))
+	pterm.Println()
+	return nil
+}
+
+func execUploadBizBundle(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	if viaFlag == viaS3 {
+		return execUploadBizBundleToS3(ctx)
+	}
+
+	if podFlag != "" && bizModel.BizUrl.GetFileUrlType() == fileutil.FileUrlTypeLocal {
+		localPath := strings.TrimPrefix(string(bizModel.BizUrl), osutil.GetLocalFileProtocol())
+		targetPath, isDir := bundlePathInSidePod(bizModel, runtime.MustReturnResult(uuid.NewUUID()).String())
 		kubecpcmd := cmdutil.BuildCommand(ctx,
 			"kubectl",
 			"-n",
 			podNamespace,
 			"cp",
-			string(bizModel.BizUrl)[7:],
+			localPath,
 			podName+":"+targetPath,
 		)
-		style.InfoPrefix("Stage").Println("UploadBizBundle")
 		style.InfoPrefix("Command").Println(kubecpcmd.String())
 
+		// kubectl cp tells nothing about the progress, so it's reported when the copy starts and ends
+		size := bundleSize(localPath)
+		emitUploadProgress(0, size)
 		if err := kubecpcmd.Exec(); err != nil {
-			pterm.Error.PrintOnError(err)
-			return false
+			return err
 		}
 
 		go func() {
@@ -226,19 +937,123 @@ func execUploadBizBundle(ctx *contextutil.Context) bool {
 		}()
 
 		if err := <-kubecpcmd.Wait(); err != nil {
-			pterm.Error.PrintOnError(err)
-			return false
+			return err
+		}
+		emitUploadProgress(size, size)
+		if isDir {
+			pterm.Warning.Println("skip digest verification of exploded biz directory in pod")
+		} else if err := verifyDigestInKubePod(ctx, targetPath, bizModel.Digest); err != nil {
+			return err
 		}
-		ctx.Put(ctxKeyArkBizBundlePathInSidePod, targetPath)
+		ctxKeyArkBizBundlePathInSidePod.Put(ctx, targetPath)
 		pterm.Info.Println(pterm.LightGreen("upload biz bundle to pod success!"))
 		pterm.Println()
 
 	}
-	return true
+	return nil
+}
+
+// bundlePathInSidePod return where the biz bundle is copied to in target pod, and whether it's an exploded directory.
+func bundlePathInSidePod(bizModel *ark.BizModel, id string) (string, bool) {
+	localPath := strings.TrimPrefix(string(bizModel.BizUrl), osutil.GetLocalFileProtocol())
+	targetPath := fmt.Sprintf("/tmp/%s", bizModel.BizName+"-"+bizModel.BizVersion+"-"+id+"-"+"ark-biz")
+	// exploded biz directory is copied as it is
+	if info, err := os.Stat(localPath); err == nil && !info.IsDir() {
+		return targetPath + ".jar", false
+	}
+	return targetPath, true
+}
+
+// upload the biz bundle to object storage, and let the base install it by presigned url
+func execUploadBizBundleToS3(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	target, localPath, err := s3BundleUrl(bizModel)
+	if err != nil {
+		return err
+	}
+	if localPath != "" {
+		style.InfoPrefix("Upload").Println(string(target))
+		progressCtx := fileutil.WithProgress(ctx, emitUploadProgress)
+		if err := fileutil.DefaultFileUtil().Upload(progressCtx, localPath, target); err != nil {
+			return err
+		}
+	}
+
+	presigned, err := fileutil.DefaultFileUtil().Presign(ctx, target, presignExpires)
+	if err != nil {
+		return err
+	}
+	ctxKeyArkBizBundleUrl.Put(ctx, fileutil.FileUrl(presigned))
+	pterm.Info.Println(pterm.LightGreen("upload biz bundle to object storage success!"))
+	pterm.Println()
+	return nil
 }
 
-func execInstallInKubePod(ctx *contextutil.Context) bool {
-	bizModel := ctx.Value(ctxKeyBizModel).(*ark.BizModel)
+// bundleSize return the size of bundle file, or the total size of files in exploded biz directory.
+func bundleSize(localPath string) int64 {
+	var size int64
+	_ = filepath.WalkDir(localPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// emitUploadProgress emit the bytes of biz bundle uploaded to target.
+func emitUploadProgress(done, total int64) {
+	event.Emit(event.Event{Type: event.TypeUploadProgress, Stage: stageUpload, Done: done, Total: total})
+}
+
+// s3BundleUrl return where the biz bundle is in object storage, and the local bundle to upload there if it's not yet.
+func s3BundleUrl(bizModel *ark.BizModel) (fileutil.FileUrl, string, error) {
+	// the remote bundle is already in object storage
+	target := fileutil.FileUrl(strings.SplitN(defaultArg, "#", 2)[0])
+	if target.GetFileUrlType() == fileutil.FileUrlTypeS3 && !devVersionFlag {
+		return target, "", nil
+	}
+
+	localPath := strings.TrimPrefix(string(bizModel.BizUrl), osutil.GetLocalFileProtocol())
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		return "", "", errors.New("exploded biz directory can not be uploaded to object storage, package it as a jar first")
+	}
+	return fileutil.FileUrl(strings.Join([]string{
+		strings.TrimSuffix(s3PrefixFlag, "/"),
+		bizModel.BizName,
+		bizModel.BizVersion,
+		filepath.Base(localPath),
+	}, "/")), localPath, nil
+}
+
+// installBizUrl return the url where the target base loads the biz bundle from.
+func installBizUrl(ctx *contextutil.Context) fileutil.FileUrl {
+	if bizUrl, ok := ctxKeyArkBizBundleUrl.Get(ctx); ok {
+		return bizUrl
+	}
+	if pathInSidePod, ok := ctxKeyArkBizBundlePathInSidePod.Get(ctx); ok {
+		return fileutil.FileUrl(osutil.GetLocalFileProtocol() + pathInSidePod)
+	}
+	return ctxKeyBizModel.MustGet(ctx).BizUrl
+}
+
+// dev versions are installed side by side, so the installed ones are not uninstalled first
+func execInstallInKubePod(ctx *contextutil.Context) error {
+	if !devVersionFlag {
+		if err := execUnInstallInKubePod(ctx); err != nil {
+			return err
+		}
+	}
+	return execInstallBizInKubePod(ctx)
+}
+
+// uninstall the given package in target pod
+func execUnInstallInKubePod(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
 	kubeuninstallcmd := cmdutil.BuildCommand(ctx,
 		"kubectl",
 		"-n", podNamespace,
@@ -258,8 +1073,7 @@ func execInstallInKubePod(ctx *contextutil.Context) bool {
 
 	style.InfoPrefix("Command").Println(kubeuninstallcmd.String())
 	if err := kubeuninstallcmd.Exec(); err != nil {
-		pterm.Error.PrintOnError(err)
-		return false
+		return err
 	}
 
 	// somehow kubectl exec would pipe the pod's realtime output to stderror pipeline
@@ -274,19 +1088,22 @@ func execInstallInKubePod(ctx *contextutil.Context) bool {
 		if err := kubeuninstallcmd.GetExitError(); err != nil {
 			pterm.Println(realtimeoutputlines)
 			pterm.Println(stdoutlines)
-			pterm.Error.PrintOnError(err)
-			return false
+			return err
 		}
 
 		if strings.Contains(stdoutlines.String(), "\"code\":\"FAILED\"") &&
 			!strings.Contains(stdoutlines.String(), "\"code\":\"NOT_FOUND_BIZ\"") {
 			pterm.Println(realtimeoutputlines)
 			pterm.Println(stdoutlines)
-			pterm.Error.Println("uninstall biz failed!")
-			return false
+			return errors.New("uninstall biz failed")
 		}
 	}
+	return nil
+}
 
+// install the given package in target pod
+func execInstallBizInKubePod(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
 	kubeinstallcmd := cmdutil.BuildCommand(ctx,
 		"kubectl",
 		"-n", podNamespace,
@@ -300,14 +1117,13 @@ func execInstallInKubePod(ctx *contextutil.Context) bool {
 		string(runtime.MustReturnResult(json.Marshal(ark.BizModel{
 			BizName:    bizModel.BizName,
 			BizVersion: bizModel.BizVersion,
-			BizUrl:     fileutil.FileUrl(osutil.GetLocalFileProtocol() + ctx.Value(ctxKeyArkBizBundlePathInSidePod).(string)),
+			BizUrl:     installBizUrl(ctx),
 		}))),
 		fmt.Sprintf("http://127.0.0.1:%v/installBiz", portFlag),
 	)
 	style.InfoPrefix("Command").Println(kubeinstallcmd.String())
 	if err := kubeinstallcmd.Exec(); err != nil {
-		pterm.Error.PrintOnError(err)
-		return false
+		return err
 	}
 
 	// somehow kubectl exec would pipe the pod's realtime output to stderror pipeline
@@ -322,89 +1138,287 @@ func execInstallInKubePod(ctx *contextutil.Context) bool {
 		if err := kubeinstallcmd.GetExitError(); err != nil {
 			pterm.Println(realtimeoutputlines)
 			pterm.Println(stdoutlines)
-			pterm.Error.PrintOnError(err)
-			return false
+			return err
 		}
 
 		if strings.Contains(stdoutlines.String(), "\"code\":\"FAILED\"") {
 			pterm.Println(realtimeoutputlines)
 			pterm.Println(stdoutlines)
-			pterm.Error.Println("install biz failed!")
+			return errors.New("install biz failed")
 		}
 		pterm.Println(stdoutlines)
 	}
 	pterm.Println()
 
-	return true
+	return nil
 }
 
 // install the given package in target ark container
-func execInstallInLocal(ctx *contextutil.Context) bool {
-	return execUnInstallLocal(ctx) && execInstallLocal(ctx)
+// dev versions are installed side by side, so the installed ones are not uninstalled first
+func execInstallInLocal(ctx *contextutil.Context) error {
+	if !devVersionFlag {
+		if err := execUnInstallLocal(ctx); err != nil {
+			return err
+		}
+	}
+	return execInstallLocal(ctx)
 }
 
 // install the given package in target ark container
-func execInstall(ctx *contextutil.Context) (result bool) {
-	style.InfoPrefix("Stage").Println("Install")
+func execInstall(ctx *contextutil.Context) (err error) {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	// the install with --watch is let finish on changes, so that the base is not left half updated
+	if cycle, ok := ctxKeyWatchCycle.Get(ctx); ok && !cycle.beginInstall(ctx) {
+		return ctx.Err()
+	}
+	event.EmitBizState(bizModel.BizName, bizModel.BizVersion, event.StateInstalling)
+
+	installCtx := ctx
+	if installTimeoutFlag > 0 {
+		timeoutCtx, cancel := context.WithTimeout(ctx, installTimeoutFlag)
+		defer cancel()
+		installCtx = contextutil.NewContext(timeoutCtx)
+	}
 
 	switch {
 	case podFlag != "":
-		result = execInstallInKubePod(ctx)
+		err = execInstallInKubePod(installCtx)
 	default:
-		result = execInstallInLocal(ctx)
+		err = execInstallInLocal(installCtx)
 	}
 
-	if result {
-		pterm.Info.Println(pterm.Green("install biz success!"))
-		pterm.Println()
+	// the base in pod or downloading from object storage has its own copy of the stamped bundle,
+	// while the local base keeps loading classes from it until the dev version is pruned
+	if devVersionFlag && (podFlag != "" || viaFlag != "") {
+		removeStampedBundle(bizModel.BizName, bizModel.BizVersion)
 	}
 
+	// the killed kubectl tells nothing about the timeout
+	if err != nil && errors.Is(installCtx.Err(), context.DeadlineExceeded) {
+		err = exitcode.Wrap(exitcode.ActivationTimeout, fmt.Errorf("biz is not activated in %s: %w", installTimeoutFlag, err))
+	}
+	if err != nil {
+		event.EmitBizState(bizModel.BizName, bizModel.BizVersion, event.StateBroken)
+		return
+	}
+	event.EmitBizState(bizModel.BizName, bizModel.BizVersion, event.StateActivated)
+	pterm.Info.Println(pterm.Green("install biz success!"))
+	pterm.Println()
 	return
 }
 
+// execSmokeTest probe the paths under the web context path of biz installed, until they respond with
+// the expected status. The biz failing the probe is uninstalled with --uninstall-on-failure.
+func execSmokeTest(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	webContextPath, err := bizWebContextPath(ctx, bizModel)
+	if err != nil {
+		return err
+	}
+
+	get := probe.HttpGet
+	if podFlag != "" {
+		get = probeInKubePod
+	}
+	for _, path := range probeConfig.Paths {
+		url := probe.Url("127.0.0.1", probeConfig.Port, webContextPath, path)
+		style.InfoPrefix("Probe").Println(url)
+		err := probe.Check(ctx, get, probe.Probe{
+			Url:      url,
+			Status:   probeConfig.Status,
+			Timeout:  *probeConfig.Timeout,
+			Interval: probeInterval,
+		})
+		if err != nil {
+			err = exitcode.Wrap(exitcode.ProbeFailed, err)
+			if probeConfig.UninstallOnFailure {
+				return uninstallFailedBiz(ctx, bizModel, err)
+			}
+			return err
+		}
+	}
+
+	pterm.Info.Println(pterm.Green("smoke test success!"))
+	pterm.Println()
+	return nil
+}
+
+// bizWebContextPath return the web context path of biz installed in target base.
+func bizWebContextPath(ctx *contextutil.Context, bizModel *ark.BizModel) (string, error) {
+	installed, err := queryAllBiz(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to query biz installed in target base: %w", err)
+	}
+	for _, biz := range installed {
+		if biz.BizName == bizModel.BizName && biz.BizVersion == bizModel.BizVersion {
+			return biz.WebContextPath, nil
+		}
+	}
+	return "", fmt.Errorf("biz %s:%s is not installed in target base", bizModel.BizName, bizModel.BizVersion)
+}
+
+// uninstallFailedBiz uninstall the biz failing the probe so that the base does not serve it,
+// and hint how to redeploy the version deployed before, which is not reinstalled.
+func uninstallFailedBiz(ctx *contextutil.Context, bizModel *ark.BizModel, probeErr error) error {
+	style.InfoPrefix("UnInstall").Printfln("biz %s:%s failing the probe", bizModel.BizName, bizModel.BizVersion)
+	event.EmitBizState(bizModel.BizName, bizModel.BizVersion, event.StateUninstalling)
+	if err := unInstallBiz(ctx, bizModel.BizName, bizModel.BizVersion); err != nil {
+		return fmt.Errorf("%w, and failed to uninstall it: %s", probeErr, err)
+	}
+	event.EmitBizState(bizModel.BizName, bizModel.BizVersion, event.StateUninstalled)
+	if devVersionFlag {
+		removeStampedBundle(bizModel.BizName, bizModel.BizVersion)
+	}
+
+	err := fmt.Errorf("%w, biz %s:%s is uninstalled", probeErr, bizModel.BizName, bizModel.BizVersion)
+	records, _ := ark.ReadDeployRecords(ark.DefaultDeployRecordPath())
+	previous, ok := ark.FindPreviousDeployRecord(records, bizModel.BizName, bizModel.BizVersion, deployTarget())
+	if !ok {
+		return err
+	}
+	redeploy := "arkctl deploy " + string(previous.BizUrl)
+	if podFlag != "" {
+		redeploy += " --pod " + podFlag
+	}
+	return withHint(err, fmt.Sprintf("the version deployed before is %s, redeploy it by %s", previous.BizVersion, redeploy))
+}
+
 // uninstall the given package in target ark container
-func execUnInstallLocal(ctx *contextutil.Context) bool {
+func execUnInstallLocal(ctx *contextutil.Context) error {
 	var (
-		arkService              = ctx.Value(ctxKeyArkService).(ark.Service)
-		bizModel                = ctx.Value(ctxKeyBizModel).(*ark.BizModel)
-		arkContainerRuntimeInfo = ctx.Value(ctxKeyArkContainerRuntimeInfo).(*ark.ArkContainerRuntimeInfo)
+		arkService              = ctxKeyArkService.MustGet(ctx)
+		bizModel                = ctxKeyBizModel.MustGet(ctx)
+		arkContainerRuntimeInfo = ctxKeyArkContainerRuntimeInfo.MustGet(ctx)
 	)
 	if err := arkService.UnInstallBiz(ctx, ark.UnInstallBizRequest{
 		BizModel:        *bizModel,
 		TargetContainer: *arkContainerRuntimeInfo,
 	}); err != nil {
-		pterm.Error.PrintOnError(err)
-		printSuggestion(err)
-		return false
+		return withSuggestion(err, nil)
 	}
 
-	return true
+	return nil
 }
 
 // install the given package in target ark container
-func execInstallLocal(ctx *contextutil.Context) bool {
+func execInstallLocal(ctx *contextutil.Context) error {
 	var (
-		arkService              = ctx.Value(ctxKeyArkService).(ark.Service)
-		bizModel                = ctx.Value(ctxKeyBizModel).(*ark.BizModel)
-		arkContainerRuntimeInfo = ctx.Value(ctxKeyArkContainerRuntimeInfo).(*ark.ArkContainerRuntimeInfo)
+		arkService              = ctxKeyArkService.MustGet(ctx)
+		bizModel                = ctxKeyBizModel.MustGet(ctx)
+		arkContainerRuntimeInfo = ctxKeyArkContainerRuntimeInfo.MustGet(ctx)
 	)
 
-	if err := arkService.InstallBiz(ctx, ark.InstallBizRequest{
+	req := ark.InstallBizRequest{
 		BizModel:        *bizModel,
 		TargetContainer: *arkContainerRuntimeInfo,
-	}); err != nil {
-		pterm.Error.PrintOnError(err)
-		printSuggestion(err)
-		return false
 	}
-	return true
+	req.BizModel.BizUrl = installBizUrl(ctx)
+	if err := arkService.InstallBiz(ctx, req); err != nil {
+		return withSuggestion(err, nil)
+	}
+	return nil
+}
+
+// uninstall the dev versions of biz older than the latest kept ones
+func execPruneDevVersions(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+
+	bizInfos, err := queryAllBiz(ctx)
+	if err != nil {
+		// the new version is installed anyway, leave the old ones to be pruned next time
+		pterm.Warning.Printfln("skip pruning dev versions, failed to query installed biz: %s", err)
+		pterm.Println()
+		return nil
+	}
+
+	for _, stale := range ark.StaleDevVersions(bizInfos, bizModel.BizName, bizModel.BizVersion, keepDevVersionsFlag) {
+		style.InfoPrefix("UnInstall").Println(stale.BizName + ":" + stale.BizVersion)
+		event.EmitBizState(stale.BizName, stale.BizVersion, event.StateUninstalling)
+		if err := unInstallBiz(ctx, stale.BizName, stale.BizVersion); err != nil {
+			return err
+		}
+		event.EmitBizState(stale.BizName, stale.BizVersion, event.StateUninstalled)
+		removeStampedBundle(stale.BizName, stale.BizVersion)
+	}
+	pterm.Info.Println(pterm.Green("prune dev versions success!"))
+	pterm.Println()
+	return nil
+}
+
+// queryAllBiz return the biz installed in target base.
+func queryAllBiz(ctx *contextutil.Context) ([]ark.ArkBizInfo, error) {
+	if podFlag != "" {
+		resp := &ark.QueryAllArkBizResponse{}
+		if err := execArkApiInKubePod(ctx, "queryAllBiz", nil, resp); err != nil {
+			return nil, err
+		}
+		return resp.Data, ark.IsSuccessResponse(&resp.GenericArkResponseBase)
+	}
+
+	arkService := ctxKeyArkService.MustGet(ctx)
+	resp, err := arkService.QueryAllBiz(ctx, ark.QueryAllArkBizRequest{
+		HostName: "127.0.0.1",
+		Port:     portFlag,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// unInstallBiz uninstall the given biz version in target base.
+func unInstallBiz(ctx *contextutil.Context, bizName, bizVersion string) error {
+	bizModel := ark.BizModel{BizName: bizName, BizVersion: bizVersion}
+	if podFlag != "" {
+		resp := &ark.ArkResponseBase{}
+		if err := execArkApiInKubePod(ctx, "uninstallBiz", bizModel, resp); err != nil {
+			return err
+		}
+		if resp.Code == "FAILED" && resp.Data.Code != "NOT_FOUND_BIZ" {
+			return fmt.Errorf("uninstall biz %s:%s failed: %s", bizName, bizVersion, resp.Message)
+		}
+		return nil
+	}
+
+	arkService := ctxKeyArkService.MustGet(ctx)
+	return arkService.UnInstallBiz(ctx, ark.UnInstallBizRequest{
+		BizModel:        bizModel,
+		TargetContainer: *ctxKeyArkContainerRuntimeInfo.MustGet(ctx),
+	})
+}
+
+// append the deployed biz to the deploy records, with the SBOM digest if generated
+func execRecordDeploy(ctx *contextutil.Context) error {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	record := ark.DeployRecord{
+		Time:       time.Now().UTC(),
+		BizName:    bizModel.BizName,
+		BizVersion: bizModel.BizVersion,
+		BizUrl:     bizModel.BizUrl,
+		Target:     deployTarget(),
+		Digest:     bizModel.Digest,
+	}
+	if fileutil.FileUrl(defaultArg).IsRemote() {
+		// the downloaded bundle is in cache, which may be evicted
+		record.BizUrl = fileutil.FileUrl(defaultArg)
+	}
+	if sbomDigest, ok := ctxKeySbomDigest.Get(ctx); ok {
+		record.SbomDigest = sbomDigest
+		record.SbomPath = ctxKeySbomPath.MustGet(ctx)
+	}
+
+	// the biz is deployed anyway, failing to record it is not fatal
+	if err := ark.AppendDeployRecord(ark.DefaultDeployRecordPath(), record); err != nil {
+		pterm.Warning.Printfln("failed to record deploy: %s", err)
+	}
+	return nil
 }
 
-func generateContext(cmd *cobra.Command) *contextutil.Context {
-	ctx := contextutil.NewContext(context.Background())
+func generateContext(parent context.Context) *contextutil.Context {
+	ctx := contextutil.NewContext(parent)
 
 	arkService := ark.BuildService(ctx)
-	ctx.Put(ctxKeyArkService, arkService)
+	ctxKeyArkService.Put(ctx, arkService)
 
 	arkContainerRuntimeInfo := &ark.ArkContainerRuntimeInfo{
 		RunType: ark.ArkContainerRunTypeLocal,
@@ -416,32 +1430,59 @@ func generateContext(cmd *cobra.Command) *contextutil.Context {
 		arkContainerRuntimeInfo.RunType = ark.ArkContainerRunTypeK8s
 		arkContainerRuntimeInfo.Coordinate = podFlag
 	}
-	ctx.Put(ctxKeyArkContainerRuntimeInfo, arkContainerRuntimeInfo)
+	ctxKeyArkContainerRuntimeInfo.Put(ctx, arkContainerRuntimeInfo)
 
 	return ctx
 }
 
 // executeDeploy will execute the deploy command
-// 1. build the biz bundle
-// 2. parse the biz model for further usage
-// 3. uninstall the biz bundle in target ark container to prevent conflict
-// 4. install the biz bundle in target ark container
-func executeDeploy(cobracmd *cobra.Command, _ []string) {
-	c := generateContext(cobracmd)
-
-	todos := []func(context2 *contextutil.Context) bool{
-		execMavenBuild,
-		execParseBizModel,
-		execUploadBizBundle,
-		execInstall,
+// 1. build: build the biz bundle, unless the project is not changed since last build
+// 2. parse-biz-model: parse the biz model for further usage
+// 3. verify-signature: verify the signature of biz bundle if a public key is configured
+// 4. stamp-dev-version: stamp the biz version as a dev version if required
+// 5. generate-sbom: generate the SBOM of biz bundle if required
+// 6. check-class-version: check the biz bundle is compatible with the jvm of target base
+// 7. upload: upload the biz bundle and verify its digest in target pod
+// 8. install: uninstall the biz bundle in target ark container to prevent conflict unless it's a dev version, then install it
+// 9. smoke-test: probe the web endpoint of biz installed if required, and uninstall it on failure with --uninstall-on-failure
+// 10. prune-dev-versions: uninstall the dev versions older than the latest kept ones
+// 11. record: record the deployed biz with its SBOM digest
+// the custom stages in project config run after the given ones, and the stages could be selected by --only and --skip.
+// with --watch, the stages are executed again whenever the project is changed.
+// with --dry-run, the stages tell what they will do instead of doing it.
+// with --output events, the stages are reported as newline delimited JSON events instead of human output.
+// the error of failed stage is returned with the exit code telling why it fails.
+func executeDeploy(_ *cobra.Command, _ []string) error {
+	switch {
+	case watchFlag:
+		return watchAndDeploy()
+	case dryRunFlag:
+		return planDeploy(generateContext(context.Background()))
+	default:
+		return runDeploy(generateContext(context.Background()))
 	}
+}
 
-	for _, todo := range todos {
-		if !todo(c) {
-			return
-		}
+// planDeploy print the operations planned by stages and the summary of them, return the error of failed stage if any.
+func planDeploy(c *contextutil.Context) error {
+	style.InfoPrefix("DryRun").Println("nothing is built, uploaded or installed, deploy to " + deployTarget())
+	pterm.Println()
+	if multiModule() {
+		return deployModules(c, (*pipeline.Pipeline).Plan)
 	}
+	report := deployPipeline.Plan(c)
+	printStageSummary(report)
+	return stageError(report)
+}
 
+// runDeploy execute the stages in order and print the summary of them, return the error of failed stage if any.
+func runDeploy(c *contextutil.Context) error {
+	if multiModule() {
+		return deployModules(c, (*pipeline.Pipeline).Run)
+	}
+	report := deployPipeline.Run(c)
+	printStageSummary(report)
+	return stageError(report)
 }
 
 func init() {
@@ -450,12 +1491,125 @@ func init() {
 	DeployCommand.Flags().StringVar(&podFlag, "pod", "", `
 If Provided, arkctl will try to deploy the bundle to the ark container running in given pod.
 `)
-	DeployCommand.Flags().StringVar(&subBundlePath, "sub", "", `
-If Provided, arkctl will try to build the project at current dir and deploy the bundle at subBundlePath.
+	DeployCommand.Flags().StringSliceVar(&subBundlePaths, "sub", nil, `
+If Provided, arkctl will build the module at subBundlePath and the modules it depends on, and deploy its bundle.
+Repeat it to build several modules in one run and deploy the bundle of each, e.g. --sub a --sub b
+`)
+	DeployCommand.Flags().StringVar(&changedSinceFlag, "changed-since", "", `
+If Provided, arkctl will build the modules changed since the commit where the given ref branches off HEAD,
+including uncommitted changes, and the modules depending on them, and deploy the bundle of each, e.g. origin/main
+`)
+	DeployCommand.Flags().BoolVar(&keepGoingFlag, "keep-going", false, `
+If Provided, arkctl will go on building and deploying the other modules after one fails,
+instead of stopping at the first failure.
+`)
+
+	DeployCommand.Flags().StringVar(&buildToolFlag, "build-tool", "", `
+The build tool of project, one of maven and gradle. Detected by pom.xml, build.gradle(.kts) or gradlew if not provided.
+`)
+
+	DeployCommand.Flags().StringArrayVar(&profileFlags, "profile", nil, `
+The maven profiles activated when building the project, appended to build.profiles in project config.
+`)
+	DeployCommand.Flags().StringArrayVar(&buildArgFlags, "maven-arg", nil, `
+The extra args passed to the build tool as they are, e.g. --maven-arg=-T --maven-arg=4.
+Appended to build.args in project config.
+`)
+	DeployCommand.Flags().BoolVar(&offlineFlag, "offline", false, `
+If Provided, arkctl will build the project without accessing remote repositories. Defaults to build.offline in project config.
+`)
+	DeployCommand.Flags().StringVar(&mavenSettingFlag, "settings", "", `
+The maven settings.xml used to build the project. Defaults to build.settings in project config.
+`)
+	DeployCommand.Flags().BoolVar(&skipBuildFlag, "skip-build", false, `
+If Provided, arkctl will deploy the bundle built before instead of building the project.
+`)
+	DeployCommand.Flags().BoolVar(&forceBuildFlag, "force-build", false, `
+If Provided, arkctl will build the project even if its sources, build scripts and resources are not changed since last build.
 `)
 
 	DeployCommand.Flags().IntVar(&portFlag, "port", 1238, `
 The default port of ark container is 1238 if not provided.
+`)
+
+	DeployCommand.Flags().StringArrayVar(&headerFlags, "header", nil, `
+If Provided, arkctl will send the header when downloading remote bundle, in the format of "name: value".
+`)
+	DeployCommand.Flags().StringVar(&checksumFlag, "checksum", "", `
+If Provided, arkctl will verify the downloaded remote bundle against the checksum, in the format of sha256:{hex}.
+`)
+	DeployCommand.Flags().StringVar(&publicKeyFlag, "public-key", "", `
+If Provided, arkctl will verify the detached signature of bundle against the PEM encoded public key.
+Defaults to signature.publicKey in config.
+`)
+	DeployCommand.Flags().StringVar(&signatureFlag, "signature", "", `
+The detached signature of bundle, defaults to the bundle path or url with .sig suffix.
+`)
+	DeployCommand.Flags().BoolVar(&requireSignatureFlag, "require-signature", false, `
+If Provided, arkctl will refuse to deploy bundles without signature. Defaults to signature.required in config.
+`)
+	DeployCommand.Flags().StringVar(&viaFlag, "via", "", `
+If Provided, arkctl will hand the bundle to target base through the given channel instead of the local file system.
+Supported: s3, which uploads the bundle to S3 compatible object storage and installs it by presigned url.
+`)
+	DeployCommand.Flags().StringVar(&s3PrefixFlag, "s3-prefix", "", `
+The prefix the bundle is uploaded to with --via s3, in the format of s3://bucket/path. Defaults to s3.prefix in config.
+`)
+	DeployCommand.Flags().BoolVar(&devVersionFlag, "dev-version", false, `
+If Provided, arkctl will stamp the biz version as {version}-dev.{timestamp}.{git sha} before install,
+and install it side by side with the dev versions deployed before.
+`)
+	DeployCommand.Flags().IntVar(&keepDevVersionsFlag, "keep-dev-versions", 3, `
+The number of dev versions of biz kept installed with --dev-version, including the new one. 0 keeps all of them.
+`)
+	DeployCommand.Flags().StringVar(&sbomFlag, "sbom", "", `
+If Provided, arkctl will generate the SBOM of bundle in given format, one of cyclonedx-json and spdx-json,
+and attach its digest to the deploy record.
+`)
+	DeployCommand.Flags().DurationVar(&installTimeoutFlag, "install-timeout", 10*time.Minute, `
+How long arkctl waits for the biz to be installed and activated in target base, 0 waits forever.
+`)
+	DeployCommand.Flags().StringArrayVar(&probePathFlags, "probe", nil, `
+If Provided, arkctl will GET the path under the web context path of biz after install, e.g. --probe /actuator/health,
+and fail the deploy unless it responds with the expected status in time. Appended to probe.paths in project config.
+`)
+	DeployCommand.Flags().IntVar(&probePortFlag, "probe-port", 8080, `
+The web port of base the probe paths are served on. Defaults to probe.port in project config, or 8080.
+`)
+	DeployCommand.Flags().IntSliceVar(&probeStatusFlags, "probe-status", []int{200}, `
+The status codes expected from the probe paths, e.g. --probe-status 200,204. Defaults to probe.status in project config, or 200.
+`)
+	DeployCommand.Flags().DurationVar(&probeTimeoutFlag, "probe-timeout", 30*time.Second, `
+How long arkctl probes a path until it responds with the expected status, 0 waits forever.
+Defaults to probe.timeout in project config, or 30s.
+`)
+	DeployCommand.Flags().BoolVar(&uninstallOnFailureFlag, "uninstall-on-failure", false, `
+If Provided, arkctl will uninstall the biz failing the probe, the version deployed before is not reinstalled.
+Defaults to probe.uninstallOnFailure in project config.
+`)
+	DeployCommand.Flags().BoolVar(&watchFlag, "watch", false, `
+If Provided, arkctl will keep watching the project, and rebuild and redeploy it whenever its sources are changed.
+The build in progress is cancelled once new changes arrive, while the install in progress is let finish first.
+`)
+	DeployCommand.Flags().DurationVar(&watchDebounceFlag, "watch-debounce", 500*time.Millisecond, `
+How long arkctl waits for no more changes before rebuilding with --watch, so that a burst of saves triggers one build.
+`)
+	DeployCommand.Flags().BoolVar(&dryRunFlag, "dry-run", false, `
+If Provided, arkctl will print what deploy will do, e.g. the build command, the upload destination,
+the biz to uninstall and the install requests, without building, uploading or installing anything.
+The biz installed in target base is queried to tell what will be uninstalled.
+`)
+	DeployCommand.Flags().StringSliceVar(&onlyStageFlags, "only", nil, `
+If Provided, arkctl will run the given stages only, e.g. --only build,parse-biz-model
+Stages: build, parse-biz-model, verify-signature, stamp-dev-version, generate-sbom, check-class-version,
+upload, install, smoke-test, prune-dev-versions, record, and the custom ones in the stages section of .arkctl.yaml.
+`)
+	DeployCommand.Flags().StringSliceVar(&skipStageFlags, "skip", nil, `
+If Provided, arkctl will skip the given stages, e.g. --skip check-class-version
+`)
+	DeployCommand.Flags().StringVar(&outputFlag, "output", event.OutputText, `
+The output format, text or events. With events, arkctl prints one JSON object per line for each
+stage started and finished, log line, upload progress, biz state and error, and nothing else.
 `)
 
 }
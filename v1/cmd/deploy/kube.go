@@ -0,0 +1,136 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/cmdutil"
+)
+
+// execArkApiInKubePod call the ark api of the container running in target pod with curl,
+// and decode the json response into result. The request is sent as json body if not nil.
+func execArkApiInKubePod(ctx context.Context, api string, request, result interface{}) error {
+	args := []string{
+		"-n", podNamespace,
+		"exec", podName, "--",
+		"curl",
+		"-s",
+		"-X",
+		"POST",
+	}
+	if request != nil {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		args = append(args, "-H", "Content-Type: application/json", "-d", string(body))
+	}
+	args = append(args, fmt.Sprintf("http://127.0.0.1:%v/%s", portFlag, api))
+	kubecmd := cmdutil.BuildCommand(ctx, "kubectl", args...)
+	if err := kubecmd.Exec(); err != nil {
+		return err
+	}
+
+	stdoutlines := &strings.Builder{}
+	for line := range kubecmd.Output() {
+		stdoutlines.WriteString(line)
+	}
+
+	// somehow kubectl exec would pipe the pod's realtime output to stderror pipeline
+	// so we judge by the response instead of the stderror pipeline output
+	stderrlines := &strings.Builder{}
+	for err := range kubecmd.Wait() {
+		stderrlines.WriteString(err.Error())
+	}
+
+	if err := json.Unmarshal([]byte(stdoutlines.String()), result); err != nil {
+		return fmt.Errorf("call %s in pod %s/%s failed: %s%s", api, podNamespace, podName, stdoutlines, stderrlines)
+	}
+	return nil
+}
+
+// probeInKubePod GET the url with curl in target pod, return the status code of response.
+func probeInKubePod(ctx context.Context, url string) (int, error) {
+	kubecmd := cmdutil.BuildCommand(ctx,
+		"kubectl",
+		"-n", podNamespace,
+		"exec", podName, "--",
+		"curl",
+		"-sS",
+		"-o", "/dev/null",
+		"-w", "%{http_code}",
+		url,
+	)
+	if err := kubecmd.Exec(); err != nil {
+		return 0, err
+	}
+
+	stdoutlines := &strings.Builder{}
+	for line := range kubecmd.Output() {
+		stdoutlines.WriteString(line)
+	}
+	stderrlines := &strings.Builder{}
+	for err := range kubecmd.Wait() {
+		stderrlines.WriteString(err.Error())
+	}
+
+	// curl writes 000 if the url could not be connected
+	status, err := strconv.Atoi(strings.TrimSpace(stdoutlines.String()))
+	if err != nil || status == 0 {
+		return 0, fmt.Errorf("curl %s in pod %s/%s failed: %s", url, podNamespace, podName, strings.TrimSpace(stderrlines.String()))
+	}
+	return status, nil
+}
+
+// verifyDigestInKubePod compare the sha256 digest of file in target pod with the expected one,
+// since kubectl cp may silently truncate the file.
+func verifyDigestInKubePod(ctx context.Context, pathInSidePod, expectedDigest string) error {
+	kubecmd := cmdutil.BuildCommand(ctx,
+		"kubectl",
+		"-n", podNamespace,
+		"exec", podName, "--",
+		"sha256sum",
+		pathInSidePod,
+	)
+	if err := kubecmd.Exec(); err != nil {
+		return err
+	}
+
+	stdoutlines := &strings.Builder{}
+	for line := range kubecmd.Output() {
+		stdoutlines.WriteString(line)
+	}
+	stderrlines := &strings.Builder{}
+	for err := range kubecmd.Wait() {
+		stderrlines.WriteString(err.Error())
+	}
+
+	fields := strings.Fields(stdoutlines.String())
+	if len(fields) == 0 {
+		return fmt.Errorf("failed to compute digest of %s in pod %s/%s: %s", pathInSidePod, podNamespace, podName, stderrlines)
+	}
+	if digest := "sha256:" + fields[0]; digest != expectedDigest {
+		return fmt.Errorf("digest mismatch of %s in pod %s/%s: expected %s, got %s", pathInSidePod, podNamespace, podName, expectedDigest, digest)
+	}
+	return nil
+}
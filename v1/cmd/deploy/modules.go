@@ -0,0 +1,199 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/service/build"
+	"github.com/koupleless/arkctl/v1/service/pipeline"
+
+	"github.com/pterm/pterm"
+)
+
+// moduleResult is the result of deploying the biz bundle of a module.
+type moduleResult struct {
+	module   string // the bundle relative to project, or the module failed to build
+	biz      string
+	status   pipeline.Status
+	stage    string // the stage failed
+	reason   string // why the module is not deployed
+	err      error
+	duration time.Duration
+}
+
+// multiModule return true if several modules are built in one run and each of them is deployed.
+func multiModule() bool {
+	return len(subBundlePaths) > 1 || changedSinceFlag != ""
+}
+
+// deployModules build the modules in one run with the stages before parse-biz-model,
+// then run the rest of stages for the bundle of each module built, by run or plan of pipeline.
+// the modules after the failed one are not deployed unless --keep-going.
+func deployModules(c *contextutil.Context, do func(p *pipeline.Pipeline, ctx *contextutil.Context) *pipeline.Report) error {
+	if nothingChanged {
+		pterm.Info.Printfln("no module changed since %s, nothing to deploy", changedSinceFlag)
+		return nil
+	}
+
+	buildPipeline, modulePipeline := deployPipeline.Split(stageParseBizModel)
+	buildReport := do(buildPipeline, c)
+	printStageSummary(buildReport)
+	var results []moduleResult
+	if err := stageError(buildReport); err != nil {
+		if !keepGoingFlag || buildReport.Failed().Stage != stageBuild {
+			return err
+		}
+		results = failedBuildModules(c, err)
+	}
+
+	bundles, err := locateBuiltBundles(c, bundleSearchDirs())
+	if err != nil {
+		return fmt.Errorf("failed to locate built biz bundles: %w", err)
+	}
+	if len(bundles) == 0 && len(results) == 0 {
+		if dryRunFlag {
+			return fmt.Errorf("no biz bundle built before in %s, the rest of plan is known after build, try --only build first", strings.Join(bundleSearchDirs(), ", "))
+		}
+		return fmt.Errorf("no biz bundle built in %s", strings.Join(bundleSearchDirs(), ", "))
+	}
+
+	stopped := false
+	for _, bundle := range bundles {
+		result := moduleResult{module: relativeToProject(bundle), status: pipeline.StatusNotRun}
+		if stopped {
+			result.reason = "a module before failed, use --keep-going to deploy it anyway"
+			results = append(results, result)
+			continue
+		}
+
+		style.InfoPrefix("Module").Println(result.module)
+		pterm.Println()
+		ctx := generateContext(c)
+		ctxKeyBizBundle.Put(ctx, bundle)
+		report := do(modulePipeline, ctx)
+		printStageSummary(report)
+
+		result.status, result.duration = pipeline.StatusSucceeded, report.Duration
+		if dryRunFlag {
+			result.status = pipeline.StatusPlanned
+		}
+		if bizModel, ok := ctxKeyBizModel.Get(ctx); ok {
+			result.biz = bizModel.BizName + ":" + bizModel.BizVersion
+		}
+		if failed := report.Failed(); failed != nil {
+			result.status, result.stage, result.err = pipeline.StatusFailed, failed.Stage, stageError(report)
+			stopped = !keepGoingFlag
+		}
+		emitModuleResult(result)
+		results = append(results, result)
+	}
+
+	printModuleSummary(results)
+	return modulesError(results)
+}
+
+// failedBuildModules return the results of modules failed to build, and those skipped by the build tool
+// for depending on the failed ones. The build tool telling nothing about modules is taken as one failed module.
+func failedBuildModules(c *contextutil.Context, err error) []moduleResult {
+	var results []moduleResult
+	modules, _ := ctxKeyBuildModules.Get(c)
+	for _, module := range modules {
+		switch module.State {
+		case build.ModuleFailure:
+			results = append(results, moduleResult{module: module.Name, status: pipeline.StatusFailed, stage: stageBuild, err: err, duration: module.Duration})
+		case build.ModuleSkipped:
+			results = append(results, moduleResult{module: module.Name, status: pipeline.StatusNotRun, reason: "depends on a module failed to build"})
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, moduleResult{module: buildModule(), status: pipeline.StatusFailed, stage: stageBuild, err: err})
+	}
+	for _, result := range results {
+		emitModuleResult(result)
+	}
+	return results
+}
+
+// relativeToProject return the path of bundle relative to the project.
+func relativeToProject(bundle string) string {
+	if rel, err := filepath.Rel(defaultArg, bundle); err == nil {
+		return rel
+	}
+	return bundle
+}
+
+func emitModuleResult(result moduleResult) {
+	e := event.Event{
+		Type:       event.TypeModuleFinished,
+		Module:     result.module,
+		Biz:        result.biz,
+		Status:     string(result.status),
+		Stage:      result.stage,
+		DurationMs: result.duration.Milliseconds(),
+		Reason:     result.reason,
+	}
+	if result.err != nil {
+		e.Message = result.err.Error()
+	}
+	event.Emit(e)
+}
+
+// printModuleSummary print the result of each module in a table.
+func printModuleSummary(results []moduleResult) {
+	data := [][]string{{"Module", "Biz", "Status", "Time", "Note"}}
+	for _, result := range results {
+		elapsed := ""
+		if result.duration > 0 {
+			elapsed = result.duration.Round(time.Millisecond).String()
+		}
+		note := result.reason
+		if result.err != nil {
+			// the error of build is followed by the whole stderr of build tool
+			note = strings.SplitN(result.err.Error(), "\n", 2)[0]
+		}
+		data = append(data, []string{result.module, result.biz, string(result.status), elapsed, note})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	pterm.Println()
+}
+
+// modulesError return the error telling how many modules failed, with the exit code of the first failed one.
+func modulesError(results []moduleResult) error {
+	var first error
+	failed := 0
+	for _, result := range results {
+		if result.status == pipeline.StatusFailed {
+			if first == nil {
+				first = result.err
+			}
+			failed++
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return exitcode.Wrap(exitcode.Of(first), fmt.Errorf("%d of %d modules failed to deploy: %w", failed, len(results), first))
+}
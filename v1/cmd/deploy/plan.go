@@ -0,0 +1,271 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/koupleless/arkctl/common/runtime"
+	"github.com/koupleless/arkctl/v1/service/ark"
+	"github.com/koupleless/arkctl/v1/service/build"
+	"github.com/koupleless/arkctl/v1/service/pipeline"
+	"github.com/koupleless/arkctl/v1/service/probe"
+)
+
+// ctxKeyInstalledBiz is the biz installed in target base queried in dry run.
+var ctxKeyInstalledBiz = pipeline.Key[[]ark.ArkBizInfo]("ark.InstalledBiz")
+
+// the plan funcs below tell what the stages will do in dry run, they query the target base
+// and read the project, but never build, upload or install anything.
+
+func planBuild(ctx *contextutil.Context) ([]string, error) {
+	if !forceBuildFlag {
+		fingerprint, err := build.Fingerprint(defaultArg, buildTool, buildOptions)
+		if err == nil {
+			if bundles, ok := build.ReusableBundles(defaultArg, buildModule(), fingerprint); ok {
+				ctxKeyReusedBundles.Put(ctx, bundles)
+				return []string{"reuse the bundles built before, sources not changed: " + strings.Join(bundles, ", ")}, nil
+			}
+		}
+	}
+
+	executable, args := buildTool.Command(defaultArg, buildOptions)
+	return []string{fmt.Sprintf("run %s %s in %s", executable, strings.Join(args, " "), defaultArg)}, nil
+}
+
+func planParseBizModel(ctx *contextutil.Context) ([]string, error) {
+	var plan []string
+	bundleUrl := fileutil.FileUrl(osutil.GetLocalFileProtocol() + defaultArg)
+	switch {
+	case fileutil.FileUrl(defaultArg).IsRemote():
+		// the remote bundle is not fetched in dry run, so the biz in it is known after download
+		download, err := planDownload(defaultArg)
+		if err != nil {
+			return nil, err
+		}
+		ctxKeyBizModel.Put(ctx, &ark.BizModel{
+			BizName:    "{bizName}",
+			BizVersion: "{bizVersion}",
+			BizUrl:     fileutil.FileUrl(osutil.GetLocalFileProtocol() + "{downloadedBundle}"),
+			Digest:     "{digest}",
+		})
+		return append(download, "deploy the biz in the downloaded bundle, whose name and version are known after download"), nil
+
+	case ctxKeyBizBundle.Present(ctx):
+		bundleUrl = fileutil.FileUrl(osutil.GetLocalFileProtocol() + ctxKeyBizBundle.MustGet(ctx))
+
+	case doBuild:
+		bundles, reused := ctxKeyReusedBundles.Get(ctx)
+		if !reused {
+			// the built bundle is stale, but tells the biz name and version to deploy
+			var err error
+			if bundles, err = locateBuiltBundles(ctx, bundleSearchDirs()); err != nil {
+				return nil, fmt.Errorf("failed to locate built biz bundle: %s", err)
+			}
+			if len(bundles) == 0 {
+				return nil, fmt.Errorf("no biz bundle built before in %s, the rest of plan is known after build, try --only build first", strings.Join(bundleSearchDirs(), ", "))
+			}
+		}
+		builtBundle, err := selectBizBundle(bundles)
+		if err != nil {
+			return nil, err
+		}
+		if !reused {
+			plan = append(plan, "resolve the biz by the bundle built before, which may change after build")
+		}
+		bundleUrl = fileutil.FileUrl(osutil.GetLocalFileProtocol() + builtBundle)
+	}
+
+	bizModel, err := ark.ParseBizModel(ctx, bundleUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %s", err)
+	}
+	ctxKeyBizModel.Put(ctx, bizModel)
+	return append(plan, fmt.Sprintf("deploy biz %s:%s in %s", bizModel.BizName, bizModel.BizVersion, bizModel.BizUrl)), nil
+}
+
+// planDownload tell where the remote bundle is fetched from.
+func planDownload(remoteUrl string) ([]string, error) {
+	if fileutil.FileUrl(remoteUrl).GetFileUrlType() != fileutil.FileUrlTypeMaven {
+		return []string{"download " + remoteUrl}, nil
+	}
+
+	coordinate, err := fileutil.ParseMavenCoordinate(remoteUrl)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := fileutil.MavenLocations(coordinate)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("resolve %s from %s", coordinate, strings.Join(locations, ", then "))}, nil
+}
+
+func planVerifySignature(ctx *contextutil.Context) ([]string, error) {
+	signatureUrl, found := bizSignatureUrl(ctxKeyBizModel.MustGet(ctx))
+	switch {
+	case found:
+		return []string{fmt.Sprintf("verify signature %s with public key %s", signatureUrl, publicKeyFlag)}, nil
+	case requireSignatureFlag:
+		return nil, fmt.Errorf("refuse to deploy unsigned bundle: signature of maven coordinate must be given by --signature")
+	}
+	return []string{"skip signature verification, signature of maven coordinate must be given by --signature"}, nil
+}
+
+func planStampDevVersion(ctx *contextutil.Context) ([]string, error) {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	devVersion, output := stampedDevVersion(ctx, bizModel)
+
+	stamped := *bizModel
+	stamped.BizVersion = devVersion
+	stamped.BizUrl = fileutil.FileUrl(osutil.GetLocalFileProtocol() + output)
+	ctxKeyBizModel.Put(ctx, &stamped)
+	return []string{fmt.Sprintf("repackage the bundle as %s with biz version %s", output, devVersion)}, nil
+}
+
+func planGenerateSbom(ctx *contextutil.Context) ([]string, error) {
+	return []string{fmt.Sprintf("generate %s SBOM to %s", sbomFlag, bizSbomPath(ctxKeyBizModel.MustGet(ctx)))}, nil
+}
+
+func planCheckClassVersion(ctx *contextutil.Context) ([]string, error) {
+	health, err := queryBaseHealth(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("skip class version check, failed to query java version of target base: %s", err)}, nil
+	}
+	return []string{"check the class files against java " + health.Data.HealthData.Jvm.JavaVersion + " of target base"}, nil
+}
+
+func planUpload(ctx *contextutil.Context) ([]string, error) {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	localPath := strings.TrimPrefix(string(bizModel.BizUrl), osutil.GetLocalFileProtocol())
+
+	if viaFlag == viaS3 {
+		target, localPath, err := s3BundleUrl(bizModel)
+		if err != nil {
+			return nil, err
+		}
+		var plan []string
+		if localPath != "" {
+			plan = append(plan, fmt.Sprintf("upload %s to %s", localPath, target))
+		}
+		ctxKeyArkBizBundleUrl.Put(ctx, target)
+		return append(plan, fmt.Sprintf("presign %s for %s", target, presignExpires)), nil
+	}
+
+	if bizModel.BizUrl.GetFileUrlType() != fileutil.FileUrlTypeLocal {
+		return []string{"the base downloads the bundle from " + string(bizModel.BizUrl)}, nil
+	}
+	targetPath, isDir := bundlePathInSidePod(bizModel, "{uuid}")
+	ctxKeyArkBizBundlePathInSidePod.Put(ctx, targetPath)
+	plan := []string{fmt.Sprintf("kubectl -n %s cp %s %s:%s", podNamespace, localPath, podName, targetPath)}
+	if !isDir {
+		plan = append(plan, fmt.Sprintf("verify the digest of %s in pod is %s", targetPath, bizModel.Digest))
+	}
+	return plan, nil
+}
+
+func planInstall(ctx *contextutil.Context) ([]string, error) {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	var plan []string
+
+	installed := false
+	if bizInfos, err := queryAllBiz(ctx); err != nil {
+		plan = append(plan, fmt.Sprintf("failed to query installed biz in %s: %s", deployTarget(), err))
+	} else {
+		ctxKeyInstalledBiz.Put(ctx, bizInfos)
+		names := make([]string, 0, len(bizInfos))
+		for _, bizInfo := range bizInfos {
+			names = append(names, fmt.Sprintf("%s:%s(%s)", bizInfo.BizName, bizInfo.BizVersion, bizInfo.BizState))
+			installed = installed || (bizInfo.BizName == bizModel.BizName && bizInfo.BizVersion == bizModel.BizVersion)
+		}
+		plan = append(plan, fmt.Sprintf("installed in %s: %s", deployTarget(), strings.Join(names, ", ")))
+	}
+
+	if !devVersionFlag {
+		uninstall := ark.BizModel{BizName: bizModel.BizName, BizVersion: bizModel.BizVersion}
+		operation := fmt.Sprintf("uninstall %s:%s", bizModel.BizName, bizModel.BizVersion)
+		if !installed {
+			operation += ", which is not installed yet"
+		}
+		plan = append(plan, operation, arkApiRequest("uninstallBiz", uninstall))
+	}
+
+	install := ark.BizModel{BizName: bizModel.BizName, BizVersion: bizModel.BizVersion, BizUrl: installBizUrl(ctx)}
+	return append(plan, fmt.Sprintf("install %s:%s", bizModel.BizName, bizModel.BizVersion), arkApiRequest("installBiz", install)), nil
+}
+
+func planSmokeTest(_ *contextutil.Context) ([]string, error) {
+	where := ""
+	if podFlag != "" {
+		where = " inside pod " + podNamespace + "/" + podName
+	}
+	status := strings.Trim(fmt.Sprint(probeConfig.Status), "[]")
+	within := fmt.Sprintf(" in %s", *probeConfig.Timeout)
+	if *probeConfig.Timeout == 0 {
+		within = ""
+	}
+	var plan []string
+	for _, path := range probeConfig.Paths {
+		url := probe.Url("127.0.0.1", probeConfig.Port, "{webContextPath}", path)
+		plan = append(plan, fmt.Sprintf("GET %s%s until it responds %s%s", url, where, status, within))
+	}
+	if probeConfig.UninstallOnFailure {
+		plan = append(plan, "uninstall the biz if it fails the probe")
+	}
+	return plan, nil
+}
+
+func planPruneDevVersions(ctx *contextutil.Context) ([]string, error) {
+	bizModel := ctxKeyBizModel.MustGet(ctx)
+	bizInfos, ok := ctxKeyInstalledBiz.Get(ctx)
+	if !ok {
+		return []string{"skip pruning dev versions, failed to query installed biz"}, nil
+	}
+
+	var plan []string
+	for _, stale := range ark.StaleDevVersions(bizInfos, bizModel.BizName, bizModel.BizVersion, keepDevVersionsFlag) {
+		plan = append(plan, fmt.Sprintf("uninstall %s:%s", stale.BizName, stale.BizVersion),
+			arkApiRequest("uninstallBiz", ark.BizModel{BizName: stale.BizName, BizVersion: stale.BizVersion}))
+	}
+	if len(plan) == 0 {
+		plan = append(plan, fmt.Sprintf("no dev versions of %s older than the latest %d ones", bizModel.BizName, keepDevVersionsFlag))
+	}
+	return plan, nil
+}
+
+func planRecordDeploy(_ *contextutil.Context) ([]string, error) {
+	return []string{"append the deploy record to " + ark.DefaultDeployRecordPath()}, nil
+}
+
+// deployTarget return the base deployed to, e.g. 127.0.0.1:1238 or pod default/base-0
+func deployTarget() string {
+	if podFlag != "" {
+		return "pod " + podNamespace + "/" + podName
+	}
+	return fmt.Sprintf("127.0.0.1:%d", portFlag)
+}
+
+// arkApiRequest describe the request of ark api sent to target base.
+func arkApiRequest(api string, body interface{}) string {
+	return fmt.Sprintf("POST http://127.0.0.1:%d/%s %s", portFlag, api, runtime.MustReturnResult(json.Marshal(body)))
+}
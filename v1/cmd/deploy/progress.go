@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/service/build"
+
+	"github.com/pterm/pterm"
+)
+
+// mavenProgress show the progress of maven reactor, as a progress bar of modules in terminal,
+// or a line per module started and finished in CI mode or if maven does not count the modules.
+type mavenProgress struct {
+	plain bool
+	bar   *pterm.ProgressbarPrinter
+}
+
+func newMavenProgress() *mavenProgress {
+	return &mavenProgress{plain: style.CI() || !pterm.Output}
+}
+
+// update show the module started, running a goal or finished.
+func (p *mavenProgress) update(module build.ReactorModule) {
+	name := module.Name
+	if module.Total > 0 {
+		name = fmt.Sprintf("[%d/%d] %s", module.Index, module.Total, module.Name)
+	}
+	finished := module.State != build.ModuleBuilding
+	result := fmt.Sprintf("%s %s in %s", name, module.State, module.Duration.Round(time.Millisecond))
+	if module.State == build.ModuleSkipped {
+		result = fmt.Sprintf("%s %s", name, module.State)
+	}
+
+	// the modules are counted only by maven 3.6 and later
+	if p.bar == nil && module.Total == 0 {
+		p.plain = true
+	}
+	if p.plain {
+		switch {
+		case finished:
+			style.InfoPrefix("Module").Println(result)
+		case module.Goal == "":
+			style.InfoPrefix("Module").Println(name)
+		}
+		return
+	}
+
+	if p.bar == nil {
+		// the result of each module is printed, so the bar is removed once all modules finish
+		p.bar, _ = pterm.DefaultProgressbar.WithTotal(module.Total).WithTitle(module.Name).WithRemoveWhenDone().Start()
+	}
+	if !finished {
+		p.bar.UpdateTitle(strings.TrimSpace(module.Name + " " + module.Goal))
+		return
+	}
+
+	switch module.State {
+	case build.ModuleSuccess:
+		pterm.Success.Println(result)
+	case build.ModuleFailure:
+		pterm.Error.Println(result)
+	default:
+		pterm.Warning.Println(result)
+	}
+	p.bar.Increment()
+}
+
+// stop remove the progress bar.
+func (p *mavenProgress) stop() {
+	if p.bar != nil {
+		_, _ = p.bar.Stop()
+	}
+}
@@ -0,0 +1,295 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/config"
+	"github.com/koupleless/arkctl/v1/service/pipeline"
+
+	"github.com/pterm/pterm"
+)
+
+const (
+	stageBuild             = "build"
+	stageParseBizModel     = "parse-biz-model"
+	stageVerifySignature   = "verify-signature"
+	stageStampDevVersion   = "stamp-dev-version"
+	stageGenerateSbom      = "generate-sbom"
+	stageCheckClassVersion = "check-class-version"
+	stageUpload            = "upload"
+	stageInstall           = "install"
+	stageSmokeTest         = "smoke-test"
+	stagePruneDevVersions  = "prune-dev-versions"
+	stageRecord            = "record"
+)
+
+// newDeployPipeline return the pipeline of deploy stages, with the custom stages in project config.
+func newDeployPipeline(stageConfigs []config.StageConfig, configDir string) (*pipeline.Pipeline, error) {
+	bizModel := []pipeline.Ref{ctxKeyBizModel}
+	p := pipeline.New(
+		&pipeline.Func{
+			StageName: stageBuild,
+			SkipFunc:  skipBuild,
+			RunFunc:   execBuild,
+			PlanFunc:  planBuild,
+		},
+		&pipeline.Func{
+			StageName: stageParseBizModel,
+			Out:       bizModel,
+			RunFunc:   execParseBizModel,
+			PlanFunc:  planParseBizModel,
+		},
+		&pipeline.Func{
+			StageName: stageVerifySignature,
+			In:        bizModel,
+			SkipFunc:  skipUnless(publicKeyFlag != "", "no public key configured"),
+			RunFunc:   execVerifySignature,
+			PlanFunc:  planVerifySignature,
+		},
+		&pipeline.Func{
+			StageName: stageStampDevVersion,
+			In:        bizModel,
+			Out:       bizModel,
+			SkipFunc:  skipUnless(devVersionFlag, "--dev-version not set"),
+			RunFunc:   execStampDevVersion,
+			PlanFunc:  planStampDevVersion,
+		},
+		&pipeline.Func{
+			StageName: stageGenerateSbom,
+			In:        bizModel,
+			Out:       []pipeline.Ref{ctxKeySbomPath, ctxKeySbomDigest},
+			SkipFunc:  skipUnless(sbomFlag != "", "--sbom not set"),
+			RunFunc:   execGenerateSbom,
+			PlanFunc:  planGenerateSbom,
+		},
+		&pipeline.Func{
+			StageName: stageCheckClassVersion,
+			In:        bizModel,
+			RunFunc:   execCheckClassVersion,
+			PlanFunc:  planCheckClassVersion,
+		},
+		&pipeline.Func{
+			StageName: stageUpload,
+			In:        bizModel,
+			Out:       []pipeline.Ref{ctxKeyArkBizBundlePathInSidePod, ctxKeyArkBizBundleUrl},
+			SkipFunc:  skipUnless(viaFlag != "" || podFlag != "", "local base loads the bundle from local file system"),
+			RunFunc:   execUploadBizBundle,
+			PlanFunc:  planUpload,
+		},
+		&pipeline.Func{
+			StageName: stageInstall,
+			In:        bizModel,
+			RunFunc:   execInstall,
+			PlanFunc:  planInstall,
+		},
+		&pipeline.Func{
+			StageName: stageSmokeTest,
+			In:        bizModel,
+			SkipFunc:  skipUnless(len(probeConfig.Paths) > 0, "no probe paths configured"),
+			RunFunc:   execSmokeTest,
+			PlanFunc:  planSmokeTest,
+		},
+		&pipeline.Func{
+			StageName: stagePruneDevVersions,
+			In:        bizModel,
+			SkipFunc:  skipUnless(devVersionFlag && keepDevVersionsFlag > 0, "no dev versions to prune"),
+			RunFunc:   execPruneDevVersions,
+			PlanFunc:  planPruneDevVersions,
+		},
+		&pipeline.Func{
+			StageName: stageRecord,
+			In:        bizModel,
+			RunFunc:   execRecordDeploy,
+			PlanFunc:  planRecordDeploy,
+		},
+	)
+
+	for _, stageConfig := range stageConfigs {
+		stage := &pipeline.CommandStage{
+			StageName: stageConfig.Name,
+			Dir:       configDir,
+			Command:   stageConfig.Command,
+			Output:    printStageOutput(stageConfig.Name),
+		}
+		if err := p.Insert(stage, stageConfig.After); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.Select(onlyStageFlags, skipStageFlags); err != nil {
+		return nil, err
+	}
+	p.OnStart = func(stage pipeline.Stage) {
+		style.InfoPrefix("Stage").Println(stage.Name())
+		event.Emit(event.Event{Type: event.TypeStageStarted, Stage: stage.Name()})
+	}
+	p.OnFinish = func(result pipeline.Result) {
+		printStageResult(result)
+		emitStageResult(result)
+	}
+	return p, nil
+}
+
+// printStageOutput return the func printing a line of output of stage.
+func printStageOutput(stage string) func(line string) {
+	return func(line string) {
+		pterm.Println(line)
+		event.Emit(event.Event{Type: event.TypeLog, Stage: stage, Line: line})
+	}
+}
+
+// skipUnless skip the stage with reason unless cond is true, which is decided by flags before the pipeline runs.
+func skipUnless(cond bool, reason string) func(ctx *contextutil.Context) string {
+	return func(_ *contextutil.Context) string {
+		if cond {
+			return ""
+		}
+		return reason
+	}
+}
+
+// suggestionError is the error of stage, with the suggestion telling how to fix it.
+type suggestionError struct {
+	err error
+
+	// output is the output of subprocess, which helps to find the suggestion
+	output []string
+
+	// hint is the suggestion, found by the error and output if empty
+	hint string
+}
+
+func (e *suggestionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *suggestionError) Unwrap() error {
+	return e.err
+}
+
+// withSuggestion attach the suggestion found by err and the subprocess output to err.
+func withSuggestion(err error, output []string) error {
+	return &suggestionError{err: err, output: output}
+}
+
+// withHint attach the given suggestion to err.
+func withHint(err error, hint string) error {
+	return &suggestionError{err: err, hint: hint}
+}
+
+// printStageResult print the operations planned by stage, or the error of failed stage and the suggestion if any.
+func printStageResult(result pipeline.Result) {
+	for _, operation := range result.Plan {
+		style.InfoPrefix("Plan").Println(operation)
+	}
+	if len(result.Plan) > 0 {
+		pterm.Println()
+	}
+	if result.Status != pipeline.StatusFailed {
+		return
+	}
+	style.PrintError(result.Err)
+	for _, suggestion := range stageSuggestions(result.Err) {
+		style.PrintSuggestion(suggestion)
+	}
+}
+
+// emitStageResult emit the event of finished stage, followed by the error event if the stage fails.
+func emitStageResult(result pipeline.Result) {
+	event.Emit(event.Event{
+		Type:       event.TypeStageFinished,
+		Stage:      result.Stage,
+		Status:     string(result.Status),
+		DurationMs: result.Duration.Milliseconds(),
+		Reason:     result.Reason,
+		Plan:       result.Plan,
+	})
+	if result.Status == pipeline.StatusFailed {
+		event.Emit(event.Event{
+			Type:        event.TypeError,
+			Stage:       result.Stage,
+			Message:     result.Err.Error(),
+			Suggestions: stageSuggestions(result.Err),
+		})
+	}
+}
+
+// stageSuggestions return the suggestions attached to the error of stage, if any.
+func stageSuggestions(err error) []string {
+	suggestion := &suggestionError{}
+	switch {
+	case !errors.As(err, &suggestion):
+		return nil
+	case suggestion.hint != "":
+		return []string{suggestion.hint}
+	default:
+		return suggestionsOf(suggestion.err, suggestion.output)
+	}
+}
+
+// stageError return the error of failed stage with the exit code telling why it fails, or nil if no stage fails.
+func stageError(report *pipeline.Report) error {
+	failed := report.Failed()
+	if failed == nil {
+		return nil
+	}
+
+	code := exitcode.Of(failed.Err)
+	switch {
+	case errors.As(failed.Err, new(*pipeline.MissingInputError)):
+		// the stages providing the input are not selected by --only or --skip
+		code = exitcode.Usage
+	case failed.Stage == stageBuild:
+		code = exitcode.BuildFailed
+	case failed.Stage == stageInstall || failed.Stage == stagePruneDevVersions:
+		code = exitcode.OfTargetError(failed.Err, exitcode.InstallRejected)
+	case failed.Stage == stageUpload || failed.Stage == stageCheckClassVersion || failed.Stage == stageSmokeTest:
+		code = exitcode.OfTargetError(failed.Err, code)
+	}
+	// the error is printed by printStageResult as the stage fails
+	return style.Printed(exitcode.Wrap(code, fmt.Errorf("stage %s failed: %w", failed.Stage, failed.Err)))
+}
+
+// printStageSummary print the status and time of each stage.
+func printStageSummary(report *pipeline.Report) {
+	data := [][]string{{"Stage", "Status", "Time", "Note"}}
+	for _, result := range report.Results {
+		elapsed := ""
+		if result.Status == pipeline.StatusSucceeded || result.Status == pipeline.StatusFailed || result.Status == pipeline.StatusPlanned {
+			elapsed = result.Duration.Round(time.Millisecond).String()
+		}
+		note := result.Reason
+		if result.Err != nil {
+			// the error of build is followed by the whole stderr of build tool
+			note = strings.SplitN(result.Err.Error(), "\n", 2)[0]
+		}
+		data = append(data, []string{result.Stage, string(result.Status), elapsed, note})
+	}
+	data = append(data, []string{"total", "", report.Duration.Round(time.Millisecond).String(), ""})
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	pterm.Println()
+}
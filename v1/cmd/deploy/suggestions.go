@@ -19,29 +19,27 @@ package deploy
 
 import (
 	"strings"
-
-	"github.com/koupleless/arkctl/common/style"
 )
 
 const (
 	faq_url = "https://koupleless.io/en/docs/faq/faq/"
 )
 
-var suggestionFuncs = []func(errorOutputLines []string) bool{
+var suggestionFuncs = []func(errorOutputLines []string, suggest func(string)) bool{
 	suggestionBaseNotStart,
 	suggestionMavenExecutableNotFound,
 	suggestionMavenVersionTooLow,
+	suggestionGradleExecutableNotFound,
+	suggestionGradleWrapperNotExecutable,
+	suggestionGradleBizTaskNotFound,
 	suggestWebContextPathConflict,
 	suggestApplicationProperties,
 	suggestImportSpringBootAutoConfiguration,
 	suggestJvmInitializingFailed,
 }
 
-func printSuggestion(err error) {
-	printSuggestionWithMore(err, nil)
-}
-
-func printSuggestionWithMore(err error, subprocessOutput []string) {
+// suggestionsOf return the suggestions fixing err, the last one is always the faq.
+func suggestionsOf(err error, subprocessOutput []string) []string {
 	var errorOutputLines []string
 	if err != nil {
 		if lines := strings.Split(err.Error(), "\n"); len(lines) > 0 {
@@ -52,16 +50,20 @@ func printSuggestionWithMore(err error, subprocessOutput []string) {
 		errorOutputLines = append(errorOutputLines, subprocessOutput...)
 	}
 
+	var suggestions []string
+	suggest := func(suggestion string) {
+		suggestions = append(suggestions, suggestion)
+	}
 	for _, suggestionFunc := range suggestionFuncs {
-		if suggestionFunc(errorOutputLines) {
+		if suggestionFunc(errorOutputLines, suggest) {
 			break
 		}
 	}
 
-	doPrintSuggestion("you can go to faq for more help at " + faq_url)
+	return append(suggestions, "you can go to faq for more help at "+faq_url)
 }
 
-func suggestionBaseNotStart(errorOutputLines []string) bool {
+func suggestionBaseNotStart(errorOutputLines []string, suggest func(string)) bool {
 	hasBaseNotStart := false
 	for _, line := range errorOutputLines {
 		if strings.HasSuffix(line, "connect: connection refused") {
@@ -72,13 +74,13 @@ func suggestionBaseNotStart(errorOutputLines []string) bool {
 		}
 	}
 	if hasBaseNotStart {
-		doPrintSuggestion("ensure target base is running")
+		suggest("ensure target base is running")
 		return true
 	}
 	return false
 }
 
-func suggestionMavenExecutableNotFound(errorOutputLines []string) bool {
+func suggestionMavenExecutableNotFound(errorOutputLines []string, suggest func(string)) bool {
 	hasMavenExecutableNotFound := false
 	for _, line := range errorOutputLines {
 		if strings.Contains(line, "exec: \"mvn\": executable file not found") {
@@ -87,13 +89,13 @@ func suggestionMavenExecutableNotFound(errorOutputLines []string) bool {
 		}
 	}
 	if hasMavenExecutableNotFound {
-		doPrintSuggestion("install latest maven or just put mvn executable path into your $PATH")
+		suggest("install latest maven or just put mvn executable path into your $PATH")
 		return true
 	}
 	return false
 }
 
-func suggestionMavenVersionTooLow(errorOutputLines []string) bool {
+func suggestionMavenVersionTooLow(errorOutputLines []string, suggest func(string)) bool {
 	hasMavenVersionTooLow := false
 	var featureSubStrings = []string{
 		"Unable to parse configuration of mojo com.alipay.sofa:sofa-ark-maven-plugin",
@@ -110,13 +112,43 @@ func suggestionMavenVersionTooLow(errorOutputLines []string) bool {
 		}
 	}
 	if hasMavenVersionTooLow {
-		doPrintSuggestion("your maven is outdated, update it to 3.6.1 or higher version")
+		suggest("your maven is outdated, update it to 3.6.1 or higher version")
 		return true
 	}
 	return false
 }
 
-func suggestWebContextPathConflict(errorOutputLines []string) bool {
+func suggestionGradleExecutableNotFound(errorOutputLines []string, suggest func(string)) bool {
+	for _, line := range errorOutputLines {
+		if strings.Contains(line, "exec: \"gradle\": executable file not found") {
+			suggest("install latest gradle, or add gradle wrapper to your project with `gradle wrapper`")
+			return true
+		}
+	}
+	return false
+}
+
+func suggestionGradleWrapperNotExecutable(errorOutputLines []string, suggest func(string)) bool {
+	for _, line := range errorOutputLines {
+		if strings.Contains(line, "gradlew") && strings.Contains(line, "permission denied") {
+			suggest("make gradle wrapper executable with `chmod +x gradlew`")
+			return true
+		}
+	}
+	return false
+}
+
+func suggestionGradleBizTaskNotFound(errorOutputLines []string, suggest func(string)) bool {
+	for _, line := range errorOutputLines {
+		if strings.Contains(line, "Task 'bizJar' not found") {
+			suggest("apply the koupleless gradle plugin in your build.gradle to build the biz bundle")
+			return true
+		}
+	}
+	return false
+}
+
+func suggestWebContextPathConflict(errorOutputLines []string, suggest func(string)) bool {
 	hasStartWebServer := false
 	hasChildNameNotUnique := false
 	for _, line := range errorOutputLines {
@@ -131,44 +163,40 @@ func suggestWebContextPathConflict(errorOutputLines []string) bool {
 		}
 	}
 	if hasChildNameNotUnique {
-		doPrintSuggestion("another installed biz module has the same webContextPath as yours")
-		doPrintSuggestion("change your <webContextPath> in pom.xml or uninstall another biz module")
+		suggest("another installed biz module has the same webContextPath as yours")
+		suggest("change your <webContextPath> in pom.xml or uninstall another biz module")
 		return true
 	}
 	return false
 }
 
-func suggestApplicationProperties(errorOutputLines []string) bool {
+func suggestApplicationProperties(errorOutputLines []string, suggest func(string)) bool {
 	for _, line := range errorOutputLines {
 		if strings.Contains(line, "spring.application.name must be configured") {
-			doPrintSuggestion("add \"spring.application.name\" config into your application.properties")
+			suggest("add \"spring.application.name\" config into your application.properties")
 			return true
 		}
 	}
 	return false
 }
 
-func suggestImportSpringBootAutoConfiguration(errorOutputLines []string) bool {
+func suggestImportSpringBootAutoConfiguration(errorOutputLines []string, suggest func(string)) bool {
 	for _, line := range errorOutputLines {
 		if strings.Contains(line, "The following classes could not be excluded because they are not auto-configuration classes") &&
 			strings.Contains(line, "org.springframework.boot.actuate.autoconfigure.startup.StartupEndpointAutoConfiguration") {
-			doPrintSuggestion("import sprign-boot-actuator-autoconfiguration artifact in your pom.xml file")
+			suggest("import sprign-boot-actuator-autoconfiguration artifact in your pom.xml file")
 			return true
 		}
 	}
 	return false
 }
 
-func suggestJvmInitializingFailed(errorOutputLines []string) bool {
+func suggestJvmInitializingFailed(errorOutputLines []string, suggest func(string)) bool {
 	for _, line := range errorOutputLines {
 		if strings.Contains(line, "Error occurred during initialization of VM") {
-			doPrintSuggestion("check your jvm staring paramaters")
+			suggest("check your jvm staring paramaters")
 			return true
 		}
 	}
 	return false
 }
-
-func doPrintSuggestion(line string) {
-	style.InfoPrefix("Suggestion").Printfln("%s", line)
-}
@@ -0,0 +1,156 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/service/build"
+	"github.com/koupleless/arkctl/v1/service/pipeline"
+
+	"github.com/pterm/pterm"
+)
+
+// ctxKeyWatchCycle is the cycle of watch mode which the deploy runs in, absent out of watch mode.
+var ctxKeyWatchCycle = pipeline.Key[*watchCycle]("watch.Cycle")
+
+// watchCycle is one run of the deploy stages triggered by changes.
+type watchCycle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu         sync.Mutex
+	installing bool
+}
+
+// beginInstall mark the cycle installing, after which it's not cancelled by changes so that the base is not
+// left half updated. It returns false if the cycle is cancelled already.
+func (c *watchCycle) beginInstall(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ctx.Err() != nil {
+		return false
+	}
+	c.installing = true
+	return true
+}
+
+// interrupt cancel the cycle for new changes unless it's installing, return false if the cycle is let finish.
+func (c *watchCycle) interrupt() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.installing {
+		return false
+	}
+	c.cancel()
+	return true
+}
+
+// running return true if the cycle is not finished yet.
+func (c *watchCycle) running() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// watchAndDeploy deploy the project once, and then again whenever it is changed, until interrupted.
+// a failed cycle does not stop watching, and the cycle in progress is cancelled once new changes arrive,
+// unless it's installing, which is let finish before the next cycle.
+func watchAndDeploy() error {
+	watcher, err := build.Watch(defaultArg, watchDebounceFlag)
+	if err != nil {
+		return fmt.Errorf("failed to watch the project: %w", err)
+	}
+	defer watcher.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	count := 0
+	start := func(changed []string) *watchCycle {
+		count++
+		cycleCtx, cancel := context.WithCancel(ctx)
+		cycle := &watchCycle{cancel: cancel, done: make(chan struct{})}
+		go func(n int) {
+			defer close(cycle.done)
+			defer cancel()
+			startTime := time.Now()
+			deployCtx := generateContext(cycleCtx)
+			ctxKeyWatchCycle.Put(deployCtx, cycle)
+			err := runDeploy(deployCtx)
+			printWatchStatus(n, changed, err == nil, cycleCtx.Err() != nil, time.Since(startTime))
+		}(count)
+		return cycle
+	}
+
+	style.InfoPrefix("Watch").Printfln("watching %s for changes, press Ctrl+C to stop", defaultArg)
+	cycle := start(nil)
+	for {
+		select {
+		case <-ctx.Done():
+			cycle.cancel()
+			<-cycle.done
+			return nil
+		case err := <-watcher.Errors():
+			pterm.Warning.Printfln("failed to watch the project: %s", err)
+		case changed, ok := <-watcher.Changes():
+			if !ok {
+				return nil
+			}
+			if cycle.running() {
+				if cycle.interrupt() {
+					style.InfoPrefix("Watch").Printfln("%d files changed, cancel the deploy in progress", len(changed))
+				} else {
+					style.InfoPrefix("Watch").Printfln("%d files changed, wait for the install in progress to finish", len(changed))
+				}
+				<-cycle.done
+			}
+			cycle = start(changed)
+		}
+	}
+}
+
+// printWatchStatus print a compact status line of the cycle.
+func printWatchStatus(n int, changed []string, ok, cancelled bool, elapsed time.Duration) {
+	trigger := "initial deploy"
+	switch {
+	case len(changed) == 1:
+		trigger = changed[0] + " changed"
+	case len(changed) > 1:
+		trigger = pterm.Sprintf("%s and %d more changed", changed[0], len(changed)-1)
+	}
+	elapsed = elapsed.Round(100 * time.Millisecond)
+
+	switch {
+	case cancelled:
+		pterm.Warning.Printfln("#%d cancelled after %s, %s", n, elapsed, trigger)
+	case ok:
+		pterm.Success.Printfln("#%d deployed in %s, %s, waiting for changes", n, elapsed, trigger)
+	default:
+		style.PrintErrorf("#%d failed in %s, %s, waiting for changes", n, elapsed, trigger)
+	}
+}
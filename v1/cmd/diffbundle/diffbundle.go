@@ -0,0 +1,218 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diffbundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/service/ark"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFlag    = "text"
+	installedFlag string
+	hostFlag      = "127.0.0.1"
+	portFlag      = 1238
+	podFlag       string
+)
+
+var DiffBundleCommand = &cobra.Command{
+	Use:   "diff-bundle [flags] old.jar new.jar",
+	Short: "show what changed between two biz bundles",
+	Long: `
+The arkctl diff-bundle subcommand compares the manifest attributes, the embedded lib jars added, removed or
+version-changed, and the classes whose bytecode changed between two biz bundles.
+
+With --installed, the old bundle is the one of biz currently installed on the base,
+which is looked up from the deploy records in ~/.arkctl/deploys.jsonl.
+`,
+	Example: `
+Scenario 0: Compare two biz bundles:
+    arkctl diff-bundle foo-1.0.0-ark-biz.jar target/foo-1.0.1-ark-biz.jar
+
+Scenario 1: Compare the biz installed on local base with the release candidate:
+    arkctl diff-bundle --installed foo https://artifacts.example/foo-1.0.1-ark-biz.jar
+
+Scenario 2: Compare the biz installed on the base in k8s cluster with local build in json format:
+    arkctl diff-bundle -o json --installed foo --pod ${namespace}/${name} target/foo-ark-biz.jar
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if installedFlag != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	SilenceUsage: true,
+	RunE:         execDiffBundle,
+}
+
+func execDiffBundle(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var urls []fileutil.FileUrl
+	if installedFlag != "" {
+		installedUrl, err := installedBizUrl(ctx)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, installedUrl)
+	}
+	for _, arg := range args {
+		bizUrl, err := fileutil.ParseFileUrl(arg)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, bizUrl)
+	}
+
+	diff, err := ark.DiffBundle(ctx, urls[0], urls[1])
+	if err != nil {
+		return err
+	}
+
+	switch outputFlag {
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case "text":
+		printBundleDiff(diff)
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %s", outputFlag)
+	}
+}
+
+// installedBizUrl return the bundle url of biz installed on target base, recorded when it's deployed.
+// The installed version of local base is queried, while the latest deploy to the pod is assumed to be installed.
+func installedBizUrl(ctx context.Context) (fileutil.FileUrl, error) {
+	records, err := ark.ReadDeployRecords(ark.DefaultDeployRecordPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to read deploy records: %w", err)
+	}
+
+	if podFlag != "" {
+		podNamespace, podName := "default", podFlag
+		if strings.Contains(podFlag, "/") {
+			podNamespace, podName, _ = strings.Cut(podFlag, "/")
+		}
+		record, found := ark.FindDeployRecord(records, installedFlag, "", "pod "+podNamespace+"/"+podName)
+		if !found {
+			return "", fmt.Errorf("no deploy record of biz %s to pod %s/%s found, give the bundle of installed biz explicitly", installedFlag, podNamespace, podName)
+		}
+		style.InfoPrefix("Installed").Printfln("%s:%s %s", record.BizName, record.BizVersion, record.BizUrl)
+		return record.BizUrl, nil
+	}
+
+	resp, err := ark.BuildService(ctx).QueryAllBiz(ctx, ark.QueryAllArkBizRequest{
+		HostName: hostFlag,
+		Port:     portFlag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query installed biz: %w", err)
+	}
+	// prefer the activated version if several versions are installed
+	var installed *ark.ArkBizInfo
+	for i, bizInfo := range resp.Data {
+		if bizInfo.BizName == installedFlag && (installed == nil || bizInfo.BizState == "ACTIVATED") {
+			installed = &resp.Data[i]
+		}
+	}
+	if installed == nil {
+		return "", fmt.Errorf("biz %s is not installed on %s:%d", installedFlag, hostFlag, portFlag)
+	}
+
+	record, found := ark.FindDeployRecord(records, installed.BizName, installed.BizVersion, "")
+	if !found {
+		return "", fmt.Errorf("no deploy record of biz %s:%s found, give the bundle of installed biz explicitly", installed.BizName, installed.BizVersion)
+	}
+	style.InfoPrefix("Installed").Printfln("%s:%s %s", record.BizName, record.BizVersion, record.BizUrl)
+	return record.BizUrl, nil
+}
+
+func printBundleDiff(diff *ark.BundleDiff) {
+	style.InfoPrefix("Old").Println(string(diff.Old))
+	style.InfoPrefix("New").Println(string(diff.New))
+	if diff.IsEmpty() {
+		pterm.Info.Println(pterm.Green("no difference found!"))
+		return
+	}
+
+	if len(diff.Manifest) > 0 {
+		data := pterm.TableData{{"MANIFEST", "CHANGE", "OLD", "NEW"}}
+		for _, change := range diff.Manifest {
+			data = append(data, []string{change.Name, string(change.Change), change.Old, change.New})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	}
+
+	if len(diff.Libs) > 0 {
+		data := pterm.TableData{{"LIB", "CHANGE", "OLD", "NEW"}}
+		artifacts := map[string]int{}
+		for _, change := range diff.Libs {
+			artifacts[change.Artifact]++
+		}
+		for _, change := range diff.Libs {
+			lib := change.Artifact
+			if artifacts[lib] > 1 {
+				// more than one jar of the artifact, tell them apart by path
+				lib = change.NewFile
+				if lib == "" {
+					lib = change.OldFile
+				}
+			}
+			data = append(data, []string{lib, string(change.Change), change.OldVersion, change.NewVersion})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	}
+
+	if len(diff.Classes) > 0 {
+		data := pterm.TableData{{"CLASS", "CHANGE", "SIZE", "DELTA"}}
+		for _, change := range diff.Classes {
+			class := change.Class
+			if change.Jar != "" {
+				class += " in " + change.Jar
+			}
+			data = append(data, []string{class, string(change.Change),
+				fmt.Sprintf("%d -> %d", change.OldSize, change.NewSize), fmt.Sprintf("%+d", change.SizeDelta)})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	}
+
+	pterm.Info.Printfln("%d manifest attributes, %d libs and %d classes changed",
+		len(diff.Manifest), len(diff.Libs), len(diff.Classes))
+}
+
+func init() {
+	root.RootCmd.AddCommand(DiffBundleCommand)
+
+	DiffBundleCommand.Flags().StringVarP(&outputFlag, "output", "o", outputFlag, "output format, one of text and json")
+	DiffBundleCommand.Flags().StringVar(&installedFlag, "installed", installedFlag, "compare with the bundle of given biz installed on the base")
+	DiffBundleCommand.Flags().StringVar(&hostFlag, "host", hostFlag, "the host of local base with --installed")
+	DiffBundleCommand.Flags().IntVar(&portFlag, "port", portFlag, "the port of local base with --installed")
+	DiffBundleCommand.Flags().StringVar(&podFlag, "pod", podFlag, "the pod of base with --installed, in the format of namespace/name")
+}
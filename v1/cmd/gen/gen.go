@@ -18,7 +18,9 @@
 package gen
 
 import (
+	"errors"
 	"fmt"
+	"github.com/koupleless/arkctl/common/exitcode"
 	"github.com/koupleless/arkctl/v1/cmd/root"
 )
 
@@ -30,22 +32,22 @@ import (
 var newServerlessApp = &cobra.Command{
 	Use:   "newServerlessApp",
 	Short: "new a serverless app project",
-	Run:   createApp,
+	RunE:  createApp,
 }
 
 func init() {
 	root.RootCmd.AddCommand(newServerlessApp)
 }
 
-func createApp(cmd *cobra.Command, args []string) {
+func createApp(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
-		fmt.Println("Please tell me the generate path, like '.' ")
-		return
+		return exitcode.Wrap(exitcode.Usage, errors.New("please tell me the generate path, like '.'"))
 	}
 	path := args[0]
 	if err := generate(path); err != nil {
-		fmt.Printf("generate error: %s\n", err)
+		return fmt.Errorf("generate error: %w", err)
 	}
+	return nil
 }
 
 func generate(path string) error {
@@ -15,10 +15,16 @@
 package cmd
 
 import (
+	_ "github.com/koupleless/arkctl/v1/cmd/cache"
 	_ "github.com/koupleless/arkctl/v1/cmd/create"
 	_ "github.com/koupleless/arkctl/v1/cmd/deploy"
+	_ "github.com/koupleless/arkctl/v1/cmd/diffbundle"
 	_ "github.com/koupleless/arkctl/v1/cmd/gen"
+	_ "github.com/koupleless/arkctl/v1/cmd/inspect"
+	_ "github.com/koupleless/arkctl/v1/cmd/lint"
+	_ "github.com/koupleless/arkctl/v1/cmd/repackage"
 	_ "github.com/koupleless/arkctl/v1/cmd/root"
+	_ "github.com/koupleless/arkctl/v1/cmd/sbom"
 	_ "github.com/koupleless/arkctl/v1/cmd/show"
 	_ "github.com/koupleless/arkctl/v1/cmd/status"
 	_ "github.com/koupleless/arkctl/v1/cmd/undeploy"
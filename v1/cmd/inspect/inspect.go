@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/service/ark"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFlag = "text"
+)
+
+var InspectCommand = &cobra.Command{
+	Use:   "inspect [flags] path/or/url/to/your/bundle.jar",
+	Short: "show the metadata of biz bundle",
+	Long: `
+The arkctl inspect subcommand shows the biz name, version, manifest and embedded libs of your biz bundle.
+Remote bundles are read with http range requests, so only the manifest and the zip directory are downloaded.
+`,
+	Example: `
+Scenario 0: Inspect a local biz bundle:
+    arkctl inspect target/foo-ark-biz.jar
+
+Scenario 1: Inspect a remote biz bundle in json format:
+    arkctl inspect -o json https://artifacts.example/foo-ark-biz.jar
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         execInspect,
+}
+
+func execInspect(cmd *cobra.Command, args []string) error {
+	bizUrl, err := fileutil.ParseFileUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	info, err := ark.InspectBundle(context.Background(), bizUrl)
+	if err != nil {
+		return err
+	}
+
+	switch outputFlag {
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	case "text":
+		printBundleInfo(info)
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %s", outputFlag)
+	}
+}
+
+func printBundleInfo(info *ark.BundleInfo) {
+	style.InfoPrefix("BizName").Println(info.BizName)
+	style.InfoPrefix("BizVersion").Println(info.BizVersion)
+	style.InfoPrefix("Type").Println(string(info.Type))
+	if info.Digest != "" {
+		style.InfoPrefix("Digest").Println(info.Digest)
+	}
+
+	names := make([]string, 0, len(info.Manifest))
+	for name := range info.Manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data := pterm.TableData{{"MANIFEST", "VALUE"}}
+	for _, name := range names {
+		data = append(data, []string{name, info.Manifest[name]})
+	}
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+
+	style.InfoPrefix("Libs").Printfln("%d embedded lib jars", len(info.Libs))
+	for _, lib := range info.Libs {
+		pterm.Println("  " + lib)
+	}
+}
+
+func init() {
+	root.RootCmd.AddCommand(InspectCommand)
+
+	InspectCommand.Flags().StringVarP(&outputFlag, "output", "o", outputFlag, "output format, one of text and json")
+}
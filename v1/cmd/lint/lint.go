@@ -0,0 +1,142 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/config"
+	"github.com/koupleless/arkctl/v1/service/lint"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFlag      = "text"
+	includeLibsFlag = false
+	failOnFlag      = string(lint.SeverityError)
+	sourceRootFlag  = "src/main/java"
+	suppressFlag    []string
+)
+
+var LintCommand = &cobra.Command{
+	Use:   "lint [flags] path/to/your/bundle.jar",
+	Short: "scan the bytecode of biz bundle for koupleless anti-patterns",
+	Long: `
+The arkctl lint subcommand scans the bytecode of your biz bundle, and reports the patterns
+which may leak memory when the biz module is uninstalled, like starting non-daemon threads,
+registering jdbc drivers or shutdown hooks, and keeping static caches in classes shared with base.
+
+Findings can be suppressed in .arkctl.yaml of your project:
+    lint:
+      suppress:
+        - shutdown-hook                              # suppress a rule
+        - static-cache-in-shared-class:com.foo.*     # suppress a rule for given classes
+`,
+	Example: `
+Scenario 0: Lint a biz bundle:
+    arkctl lint target/foo-ark-biz.jar
+
+Scenario 1: Lint a biz bundle including its lib jars, and report in sarif format:
+    arkctl lint --include-libs -o sarif target/foo-ark-biz.jar > arkctl.sarif
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         execLint,
+}
+
+func execLint(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	bizUrl, err := fileutil.ParseFileUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	projectConfig, err := config.LoadProjectConfig(".")
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	report, err := lint.Lint(ctx, bizUrl, lint.Options{
+		IncludeLibs: includeLibsFlag,
+		Suppress:    append(projectConfig.Lint.Suppress, suppressFlag...),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch outputFlag {
+	case "sarif":
+		err = report.WriteSarif(cmd.OutOrStdout(), sourceRootFlag)
+	case "json":
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case "text":
+		printReport(report)
+	default:
+		err = fmt.Errorf("unknown output format %s", outputFlag)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failOn := lint.Severity(failOnFlag); failOn.Rank() > 0 && report.Count(failOn) > 0 {
+		return fmt.Errorf("lint found %d findings at %s level or above", report.Count(failOn), failOn)
+	}
+	return nil
+}
+
+func printReport(report *lint.Report) {
+	for _, finding := range report.Findings {
+		prefix := style.InfoPrefix(finding.RuleID)
+		switch finding.Severity {
+		case lint.SeverityError:
+			prefix = style.ErrorPrefix(finding.RuleID)
+		case lint.SeverityWarning:
+			prefix = style.WarningPrefix(finding.RuleID)
+		}
+		prefix.Printfln("%s\n  at %s", finding.Message, finding.Location)
+	}
+
+	summary := fmt.Sprintf("%d errors, %d warnings, %d suppressed",
+		report.Count(lint.SeverityError),
+		report.Count(lint.SeverityWarning)-report.Count(lint.SeverityError),
+		report.Suppressed)
+	if len(report.Findings) == 0 {
+		pterm.Info.Println(pterm.Green("no anti-pattern found! " + summary))
+		return
+	}
+	pterm.Info.Println(summary)
+}
+
+func init() {
+	root.RootCmd.AddCommand(LintCommand)
+
+	LintCommand.Flags().StringVarP(&outputFlag, "output", "o", outputFlag, "output format, one of text, json and sarif")
+	LintCommand.Flags().BoolVar(&includeLibsFlag, "include-libs", includeLibsFlag, "also lint the lib jars embedded in biz bundle")
+	LintCommand.Flags().StringVar(&failOnFlag, "fail-on", failOnFlag, "fail if any finding at given level or above, one of error, warning, note and none")
+	LintCommand.Flags().StringVar(&sourceRootFlag, "source-root", sourceRootFlag, "the source root which the source files in sarif report are relative to")
+	LintCommand.Flags().StringArrayVar(&suppressFlag, "suppress", suppressFlag, "suppress findings in the format of {ruleId} or {ruleId}:{classNamePattern}")
+}
@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repackage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/style"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/service/ark"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bizNameFlag        string
+	bizVersionFlag     string
+	webContextPathFlag string
+	outputFlag         string
+)
+
+var RepackageCommand = &cobra.Command{
+	Use:   "repackage [flags] path/to/your/bundle.jar",
+	Short: "rewrite the biz name, version or web context path of a pre-built bundle",
+	Long: `
+The arkctl repackage subcommand copies a pre-built biz bundle with its biz name, biz version or web context path
+rewritten in the manifest and the embedded ark config under conf/ark, all other entries are kept byte-identical.
+So you can redeploy the same artifact under a different version or name without a maven rebuild.
+`,
+	Example: `
+Scenario 0: Repackage a bundle with a timestamped dev version:
+    arkctl repackage target/foo-ark-biz.jar --biz-version 1.0.0-dev.20240101 -o target/foo-dev-ark-biz.jar
+
+Scenario 1: Repackage a bundle as another biz for a side-by-side test:
+    arkctl repackage target/foo-ark-biz.jar --biz-name foo2 --web-context-path foo2 -o target/foo2-ark-biz.jar
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         execRepackage,
+}
+
+func execRepackage(cmd *cobra.Command, args []string) error {
+	bizUrl, err := fileutil.ParseFileUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	output := outputFlag
+	if output == "" {
+		bizModel, err := ark.ParseBizModel(context.Background(), bizUrl)
+		if err != nil {
+			return err
+		}
+		name, version := bizModel.BizName, bizModel.BizVersion
+		if bizNameFlag != "" {
+			name = bizNameFlag
+		}
+		if bizVersionFlag != "" {
+			version = bizVersionFlag
+		}
+		output = fmt.Sprintf("%s-%s-ark-biz.jar", name, version)
+	}
+
+	bizModel, err := ark.Repackage(context.Background(), bizUrl, output, ark.RepackageOptions{
+		BizName:        bizNameFlag,
+		BizVersion:     bizVersionFlag,
+		WebContextPath: webContextPathFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	style.InfoPrefix("Output").Println(filepath.Clean(output))
+	style.InfoPrefix("BizName").Println(bizModel.BizName)
+	style.InfoPrefix("BizVersion").Println(bizModel.BizVersion)
+	style.InfoPrefix("BizBundleDigest").Println(bizModel.Digest)
+	pterm.Info.Println(pterm.Green("repackage biz bundle success!"))
+	return nil
+}
+
+func init() {
+	root.RootCmd.AddCommand(RepackageCommand)
+
+	RepackageCommand.Flags().StringVar(&bizNameFlag, "biz-name", "", "the new biz name, kept as it is if not provided")
+	RepackageCommand.Flags().StringVar(&bizVersionFlag, "biz-version", "", "the new biz version, kept as it is if not provided")
+	RepackageCommand.Flags().StringVar(&webContextPathFlag, "web-context-path", "", "the new web context path, kept as it is if not provided")
+	RepackageCommand.Flags().StringVarP(&outputFlag, "output", "o", "", "the path of repackaged bundle, defaults to {bizName}-{bizVersion}-ark-biz.jar in current dir")
+}
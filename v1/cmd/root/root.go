@@ -18,8 +18,13 @@
 package root
 
 import (
+	"errors"
 	"fmt"
 	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/style"
 	"os"
 
 	"github.com/pterm/pterm"
@@ -27,30 +32,94 @@ import (
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile string
+
+	ciFlag      bool
+	noColorFlag bool
+	quietFlag   bool
+)
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
-			cobra.CheckErr(cmd.Help())
-			os.Exit(1)
+	Use:  "arkctl",
+	Args: cobra.NoArgs,
+	// the error is printed once by Execute, with the hint of help instead of the whole usage
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	// the welcome is printed after args are validated, which is when the output is decided
+	PersistentPreRun: func(_ *cobra.Command, _ []string) {
+		if style.CI() {
+			return
 		}
+		brand := pterm.NewStyle(pterm.Italic, pterm.Bold, pterm.FgLightBlue)
+		pterm.DefaultBasicText.
+			Println("Welcome to use " + brand.Sprint("ARKCTL") + " to ease your develop experience!")
+	},
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return exitcode.Wrap(exitcode.Usage, errors.New("no command given"))
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the RootCmd.
+// arkctl exits with the code telling the kind of error, see exitcode.Help
 func Execute() {
-	cobra.CheckErr(RootCmd.Execute())
+	markUsageErrors(RootCmd)
+	cmd, err := RootCmd.ExecuteC()
+
+	done := event.Event{Type: event.TypeDone, ExitCode: int(exitcode.Of(err))}
+	if err != nil {
+		done.Message = err.Error()
+	}
+	event.Emit(done)
+
+	if err != nil {
+		// the errors of failed stages are printed with their suggestions as soon as they fail
+		if !style.IsPrinted(err) {
+			style.PrintError(err)
+		}
+		if exitcode.Of(err) == exitcode.Usage {
+			style.PrintSuggestion(fmt.Sprintf("run '%s --help' for usage", cmd.CommandPath()))
+		}
+		os.Exit(done.ExitCode)
+	}
+}
+
+// markUsageErrors mark the errors of validating args and flags of cmd and its sub commands as usage errors.
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return exitcode.Wrap(exitcode.Usage, validate(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	RootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
+	RootCmd.SetUsageTemplate(RootCmd.UsageTemplate() + exitcode.Help)
+	RootCmd.PersistentFlags().BoolVar(&ciFlag, "ci", false, `
+If Provided, arkctl prints stable plain text without colors or the welcome, and fails instead of prompting.
+It's on by default if CI=true or stdout is not a terminal.
+`)
+	RootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "print without colors, same as NO_COLOR=1")
+	RootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "print nothing but errors")
+	cobra.OnInitialize(initOutputMode, initConfig)
 	contextutil.DisableLogger()
-	style := pterm.NewStyle(pterm.Italic, pterm.Bold, pterm.FgLightBlue)
-	pterm.DefaultBasicText.
-		Println("Welcome to use " + style.Sprint("ARKCTL") + " to ease your develop experience!")
+}
+
+// initOutputMode apply the output mode given by flags or detected from environment before anything is printed.
+func initOutputMode() {
+	style.SetMode(style.Mode{
+		CI:      ciFlag || style.DetectCI(),
+		NoColor: noColorFlag,
+		Quiet:   quietFlag,
+	})
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -73,4 +142,62 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	applyFileUtilConfig()
+}
+
+// applyFileUtilConfig apply the download settings in config file, e.g.
+//
+//	cache:
+//	  maxSize: 2147483648
+//	http:
+//	  headers:
+//	    artifacts.example.com:
+//	      Authorization: Bearer ${token}
+//	maven:
+//	  settings: /path/to/settings.xml
+//	s3:
+//	  endpoint: http://127.0.0.1:9000
+//	  region: us-east-1
+//	  accessKeyId: ${AWS_ACCESS_KEY_ID}
+//	  secretAccessKey: ${AWS_SECRET_ACCESS_KEY}
+//	  pathStyle: true
+//	  prefix: s3://bundles/dev
+func applyFileUtilConfig() {
+	if viper.IsSet("cache.maxSize") {
+		fileutil.DefaultCache().MaxSize = viper.GetInt64("cache.maxSize")
+	}
+	if viper.IsSet("maven.settings") {
+		fileutil.SetMavenSettings(os.ExpandEnv(viper.GetString("maven.settings")))
+	}
+
+	// the s3 config in file overrides the aws environment variables
+	s3Config := fileutil.GetS3Config()
+	for key, value := range map[string]*string{
+		"s3.endpoint":        &s3Config.Endpoint,
+		"s3.region":          &s3Config.Region,
+		"s3.accessKeyId":     &s3Config.AccessKeyId,
+		"s3.secretAccessKey": &s3Config.SecretAccessKey,
+		"s3.sessionToken":    &s3Config.SessionToken,
+	} {
+		if viper.IsSet(key) {
+			*value = os.ExpandEnv(viper.GetString(key))
+		}
+	}
+	if viper.IsSet("s3.pathStyle") {
+		s3Config.PathStyle = viper.GetBool("s3.pathStyle")
+	} else if viper.IsSet("s3.endpoint") {
+		s3Config.PathStyle = true
+	}
+	fileutil.SetS3Config(s3Config)
+
+	for host, headers := range viper.GetStringMap("http.headers") {
+		values, ok := headers.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, value := range values {
+			fileutil.AddHttpHeader(host, name, os.ExpandEnv(fmt.Sprint(value)))
+		}
+	}
 }
@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/v1/cmd/root"
+	"github.com/koupleless/arkctl/v1/service/sbom"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFlag = string(sbom.FormatCycloneDXJson)
+	fileFlag   string
+)
+
+var SbomCommand = &cobra.Command{
+	Use:   "sbom [flags] path/or/url/to/your/bundle.jar",
+	Short: "generate the software bill of materials of biz bundle",
+	Long: `
+The arkctl sbom subcommand generates the software bill of materials of your biz bundle,
+with the biz itself as the root component and the embedded lib jars as its components.
+The lib jars are identified by their META-INF/maven/*/*/pom.properties, or by their hashes if there is none.
+`,
+	Example: `
+Scenario 0: Generate the SBOM of biz bundle in CycloneDX json format:
+    arkctl sbom target/foo-ark-biz.jar > foo.cdx.json
+
+Scenario 1: Generate the SBOM of remote biz bundle in SPDX json format:
+    arkctl sbom -o spdx-json -f foo.spdx.json https://artifacts.example/foo-ark-biz.jar
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         execSbom,
+}
+
+func execSbom(cmd *cobra.Command, args []string) error {
+	bizUrl, err := fileutil.ParseFileUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	bom, err := sbom.Generate(context.Background(), bizUrl)
+	if err != nil {
+		return err
+	}
+
+	if fileFlag == "" {
+		return bom.Write(cmd.OutOrStdout(), sbom.Format(outputFlag))
+	}
+	// never leave a half written file behind
+	buf := &bytes.Buffer{}
+	if err := bom.Write(buf, sbom.Format(outputFlag)); err != nil {
+		return err
+	}
+	return os.WriteFile(fileFlag, buf.Bytes(), 0644)
+}
+
+func init() {
+	root.RootCmd.AddCommand(SbomCommand)
+
+	SbomCommand.Flags().StringVarP(&outputFlag, "output", "o", outputFlag, "output format, one of cyclonedx-json and spdx-json")
+	SbomCommand.Flags().StringVarP(&fileFlag, "file", "f", fileFlag, "write the SBOM to given file instead of stdout")
+}
@@ -28,7 +28,7 @@ var showCmd = &cobra.Command{
 	Use:   "show",
 	Short: "show serverless app status",
 	Long:  ``,
-	Run:   show,
+	RunE:  show,
 }
 
 func init() {
@@ -38,7 +38,8 @@ func init() {
 	showCmd.Flags().String("h", "h", "")
 }
 
-func show(cmd *cobra.Command, _ []string) {
+func show(cmd *cobra.Command, _ []string) error {
 	fmt.Printf("======================\n")
 	fmt.Printf("获取模块状态信息")
+	return nil
 }
@@ -19,9 +19,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/koupleless/arkctl/common/style"
+	"os"
 	"strings"
 
 	"github.com/koupleless/arkctl/common/cmdutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
 	"github.com/koupleless/arkctl/common/runtime"
 	"github.com/koupleless/arkctl/v1/cmd/root"
 	"github.com/koupleless/arkctl/v1/service/ark"
@@ -37,11 +40,16 @@ var (
 	podFlag      string = ""
 	podNamespace string = ""
 	podName      string = ""
+
+	outputFlag string
 )
 
 var (
 	StatusCommand = cobra.Command{
 		Use: "status",
+		Args: func(cmd *cobra.Command, args []string) error {
+			return event.SetOutput(outputFlag, os.Stdout)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if podFlag != "" && strings.Contains(podFlag, "/") {
 				podNamespace, podName = strings.Split(podFlag, "/")[0], strings.Split(podFlag, "/")[1]
@@ -64,6 +72,7 @@ func execStatusLocal(ctx context.Context) error {
 		return err
 	}
 	style.InfoPrefix("QueryAllBiz").Println(string(runtime.MustReturnResult(json.Marshal(*biz))))
+	emitBizStates(biz.Data)
 	return nil
 }
 
@@ -80,7 +89,6 @@ func execStatusKubePod(ctx context.Context) error {
 	)
 
 	if err := kubeQueryCmd.Exec(); err != nil {
-		pterm.Error.PrintOnError(err)
 		return err
 	}
 
@@ -94,21 +102,39 @@ func execStatusKubePod(ctx context.Context) error {
 		if !strings.Contains(stdoutlines.String(), "SUCCESS") {
 			pterm.Println(stderrlines)
 			pterm.Println(stdoutlines)
-			pterm.Error.Println("query all biz failed")
 			return fmt.Errorf("query all biz failed")
 		}
 		style.InfoPrefix("QueryAllBiz").Println(stdoutlines)
+
+		if event.Enabled() {
+			resp := &ark.QueryAllArkBizResponse{}
+			if err := json.Unmarshal([]byte(stdoutlines.String()), resp); err != nil {
+				return fmt.Errorf("invalid response of query all biz: %w", err)
+			}
+			emitBizStates(resp.Data)
+		}
 	}
 	return nil
 }
 
-func execStatus(ctx context.Context) error {
+// emitBizStates emit the state of each installed biz.
+func emitBizStates(bizInfos []ark.ArkBizInfo) {
+	for _, bizInfo := range bizInfos {
+		event.EmitBizState(bizInfo.BizName, bizInfo.BizVersion, bizInfo.BizState)
+	}
+}
+
+func execStatus(ctx context.Context) (err error) {
 	switch {
 	case podFlag != "":
-		return execStatusKubePod(ctx)
+		err = execStatusKubePod(ctx)
 	default:
-		return execStatusLocal(ctx)
+		err = execStatusLocal(ctx)
+	}
+	if err != nil {
+		event.Emit(event.Event{Type: event.TypeError, Message: err.Error()})
 	}
+	return exitcode.Wrap(exitcode.OfTargetError(err, exitcode.Failure), err)
 }
 
 func init() {
@@ -116,4 +142,5 @@ func init() {
 	StatusCommand.Flags().IntVar(&portFlag, "port", portFlag, "ark container's port")
 	StatusCommand.Flags().StringVar(&hostFlag, "host", hostFlag, "ark container's host")
 	StatusCommand.Flags().StringVar(&podFlag, "pod", podFlag, "ark container's running pod")
+	StatusCommand.Flags().StringVar(&outputFlag, "output", event.OutputText, "the output format, text or events")
 }
@@ -16,10 +16,14 @@ package undeploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/event"
+	"github.com/koupleless/arkctl/common/exitcode"
 	"github.com/koupleless/arkctl/common/style"
 	"github.com/koupleless/arkctl/v1/cmd/root"
 	"github.com/koupleless/arkctl/v1/service/ark"
@@ -32,6 +36,7 @@ var (
 	hostFlag          string = "127.0.0.1"
 	portFlag          int
 	bizNameAndVersion string // in the format of bizName:bizVersion
+	outputFlag        string
 )
 
 var (
@@ -43,9 +48,16 @@ var UnDeployCmd = &cobra.Command{
 	Use:   "undeploy [bizName:bizVersion]",
 	Short: "this command can help you uninstall biz in ark container",
 	Args: func(cmd *cobra.Command, args []string) error {
+		if err := event.SetOutput(outputFlag, os.Stdout); err != nil {
+			return err
+		}
 		if len(args) != 0 && strings.Contains(args[len(args)-1], ":") {
 			bizNameAndVersion = args[len(args)-1]
 		}
+		// the biz to uninstall is selected by prompt only if user could answer it
+		if bizNameAndVersion == "" && (event.Enabled() || !style.Interactive()) {
+			return errors.New("bizName:bizVersion is required in CI mode, with --output events or without a terminal")
+		}
 		return nil
 	},
 
@@ -69,20 +81,23 @@ func unInstall(_ *cobra.Command, _ []string) error {
 
 func execUnInstallLocal(ctx *contextutil.Context) error {
 	arkService := ctx.Value(ctxKeyArkService).(ark.Service)
+	bizName, bizVersion, _ := strings.Cut(bizNameAndVersion, ":")
 	style.InfoPrefix("UnInstallBiz").Println(bizNameAndVersion)
+	event.EmitBizState(bizName, bizVersion, event.StateUninstalling)
 	if err := arkService.UnInstallBiz(ctx, ark.UnInstallBizRequest{
 		TargetContainer: ark.ArkContainerRuntimeInfo{
 			RunType: ark.ArkContainerRunTypeLocal,
 			Port:    &portFlag,
 		},
 		BizModel: ark.BizModel{
-			BizName:    strings.Split(bizNameAndVersion, ":")[0],
-			BizVersion: strings.Split(bizNameAndVersion, ":")[1],
+			BizName:    bizName,
+			BizVersion: bizVersion,
 		},
 	}); err != nil {
-		pterm.Error.Printfln("uninstall %s failed: %s", bizNameAndVersion, err)
-		return err
+		event.Emit(event.Event{Type: event.TypeError, Biz: bizNameAndVersion, Message: err.Error()})
+		return exitcode.Wrap(exitcode.OfTargetError(err, exitcode.InstallRejected), fmt.Errorf("uninstall %s failed: %w", bizNameAndVersion, err))
 	}
+	event.EmitBizState(bizName, bizVersion, event.StateUninstalled)
 	pterm.Info.Printfln(pterm.Green(fmt.Sprintf("uninstall %s success", bizNameAndVersion)))
 	return nil
 }
@@ -96,7 +111,7 @@ func execUnInstallLocalWithPrompt(ctx *contextutil.Context) error {
 	})
 
 	if err != nil {
-		return err
+		return exitcode.Wrap(exitcode.OfTargetError(err, exitcode.Failure), err)
 	}
 
 	if err := ark.IsSuccessResponse(&response.GenericArkResponseBase); err != nil {
@@ -130,6 +145,7 @@ func execUnInstallLocalWithPrompt(ctx *contextutil.Context) error {
 
 func init() {
 	UnDeployCmd.Flags().IntVar(&portFlag, "port", 1238, "the port of ark container")
+	UnDeployCmd.Flags().StringVar(&outputFlag, "output", event.OutputText, "the output format, text or events")
 
 	root.RootCmd.AddCommand(UnDeployCmd)
 }
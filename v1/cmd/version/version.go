@@ -32,13 +32,14 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "show version",
 	Long:  ``,
-	Run:   versionFunc,
+	RunE:  versionFunc,
 }
 
 func init() {
 	root.RootCmd.AddCommand(versionCmd)
 }
 
-func versionFunc(cmd *cobra.Command, _ []string) {
+func versionFunc(cmd *cobra.Command, _ []string) error {
 	fmt.Printf("arkctl Version: %s\n", constant.Version)
+	return nil
 }
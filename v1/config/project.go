@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// projectConfigFiles are the names of project config file, which is committed with the project
+// so that teammates share the same settings.
+var projectConfigFiles = []string{".arkctl.yaml", ".arkctl.yml"}
+
+// ProjectConfig is the config of arkctl shared by a project.
+type ProjectConfig struct {
+	// Dir is the directory where the config file is found, empty if there is no config file.
+	Dir string `mapstructure:"-"`
+
+	// Lint is the config of arkctl lint.
+	Lint LintConfig `mapstructure:"lint"`
+
+	// Build is the config of building the project in arkctl deploy.
+	Build BuildConfig `mapstructure:"build"`
+
+	// Stages are the custom stages of arkctl deploy, e.g. tests and lint.
+	Stages []StageConfig `mapstructure:"stages"`
+
+	// Probe is the config of smoke testing the web endpoint of biz after install in arkctl deploy.
+	Probe ProbeConfig `mapstructure:"probe"`
+}
+
+// LintConfig is the config of arkctl lint.
+type LintConfig struct {
+	// Suppress are the suppressed findings, in the format of {ruleId} or {ruleId}:{classNamePattern},
+	// e.g. shutdown-hook or static-cache-in-shared-class:com.foo.cache.*
+	Suppress []string `mapstructure:"suppress"`
+}
+
+// BuildConfig is the config of building the project in arkctl deploy.
+type BuildConfig struct {
+	// Tool is the build tool, one of maven and gradle, detected by the project files if empty.
+	Tool string `mapstructure:"tool"`
+
+	// Profiles are the maven profiles activated.
+	Profiles []string `mapstructure:"profiles"`
+
+	// Args are the extra args passed to build tool, e.g. ["-T", "4"]
+	Args []string `mapstructure:"args"`
+
+	// Offline build without accessing remote repositories.
+	Offline bool `mapstructure:"offline"`
+
+	// Settings is the path of maven settings.xml, relative to the directory of config file.
+	Settings string `mapstructure:"settings"`
+}
+
+// StageConfig is a custom stage of arkctl deploy, which runs a command in the directory of config file.
+type StageConfig struct {
+	// Name is the unique name of stage, which could be used by --skip and --only.
+	Name string `mapstructure:"name"`
+
+	// After is the name of stage it runs after, e.g. build, the stage runs at last if empty.
+	After string `mapstructure:"after"`
+
+	// Command is the command and its args, e.g. ["mvn", "test"]
+	Command []string `mapstructure:"command"`
+}
+
+// ProbeConfig is the config of smoke testing the web endpoint of biz after install in arkctl deploy.
+type ProbeConfig struct {
+	// Paths are the paths probed, relative to the web context path of biz, e.g. /actuator/health
+	Paths []string `mapstructure:"paths"`
+
+	// Port is the web port of base.
+	Port int `mapstructure:"port"`
+
+	// Status are the expected status codes.
+	Status []int `mapstructure:"status"`
+
+	// Timeout is how long a path is probed until it responds with the expected status, e.g. 30s
+	// 0 probes until the path responds, nil if it's not configured.
+	Timeout *time.Duration `mapstructure:"timeout"`
+
+	// UninstallOnFailure uninstall the biz failing the probe, the version deployed before is not reinstalled.
+	UninstallOnFailure bool `mapstructure:"uninstallOnFailure"`
+}
+
+// LoadProjectConfig search the project config file from dir up to the root directory, and load the first one found.
+// An empty config is returned if no config file is found.
+func LoadProjectConfig(dir string) (*ProjectConfig, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range projectConfigFiles {
+			configFile := filepath.Join(dir, name)
+			if _, err := os.Stat(configFile); err == nil {
+				return loadProjectConfigFile(configFile)
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return &ProjectConfig{}, nil
+		}
+		dir = parent
+	}
+}
+
+func loadProjectConfigFile(configFile string) (*ProjectConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	projectConfig := &ProjectConfig{}
+	if err := v.Unmarshal(projectConfig); err != nil {
+		return nil, err
+	}
+	projectConfig.Dir = filepath.Dir(configFile)
+	if settings := projectConfig.Build.Settings; settings != "" && !filepath.IsAbs(settings) {
+		projectConfig.Build.Settings = filepath.Join(projectConfig.Dir, settings)
+	}
+	return projectConfig, nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProjectConfig(t *testing.T) {
+	projectDir := t.TempDir()
+	subDir := filepath.Join(projectDir, "sub", "module")
+	assert.Nil(t, os.MkdirAll(subDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(projectDir, ".arkctl.yaml"), []byte(`
+lint:
+  suppress:
+    - shutdown-hook
+    - static-cache-in-shared-class:com.foo.*
+build:
+  tool: maven
+  profiles: [dev]
+  args: ["-T", "4"]
+  offline: true
+  settings: .mvn/settings.xml
+stages:
+  - name: test
+    after: build
+    command: [mvn, test]
+probe:
+  paths: [/actuator/health]
+  port: 8081
+  status: [200, 204]
+  timeout: 45s
+  uninstallOnFailure: true
+`), 0644))
+
+	projectConfig, err := LoadProjectConfig(subDir)
+	assert.Nil(t, err)
+	assert.Equal(t, projectDir, projectConfig.Dir)
+	assert.Equal(t, []string{"shutdown-hook", "static-cache-in-shared-class:com.foo.*"}, projectConfig.Lint.Suppress)
+	assert.Equal(t, BuildConfig{
+		Tool:     "maven",
+		Profiles: []string{"dev"},
+		Args:     []string{"-T", "4"},
+		Offline:  true,
+		Settings: filepath.Join(projectDir, ".mvn", "settings.xml"),
+	}, projectConfig.Build)
+	assert.Equal(t, []StageConfig{{Name: "test", After: "build", Command: []string{"mvn", "test"}}}, projectConfig.Stages)
+	timeout := 45 * time.Second
+	assert.Equal(t, ProbeConfig{
+		Paths:              []string{"/actuator/health"},
+		Port:               8081,
+		Status:             []int{200, 204},
+		Timeout:            &timeout,
+		UninstallOnFailure: true,
+	}, projectConfig.Probe)
+}
+
+func TestLoadProjectConfig_ProbeTimeout(t *testing.T) {
+	projectDir := t.TempDir()
+	configFile := filepath.Join(projectDir, ".arkctl.yaml")
+
+	// the timeout not configured is told apart from 0, which probes until the path responds
+	assert.Nil(t, os.WriteFile(configFile, []byte("probe:\n  paths: [/health]\n"), 0644))
+	projectConfig, err := LoadProjectConfig(projectDir)
+	assert.Nil(t, err)
+	assert.Nil(t, projectConfig.Probe.Timeout)
+
+	assert.Nil(t, os.WriteFile(configFile, []byte("probe:\n  paths: [/health]\n  timeout: 0\n"), 0644))
+	projectConfig, err = LoadProjectConfig(projectDir)
+	assert.Nil(t, err)
+	if assert.NotNil(t, projectConfig.Probe.Timeout) {
+		assert.Equal(t, time.Duration(0), *projectConfig.Probe.Timeout)
+	}
+}
+
+func TestLoadProjectConfig_NotFound(t *testing.T) {
+	projectConfig, err := LoadProjectConfig(t.TempDir())
+	assert.Nil(t, err)
+	assert.Equal(t, &ProjectConfig{}, projectConfig)
+}
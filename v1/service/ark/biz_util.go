@@ -15,13 +15,7 @@
 package ark
 
 import (
-	"archive/zip"
 	"context"
-	"errors"
-	"github.com/koupleless/arkctl/common/osutil"
-	"github.com/koupleless/arkctl/common/runtime"
-	"io"
-	"os"
 	"strings"
 
 	"github.com/koupleless/arkctl/common/fileutil"
@@ -33,55 +27,30 @@ func isJarFile(fileUrl fileutil.FileUrl) bool {
 	return strings.HasSuffix(string(fileUrl), ".jar")
 }
 
-// parseJarBizModel parse jar file to BizModel.
-func parseJarBizModel(ctx context.Context, bizUrl fileutil.FileUrl) (model *BizModel, err error) {
-	defer runtime.RecoverFromError(&err)
-	fileUtil := fileutil.DefaultFileUtil()
-	localPath := runtime.MustReturnResult(fileUtil.Download(ctx, bizUrl))
-	readerJar, err := zip.OpenReader(localPath[len(osutil.GetLocalFileProtocol()):])
-	if errors.Is(err, os.ErrNotExist) {
+// ParseBizModel parse biz bundle given by bizUrl to BizModel.
+// The bizUrl could point to a jar, a zip archive or an exploded biz directory.
+// Remote bundles are read with range requests instead of being downloaded, and their digests are left empty.
+func ParseBizModel(ctx context.Context, bizUrl fileutil.FileUrl) (*BizModel, error) {
+	bundle, err := openBundleLazily(ctx, bizUrl)
+	if err != nil {
 		return nil, err
 	}
-	zipReader := runtime.MustReturnResult(readerJar, err)
-	defer zipReader.Close()
-
-	bizName := ""
-	bizVersion := ""
-
-	for _, fileInfo := range zipReader.File {
-		if fileInfo.Name == "META-INF/MANIFEST.MF" {
-			// open this file
-			file := runtime.MustReturnResult(fileInfo.Open())
+	defer bundle.Close()
 
-			// read all to string
-			buf := make([]byte, fileInfo.UncompressedSize64)
-			_, err = file.Read(buf)
-			runtime.Assert(err == nil || err == io.EOF, "failed to read file %s", fileInfo.Name)
-			// find bizName and bizVersion
-			for _, line := range strings.Split(string(buf), "\n") {
-				// if line contains "Ark-Biz-Name:" then it's bizName
-				if strings.Contains(line, "Ark-Biz-Name:") {
-					bizName = strings.TrimSpace(strings.Split(line, ":")[1])
-				}
-
-				// if line contains "Ark-Biz-Version:" then it's bizVersion
-				if strings.Contains(line, "Ark-Biz-Version:") {
-					bizVersion = strings.TrimSpace(strings.Split(line, ":")[1])
-				}
-			}
-			break
-		}
+	manifest, err := bundle.Manifest()
+	if err != nil {
+		return nil, err
 	}
 
-	return &BizModel{
-		BizName:    bizName,
-		BizVersion: bizVersion,
+	model := &BizModel{
+		BizName:    manifest["Ark-Biz-Name"],
+		BizVersion: manifest["Ark-Biz-Version"],
 		BizUrl:     bizUrl,
-	}, nil
-}
-
-// ParseBizModel parse biz bundle given by bizUrl to BizModel.
-func ParseBizModel(ctx context.Context, bizUrl fileutil.FileUrl) (*BizModel, error) {
-	runtime.Assert(isJarFile(bizUrl), "unknown biz bundle type %s", bizUrl)
-	return parseJarBizModel(ctx, bizUrl)
+	}
+	if bundle.Path != "" {
+		if model.Digest, err = bundle.Digest(); err != nil {
+			return nil, err
+		}
+	}
+	return model, nil
 }
@@ -0,0 +1,246 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+)
+
+const (
+	// ManifestPath is the path of manifest file in biz bundle.
+	ManifestPath = "META-INF/MANIFEST.MF"
+)
+
+// zipMagics are the leading bytes of zip archives, the latter one is an empty archive.
+var zipMagics = [][]byte{
+	[]byte("PK\x03\x04"),
+	[]byte("PK\x05\x06"),
+}
+
+type BundleType string
+
+const (
+	BundleTypeJar       BundleType = "jar"
+	BundleTypeZip       BundleType = "zip"
+	BundleTypeDirectory BundleType = "directory"
+)
+
+// Bundle is an opened biz bundle, which could be a jar, a zip archive or an exploded directory.
+// The files in bundle are accessed with slash separated paths like META-INF/MANIFEST.MF through fs.FS.
+type Bundle struct {
+	fs.FS
+
+	// Type is the type of bundle.
+	Type BundleType
+
+	// Path is the local path of bundle, empty if the bundle is read remotely.
+	Path string
+
+	closer io.Closer
+}
+
+// Close release the resources held by bundle.
+func (b *Bundle) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// Manifest return the main attributes of manifest in bundle.
+func (b *Bundle) Manifest() (Manifest, error) {
+	content, err := fs.ReadFile(b.FS, ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(content), nil
+}
+
+// OpenBundle download the biz bundle given by bizUrl and open it.
+func OpenBundle(ctx context.Context, bizUrl fileutil.FileUrl) (*Bundle, error) {
+	localPath, err := fileutil.DefaultFileUtil().Download(ctx, bizUrl)
+	if err != nil {
+		return nil, err
+	}
+	return OpenLocalBundle(strings.TrimPrefix(localPath, osutil.GetLocalFileProtocol()))
+}
+
+// OpenRemoteBundle open the biz bundle given by bizUrl without downloading it if possible,
+// the files in bundle are read with range requests on demand. It's suitable for reading a few files like the manifest,
+// use OpenBundle to read the whole bundle.
+func OpenRemoteBundle(ctx context.Context, bizUrl fileutil.FileUrl) (*Bundle, error) {
+	file, err := fileutil.DefaultFileUtil().Open(ctx, bizUrl)
+	if err != nil {
+		return nil, err
+	}
+	zipReader, err := zip.NewReader(file, file.Size())
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s is not a biz bundle: %w", bizUrl, err)
+	}
+
+	rawUrl, _, _ := strings.Cut(string(bizUrl), "#")
+	bundle := &Bundle{
+		FS:     zipReader,
+		Type:   BundleTypeZip,
+		closer: file,
+	}
+	if isJarFile(fileutil.FileUrl(rawUrl)) {
+		bundle.Type = BundleTypeJar
+	}
+	if _, err := fs.Stat(bundle, ManifestPath); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s is not a biz bundle: %s not found", bizUrl, ManifestPath)
+	}
+	return bundle, nil
+}
+
+// openBundleLazily open remote bundles with OpenRemoteBundle and local ones with OpenBundle.
+func openBundleLazily(ctx context.Context, bizUrl fileutil.FileUrl) (*Bundle, error) {
+	if bizUrl.IsRemote() {
+		return OpenRemoteBundle(ctx, bizUrl)
+	}
+	return OpenBundle(ctx, bizUrl)
+}
+
+// OpenLocalBundle open the biz bundle at local path.
+// The type of bundle is detected by its content instead of its file name.
+func OpenLocalBundle(localPath string) (*Bundle, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		bundle := &Bundle{
+			FS:   os.DirFS(localPath),
+			Type: BundleTypeDirectory,
+			Path: localPath,
+		}
+		if _, err := fs.Stat(bundle, ManifestPath); err != nil {
+			return nil, fmt.Errorf("%s is not a biz bundle: %s not found", localPath, ManifestPath)
+		}
+		return bundle, nil
+	}
+
+	if !isZipFile(localPath) {
+		return nil, fmt.Errorf("%s is not a biz bundle: unknown file format", localPath)
+	}
+
+	zipReader, err := zip.OpenReader(localPath)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		FS:     zipReader,
+		Type:   BundleTypeZip,
+		Path:   localPath,
+		closer: zipReader,
+	}
+	if isJarFile(fileutil.FileUrl(localPath)) {
+		bundle.Type = BundleTypeJar
+	}
+	if _, err := fs.Stat(bundle, ManifestPath); err != nil {
+		_ = zipReader.Close()
+		return nil, fmt.Errorf("%s is not a biz bundle: %s not found", localPath, ManifestPath)
+	}
+	return bundle, nil
+}
+
+// IsBizBundle return true if the local path is a biz bundle,
+// i.e. a zip archive or a directory with manifest.
+func IsBizBundle(localPath string) bool {
+	return CheckBizBundle(localPath) == nil
+}
+
+// CheckBizBundle return the error telling why the local path is not a biz bundle, or nil if it is.
+func CheckBizBundle(localPath string) error {
+	bundle, err := OpenLocalBundle(localPath)
+	if err != nil {
+		return err
+	}
+	return bundle.Close()
+}
+
+func isZipFile(localPath string) bool {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	for _, magic := range zipMagics {
+		if bytes.Equal(header, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenNestedJar open the jar embedded in bundle, like lib/foo.jar.
+func OpenNestedJar(fsys fs.FS, name string) (*zip.Reader, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(content), int64(len(content)))
+}
+
+// Manifest is the main attributes of jar manifest.
+type Manifest map[string]string
+
+// ParseManifest parse the main section of jar manifest.
+// see https://docs.oracle.com/en/java/javase/17/docs/specs/jar/jar.html#jar-manifest
+func ParseManifest(content []byte) Manifest {
+	manifest := Manifest{}
+	lastKey := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case line == "":
+			// main section ends at the first empty line
+			if len(manifest) > 0 {
+				return manifest
+			}
+		case strings.HasPrefix(line, " "):
+			// continuation of last attribute
+			if lastKey != "" {
+				manifest[lastKey] += line[1:]
+			}
+		default:
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			lastKey = strings.TrimSpace(key)
+			manifest[lastKey] = strings.TrimSpace(value)
+		}
+	}
+	return manifest
+}
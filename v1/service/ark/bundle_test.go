@@ -0,0 +1,101 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest := ParseManifest([]byte("Manifest-Version: 1.0\r\n" +
+		"Ark-Biz-Name: biz\r\n" +
+		"Ark-Biz-Version: 1.0.0\r\n" +
+		"Main-Class: com.alipay.sofa.web.biz1.Biz1Applicati\r\n" +
+		" on\r\n" +
+		"\r\n" +
+		"Name: com/foo/\r\n" +
+		"Ark-Biz-Name: another\r\n"))
+
+	assert.Equal(t, Manifest{
+		"Manifest-Version": "1.0",
+		"Ark-Biz-Name":     "biz",
+		"Ark-Biz-Version":  "1.0.0",
+		"Main-Class":       "com.alipay.sofa.web.biz1.Biz1Application",
+	}, manifest)
+}
+
+func TestParseBizModel_ExplodedDirectory(t *testing.T) {
+	bizDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(bizDir, "META-INF"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(bizDir, ManifestPath), []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"), 0644))
+	assert.True(t, IsBizBundle(bizDir))
+
+	bizUrl := fileutil.FileUrl(osutil.GetLocalFileProtocol() + bizDir)
+	model, err := ParseBizModel(context.Background(), bizUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, "biz", model.BizName)
+	assert.Equal(t, "1.0.0", model.BizVersion)
+	assert.Equal(t, bizUrl, model.BizUrl)
+	assert.True(t, strings.HasPrefix(model.Digest, "sha256:"))
+
+	bundle, err := OpenBundle(context.Background(), bizUrl)
+	assert.Nil(t, err)
+	defer bundle.Close()
+	assert.Equal(t, BundleTypeDirectory, bundle.Type)
+}
+
+func TestParseBizModel_ZipWithoutJarExtension(t *testing.T) {
+	bizPath := filepath.Join(t.TempDir(), "biz.zip")
+	assert.Nil(t, os.WriteFile(bizPath, ziptest.Zip(t, map[string][]byte{
+		ManifestPath: []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+	}), 0644))
+	assert.True(t, IsBizBundle(bizPath))
+
+	bundle, err := OpenLocalBundle(bizPath)
+	assert.Nil(t, err)
+	defer bundle.Close()
+	assert.Equal(t, BundleTypeZip, bundle.Type)
+
+	model, err := ParseBizModel(context.Background(), fileutil.FileUrl(osutil.GetLocalFileProtocol()+bizPath))
+	assert.Nil(t, err)
+	assert.Equal(t, "biz", model.BizName)
+}
+
+func TestIsBizBundle_NotBundle(t *testing.T) {
+	projectDir := t.TempDir()
+	assert.False(t, IsBizBundle(projectDir))
+
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	assert.Nil(t, os.WriteFile(pomPath, []byte("<project/>"), 0644))
+	assert.False(t, IsBizBundle(pomPath))
+
+	// a zip archive without manifest is not a biz bundle
+	zipPath := filepath.Join(projectDir, "foo.jar")
+	assert.Nil(t, os.WriteFile(zipPath, ziptest.Zip(t, map[string][]byte{"foo.txt": []byte("foo")}), 0644))
+	assert.False(t, IsBizBundle(zipPath))
+
+	assert.EqualError(t, CheckBizBundle(pomPath), pomPath+" is not a biz bundle: unknown file format")
+	assert.EqualError(t, CheckBizBundle(zipPath), zipPath+" is not a biz bundle: META-INF/MANIFEST.MF not found")
+	assert.ErrorIs(t, CheckBizBundle(filepath.Join(projectDir, "missing.jar")), os.ErrNotExist)
+}
@@ -0,0 +1,163 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/classfile"
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/runtime"
+)
+
+// ParseJavaRelease parse the java version reported by jvm to java feature release.
+// e.g. "1.8.0_291" is 8, "11.0.2" is 11, "17" is 17.
+func ParseJavaRelease(javaVersion string) (int, error) {
+	version := strings.TrimSpace(javaVersion)
+	version = strings.TrimPrefix(version, "1.")
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if end >= 0 {
+		version = version[:end]
+	}
+
+	release, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, fmt.Errorf("unknown java version %q", javaVersion)
+	}
+	return release, nil
+}
+
+// ClassVersionViolation is a jar in biz bundle which contains classes compiled for a newer java release.
+type ClassVersionViolation struct {
+	// Location is the jar containing the classes, e.g. lib/foo.jar or the file name of biz bundle itself.
+	Location string
+
+	// ClassName is one of the offending classes.
+	ClassName string
+
+	// JavaRelease is the java release required by the offending classes.
+	JavaRelease int
+}
+
+// IncompatibleClassVersionError means the biz bundle can not be loaded by the jvm of target base.
+type IncompatibleClassVersionError struct {
+	// JavaRelease is the java feature release of target base.
+	JavaRelease int
+
+	// Violations are the jars which can not be loaded.
+	Violations []ClassVersionViolation
+}
+
+func (e *IncompatibleClassVersionError) Error() string {
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("biz bundle requires a newer java release than target base (java %d):", e.JavaRelease))
+	for _, violation := range e.Violations {
+		sb.WriteString(fmt.Sprintf("\n  %s requires java %d (e.g. %s)", violation.Location, violation.JavaRelease, violation.ClassName))
+	}
+	return sb.String()
+}
+
+// CheckClassVersion scan the class files in biz bundle given by bizUrl,
+// and return IncompatibleClassVersionError if any of them can not be loaded by given java version.
+func CheckClassVersion(ctx context.Context, bizUrl fileutil.FileUrl, javaVersion string) (err error) {
+	defer runtime.RecoverFromError(&err)()
+	javaRelease := runtime.MustReturnResult(ParseJavaRelease(javaVersion))
+
+	bundle := runtime.MustReturnResult(OpenBundle(ctx, bizUrl))
+	defer bundle.Close()
+
+	versions := map[string]*ClassVersionViolation{}
+	runtime.Must(scanClassVersion(bundle, filepath.Base(bundle.Path), versions))
+
+	violations := make([]ClassVersionViolation, 0)
+	for _, violation := range versions {
+		if violation.JavaRelease > javaRelease {
+			violations = append(violations, *violation)
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Location < violations[j].Location
+	})
+	return &IncompatibleClassVersionError{
+		JavaRelease: javaRelease,
+		Violations:  violations,
+	}
+}
+
+// scanClassVersion record the highest java release required by each jar into versions.
+// nested jars like lib/*.jar are scanned recursively.
+func scanClassVersion(fsys fs.FS, location string, versions map[string]*ClassVersionViolation) error {
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+
+		case entry.IsDir() || isVersionInsensitiveClass(name):
+			return nil
+
+		case strings.HasSuffix(name, ".class"):
+			version, err := readClassVersion(fsys, name)
+			if err != nil {
+				return fmt.Errorf("failed to read %s in %s: %w", name, location, err)
+			}
+			recorded, ok := versions[location]
+			if !ok || version.JavaRelease() > recorded.JavaRelease {
+				versions[location] = &ClassVersionViolation{
+					Location:    location,
+					ClassName:   strings.ReplaceAll(strings.TrimSuffix(name, ".class"), "/", "."),
+					JavaRelease: version.JavaRelease(),
+				}
+			}
+
+		case strings.HasSuffix(name, ".jar"):
+			nested, err := OpenNestedJar(fsys, name)
+			if err != nil {
+				return fmt.Errorf("failed to open %s in %s: %w", name, location, err)
+			}
+			return scanClassVersion(nested, name, versions)
+		}
+		return nil
+	})
+}
+
+// isVersionInsensitiveClass return true if the class is never loaded by jvm of older release.
+// multi-release classes are picked by jvm according to its own release,
+// and module-info.class is ignored by java 8.
+func isVersionInsensitiveClass(name string) bool {
+	return strings.HasPrefix(name, "META-INF/versions/") ||
+		path.Base(name) == "module-info.class"
+}
+
+func readClassVersion(fsys fs.FS, name string) (classfile.Version, error) {
+	reader, err := fsys.Open(name)
+	if err != nil {
+		return classfile.Version{}, err
+	}
+	defer reader.Close()
+	return classfile.ReadVersion(reader)
+}
@@ -0,0 +1,81 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockClass(major uint16) []byte {
+	return []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, byte(major >> 8), byte(major)}
+}
+
+func mockBizJar(t *testing.T) fileutil.FileUrl {
+	lib := ziptest.Zip(t, map[string][]byte{
+		"com/foo/Foo.class":                      mockClass(61),
+		"META-INF/versions/21/com/foo/Foo.class": mockClass(65),
+	})
+	return ziptest.BizJar(t, "biz-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":    []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+		"com/biz/Biz.class":       mockClass(52),
+		"module-info.class":       mockClass(53),
+		"lib/foo-1.0.0.jar":       lib,
+		"lib/bar-1.0.0.jar":       ziptest.Zip(t, map[string][]byte{"com/bar/Bar.class": mockClass(50)}),
+		"com/biz/application.yml": []byte("foo: bar"),
+	})
+}
+
+func TestParseJavaRelease(t *testing.T) {
+	for javaVersion, expected := range map[string]int{
+		"1.8.0_291": 8,
+		"11.0.2":    11,
+		"17":        17,
+		"21-ea":     21,
+		"17.0.1+12": 17,
+	} {
+		release, err := ParseJavaRelease(javaVersion)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, release, javaVersion)
+	}
+
+	_, err := ParseJavaRelease("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestCheckClassVersion_Compatible(t *testing.T) {
+	assert.Nil(t, CheckClassVersion(context.Background(), mockBizJar(t), "17.0.2"))
+}
+
+func TestCheckClassVersion_Incompatible(t *testing.T) {
+	err := CheckClassVersion(context.Background(), mockBizJar(t), "1.8.0_291")
+	assert.NotNil(t, err)
+
+	incompatible, ok := err.(*IncompatibleClassVersionError)
+	assert.True(t, ok)
+	assert.Equal(t, 8, incompatible.JavaRelease)
+	assert.Equal(t, []ClassVersionViolation{
+		{
+			Location:    "lib/foo-1.0.0.jar",
+			ClassName:   "com.foo.Foo",
+			JavaRelease: 17,
+		},
+	}, incompatible.Violations)
+	assert.Contains(t, err.Error(), "lib/foo-1.0.0.jar requires java 17")
+}
@@ -0,0 +1,76 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	devVersionTimeFormat = "20060102150405"
+)
+
+// devVersionPattern matches the dev versions like 1.0.0-dev.20240101120000.abc1234
+var devVersionPattern = regexp.MustCompile(`^(.*)-dev\.(\d{14})(?:\.([0-9a-f]+))?$`)
+
+// DevVersion stamp the version with time and git sha, e.g. 1.0.0-dev.20240101120000.abc1234
+// The git sha is omitted if empty, and the existing dev stamp of version is replaced.
+func DevVersion(version string, now time.Time, gitSha string) string {
+	stamped := BaseVersion(version) + "-dev." + now.Format(devVersionTimeFormat)
+	if gitSha != "" {
+		stamped += "." + gitSha
+	}
+	return stamped
+}
+
+// BaseVersion return the version without dev stamp.
+func BaseVersion(version string) string {
+	if match := devVersionPattern.FindStringSubmatch(version); match != nil {
+		return match[1]
+	}
+	return version
+}
+
+// IsDevVersion return true if the version is stamped by DevVersion.
+func IsDevVersion(version string) bool {
+	return devVersionPattern.MatchString(version)
+}
+
+// StaleDevVersions return the dev versions of biz to uninstall, so that only the latest keep ones are left.
+// The current version is always kept, and nothing is returned if keep is not positive.
+func StaleDevVersions(bizInfos []ArkBizInfo, bizName, currentVersion string, keep int) []ArkBizInfo {
+	if keep <= 0 {
+		return nil
+	}
+
+	var devVersions []ArkBizInfo
+	for _, bizInfo := range bizInfos {
+		if bizInfo.BizName == bizName && bizInfo.BizVersion != currentVersion && IsDevVersion(bizInfo.BizVersion) {
+			devVersions = append(devVersions, bizInfo)
+		}
+	}
+
+	// the latest stamp first
+	sort.Slice(devVersions, func(i, j int) bool {
+		return devVersionPattern.FindStringSubmatch(devVersions[i].BizVersion)[2] >
+			devVersionPattern.FindStringSubmatch(devVersions[j].BizVersion)[2]
+	})
+	if len(devVersions) < keep {
+		return nil
+	}
+	return devVersions[keep-1:]
+}
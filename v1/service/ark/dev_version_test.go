@@ -0,0 +1,55 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevVersion(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "1.0.0-dev.20240101120000.abc1234", DevVersion("1.0.0", now, "abc1234"))
+	assert.Equal(t, "1.0.0-SNAPSHOT-dev.20240101120000", DevVersion("1.0.0-SNAPSHOT", now, ""))
+	assert.Equal(t, "1.0.0-dev.20240101120000", DevVersion("1.0.0-dev.20231231000000.abc1234", now, ""))
+
+	assert.True(t, IsDevVersion("1.0.0-dev.20240101120000"))
+	assert.False(t, IsDevVersion("1.0.0-dev.1"))
+	assert.Equal(t, "1.0.0", BaseVersion("1.0.0-dev.20240101120000.abc1234"))
+	assert.Equal(t, "1.0.0", BaseVersion("1.0.0"))
+}
+
+func TestStaleDevVersions(t *testing.T) {
+	bizInfos := []ArkBizInfo{
+		{BizName: "biz", BizVersion: "1.0.0"},
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101100000.abc"},
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101120000.abc"},
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101110000.abc"},
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101130000.abc"},
+		{BizName: "another", BizVersion: "1.0.0-dev.20240101000000.abc"},
+	}
+
+	stale := StaleDevVersions(bizInfos, "biz", "1.0.0-dev.20240101130000.abc", 2)
+	assert.Equal(t, []ArkBizInfo{
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101110000.abc"},
+		{BizName: "biz", BizVersion: "1.0.0-dev.20240101100000.abc"},
+	}, stale)
+
+	assert.Nil(t, StaleDevVersions(bizInfos, "biz", "1.0.0-dev.20240101130000.abc", 4))
+	assert.Nil(t, StaleDevVersions(bizInfos, "biz", "1.0.0-dev.20240101130000.abc", 0))
+	assert.Equal(t, 3, len(StaleDevVersions(bizInfos, "biz", "1.0.0-dev.20240101130000.abc", 1)))
+}
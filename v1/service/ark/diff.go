@@ -0,0 +1,290 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/runtime"
+)
+
+// ChangeType is how an item is changed between bundles.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+
+	// ChangeVersionChanged means the lib jar of same artifact is upgraded or downgraded.
+	ChangeVersionChanged ChangeType = "version-changed"
+)
+
+// AttributeChange is a changed manifest attribute.
+type AttributeChange struct {
+	Name   string     `json:"name"`
+	Change ChangeType `json:"change"`
+	Old    string     `json:"old,omitempty"`
+	New    string     `json:"new,omitempty"`
+}
+
+// LibChange is a changed lib jar embedded in bundle.
+type LibChange struct {
+	// Artifact is the artifact id guessed from the file name of jar.
+	Artifact   string     `json:"artifact"`
+	Change     ChangeType `json:"change"`
+	OldFile    string     `json:"oldFile,omitempty"`
+	NewFile    string     `json:"newFile,omitempty"`
+	OldVersion string     `json:"oldVersion,omitempty"`
+	NewVersion string     `json:"newVersion,omitempty"`
+}
+
+// ClassChange is a class whose bytecode is changed.
+type ClassChange struct {
+	// Jar is the lib jar containing the class, empty if the class belongs to biz itself.
+	// Only the classes of lib jars modified without version change, like snapshots, are compared.
+	Jar       string     `json:"jar,omitempty"`
+	Class     string     `json:"class"`
+	Change    ChangeType `json:"change"`
+	OldSize   int64      `json:"oldSize"`
+	NewSize   int64      `json:"newSize"`
+	SizeDelta int64      `json:"sizeDelta"`
+}
+
+// BundleDiff is the difference between two biz bundles.
+type BundleDiff struct {
+	Old      fileutil.FileUrl  `json:"old"`
+	New      fileutil.FileUrl  `json:"new"`
+	Manifest []AttributeChange `json:"manifest"`
+	Libs     []LibChange       `json:"libs"`
+	Classes  []ClassChange     `json:"classes"`
+}
+
+// IsEmpty return true if nothing is changed.
+func (d *BundleDiff) IsEmpty() bool {
+	return len(d.Manifest) == 0 && len(d.Libs) == 0 && len(d.Classes) == 0
+}
+
+// bundleEntry is a file in bundle.
+type bundleEntry struct {
+	size   int64
+	digest [sha256.Size]byte
+}
+
+// DiffBundle compare the manifest, lib jars and classes of the biz bundles given by oldUrl and newUrl.
+func DiffBundle(ctx context.Context, oldUrl, newUrl fileutil.FileUrl) (diff *BundleDiff, err error) {
+	defer runtime.RecoverFromError(&err)()
+
+	oldBundle := runtime.MustReturnResult(OpenBundle(ctx, oldUrl))
+	defer oldBundle.Close()
+	newBundle := runtime.MustReturnResult(OpenBundle(ctx, newUrl))
+	defer newBundle.Close()
+
+	diff = &BundleDiff{
+		Old:      oldUrl,
+		New:      newUrl,
+		Manifest: diffManifest(runtime.MustReturnResult(oldBundle.Manifest()), runtime.MustReturnResult(newBundle.Manifest())),
+		Libs:     []LibChange{},
+		Classes:  []ClassChange{},
+	}
+
+	oldEntries := runtime.MustReturnResult(readBundleEntries(oldBundle))
+	newEntries := runtime.MustReturnResult(readBundleEntries(newBundle))
+	diff.Classes = append(diff.Classes, diffClasses("", oldEntries, newEntries)...)
+
+	oldLibs, newLibs := libsByArtifact(oldEntries), libsByArtifact(newEntries)
+	for _, artifact := range sortedKeys(oldLibs, newLibs) {
+		for _, pair := range pairLibs(oldLibs[artifact], newLibs[artifact]) {
+			oldFile, newFile := pair[0], pair[1]
+			change := LibChange{Artifact: artifact, OldFile: oldFile, NewFile: newFile}
+			_, change.OldVersion = SplitJarName(path.Base(oldFile))
+			_, change.NewVersion = SplitJarName(path.Base(newFile))
+
+			switch {
+			case newFile == "":
+				change.Change = ChangeRemoved
+			case oldFile == "":
+				change.Change = ChangeAdded
+			case change.OldVersion != change.NewVersion:
+				change.Change = ChangeVersionChanged
+			case oldEntries[oldFile].digest != newEntries[newFile].digest:
+				change.Change = ChangeModified
+				oldJar := runtime.MustReturnResult(OpenNestedJar(oldBundle, oldFile))
+				newJar := runtime.MustReturnResult(OpenNestedJar(newBundle, newFile))
+				diff.Classes = append(diff.Classes, diffClasses(newFile,
+					runtime.MustReturnResult(readBundleEntries(oldJar)),
+					runtime.MustReturnResult(readBundleEntries(newJar)))...)
+			default:
+				continue
+			}
+			diff.Libs = append(diff.Libs, change)
+		}
+	}
+	return diff, nil
+}
+
+func diffManifest(oldManifest, newManifest Manifest) []AttributeChange {
+	changes := []AttributeChange{}
+	for _, name := range sortedKeys(oldManifest, newManifest) {
+		oldValue, inOld := oldManifest[name]
+		newValue, inNew := newManifest[name]
+		change := AttributeChange{Name: name, Old: oldValue, New: newValue}
+		switch {
+		case !inNew:
+			change.Change = ChangeRemoved
+		case !inOld:
+			change.Change = ChangeAdded
+		case oldValue != newValue:
+			change.Change = ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffClasses compare the class files, nested jars are not compared.
+func diffClasses(jar string, oldEntries, newEntries map[string]bundleEntry) []ClassChange {
+	var changes []ClassChange
+	for _, name := range sortedKeys(oldEntries, newEntries) {
+		if !strings.HasSuffix(name, ".class") {
+			continue
+		}
+		oldEntry, inOld := oldEntries[name]
+		newEntry, inNew := newEntries[name]
+		change := ClassChange{
+			Jar:       jar,
+			Class:     strings.ReplaceAll(strings.TrimSuffix(name, ".class"), "/", "."),
+			OldSize:   oldEntry.size,
+			NewSize:   newEntry.size,
+			SizeDelta: newEntry.size - oldEntry.size,
+		}
+		switch {
+		case !inNew:
+			change.Change = ChangeRemoved
+		case !inOld:
+			change.Change = ChangeAdded
+		case oldEntry.digest != newEntry.digest:
+			change.Change = ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// readBundleEntries return the size and digest of files in fsys by their slash separated paths.
+func readBundleEntries(fsys fs.FS) (map[string]bundleEntry, error) {
+	entries := map[string]bundleEntry{}
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		entries[name] = bundleEntry{size: int64(len(content)), digest: sha256.Sum256(content)}
+		return nil
+	})
+	return entries, err
+}
+
+// libsByArtifact return the embedded lib jars by their artifact ids, sorted by path.
+// More than one jar have the same artifact id if they are shaded in different versions or dirs.
+func libsByArtifact(entries map[string]bundleEntry) map[string][]string {
+	libs := map[string][]string{}
+	for name := range entries {
+		if strings.HasSuffix(name, ".jar") {
+			artifact, _ := SplitJarName(path.Base(name))
+			libs[artifact] = append(libs[artifact], name)
+		}
+	}
+	for _, files := range libs {
+		sort.Strings(files)
+	}
+	return libs
+}
+
+// pairLibs pair the old and new jars of an artifact to compare, empty if the jar is absent on one side.
+// The jars are paired by their paths if the artifact id is shared by more than one jar on either side.
+func pairLibs(oldFiles, newFiles []string) [][2]string {
+	if len(oldFiles) <= 1 && len(newFiles) <= 1 {
+		pair := [2]string{}
+		if len(oldFiles) == 1 {
+			pair[0] = oldFiles[0]
+		}
+		if len(newFiles) == 1 {
+			pair[1] = newFiles[0]
+		}
+		return [][2]string{pair}
+	}
+
+	inOld, inNew := map[string]bool{}, map[string]bool{}
+	for _, file := range oldFiles {
+		inOld[file] = true
+	}
+	for _, file := range newFiles {
+		inNew[file] = true
+	}
+	var pairs [][2]string
+	for _, file := range sortedKeys(inOld, inNew) {
+		pair := [2]string{}
+		if inOld[file] {
+			pair[0] = file
+		}
+		if inNew[file] {
+			pair[1] = file
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// SplitJarName guess the artifact id and version from jar file name like foo-bar-1.0.0-SNAPSHOT.jar
+func SplitJarName(fileName string) (string, string) {
+	name := strings.TrimSuffix(fileName, ".jar")
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" && parts[i][0] >= '0' && parts[i][0] <= '9' {
+			return strings.Join(parts[:i], "-"), strings.Join(parts[i:], "-")
+		}
+	}
+	return name, ""
+}
+
+// sortedKeys return the union of keys in maps, sorted.
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBundle(t *testing.T) {
+	oldUrl := ziptest.BizJar(t, "biz-1.0.0-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":          []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\nRemoved: yes\n"),
+		"com/biz/Biz.class":             mockClass(52),
+		"com/biz/Removed.class":         mockClass(52),
+		"com/biz/Same.class":            mockClass(52),
+		"lib/foo-1.0.0.jar":             ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(52)}),
+		"lib/bar-1.0.0.jar":             ziptest.Zip(t, map[string][]byte{"com/bar/Bar.class": mockClass(52)}),
+		"lib/common-2.0.0-SNAPSHOT.jar": ziptest.Zip(t, map[string][]byte{"com/common/Common.class": mockClass(52)}),
+		"lib/same-1.0.0.jar":            ziptest.Zip(t, map[string][]byte{"com/same/Same.class": mockClass(52)}),
+	})
+	newUrl := ziptest.BizJar(t, "biz-1.0.1-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":          []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.1\nAdded: yes\n"),
+		"com/biz/Biz.class":             append(mockClass(52), 0, 0),
+		"com/biz/Added.class":           mockClass(52),
+		"com/biz/Same.class":            mockClass(52),
+		"lib/foo-1.1.0.jar":             ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(52)}),
+		"lib/baz-1.0.0.jar":             ziptest.Zip(t, map[string][]byte{"com/baz/Baz.class": mockClass(52)}),
+		"lib/common-2.0.0-SNAPSHOT.jar": ziptest.Zip(t, map[string][]byte{"com/common/Common.class": mockClass(55)}),
+		"lib/same-1.0.0.jar":            ziptest.Zip(t, map[string][]byte{"com/same/Same.class": mockClass(52)}),
+	})
+
+	diff, err := DiffBundle(context.Background(), oldUrl, newUrl)
+	assert.Nil(t, err)
+	assert.False(t, diff.IsEmpty())
+
+	assert.Equal(t, []AttributeChange{
+		{Name: "Added", Change: ChangeAdded, New: "yes"},
+		{Name: "Ark-Biz-Version", Change: ChangeModified, Old: "1.0.0", New: "1.0.1"},
+		{Name: "Removed", Change: ChangeRemoved, Old: "yes"},
+	}, diff.Manifest)
+
+	assert.Equal(t, []LibChange{
+		{Artifact: "bar", Change: ChangeRemoved, OldFile: "lib/bar-1.0.0.jar", OldVersion: "1.0.0"},
+		{Artifact: "baz", Change: ChangeAdded, NewFile: "lib/baz-1.0.0.jar", NewVersion: "1.0.0"},
+		{Artifact: "common", Change: ChangeModified, OldFile: "lib/common-2.0.0-SNAPSHOT.jar", NewFile: "lib/common-2.0.0-SNAPSHOT.jar", OldVersion: "2.0.0-SNAPSHOT", NewVersion: "2.0.0-SNAPSHOT"},
+		{Artifact: "foo", Change: ChangeVersionChanged, OldFile: "lib/foo-1.0.0.jar", NewFile: "lib/foo-1.1.0.jar", OldVersion: "1.0.0", NewVersion: "1.1.0"},
+	}, diff.Libs)
+
+	assert.Equal(t, []ClassChange{
+		{Class: "com.biz.Added", Change: ChangeAdded, NewSize: 8, SizeDelta: 8},
+		{Class: "com.biz.Biz", Change: ChangeModified, OldSize: 8, NewSize: 10, SizeDelta: 2},
+		{Class: "com.biz.Removed", Change: ChangeRemoved, OldSize: 8, SizeDelta: -8},
+		{Jar: "lib/common-2.0.0-SNAPSHOT.jar", Class: "com.common.Common", Change: ChangeModified, OldSize: 8, NewSize: 8},
+	}, diff.Classes)
+
+	same, err := DiffBundle(context.Background(), oldUrl, oldUrl)
+	assert.Nil(t, err)
+	assert.True(t, same.IsEmpty())
+}
+
+func TestDiffBundle_SameArtifact(t *testing.T) {
+	oldUrl := ziptest.BizJar(t, "biz-1.0.0-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":  []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+		"lib/foo-1.0.0.jar":     ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(52)}),
+		"lib/ext/foo-2.0.0.jar": ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(55)}),
+	})
+	newUrl := ziptest.BizJar(t, "biz-1.0.1-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":  []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+		"lib/foo-1.0.0.jar":     ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(61)}),
+		"lib/ext/foo-2.0.0.jar": ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(55)}),
+		"lib/ext/foo-2.1.0.jar": ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(55)}),
+	})
+
+	// the jars sharing artifact id are paired by path, each of them is reported
+	for i := 0; i < 3; i++ {
+		diff, err := DiffBundle(context.Background(), oldUrl, newUrl)
+		assert.Nil(t, err)
+		assert.Equal(t, []LibChange{
+			{Artifact: "foo", Change: ChangeAdded, NewFile: "lib/ext/foo-2.1.0.jar", NewVersion: "2.1.0"},
+			{Artifact: "foo", Change: ChangeModified, OldFile: "lib/foo-1.0.0.jar", NewFile: "lib/foo-1.0.0.jar", OldVersion: "1.0.0", NewVersion: "1.0.0"},
+		}, diff.Libs)
+		assert.Equal(t, []ClassChange{
+			{Jar: "lib/foo-1.0.0.jar", Class: "com.foo.Foo", Change: ChangeModified, OldSize: 8, NewSize: 8},
+		}, diff.Classes)
+	}
+}
+
+func TestSplitJarName(t *testing.T) {
+	for fileName, expected := range map[string][2]string{
+		"foo-1.0.0.jar":              {"foo", "1.0.0"},
+		"foo-bar-2.0.0-SNAPSHOT.jar": {"foo-bar", "2.0.0-SNAPSHOT"},
+		"foo.jar":                    {"foo", ""},
+	} {
+		artifact, version := SplitJarName(fileName)
+		assert.Equal(t, expected, [2]string{artifact, version}, fileName)
+	}
+}
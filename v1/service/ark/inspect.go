@@ -0,0 +1,83 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+)
+
+// BundleInfo is the metadata of biz bundle.
+type BundleInfo struct {
+	BizName    string           `json:"bizName"`
+	BizVersion string           `json:"bizVersion"`
+	BizUrl     fileutil.FileUrl `json:"bizUrl"`
+	Type       BundleType       `json:"type"`
+
+	// Digest is the sha256 digest of bundle, empty if the bundle is read remotely.
+	Digest string `json:"digest,omitempty"`
+
+	// Manifest is the main attributes of manifest.
+	Manifest Manifest `json:"manifest"`
+
+	// Libs are the file names of embedded lib jars.
+	Libs []string `json:"libs"`
+}
+
+// InspectBundle read the metadata of biz bundle given by bizUrl.
+// Like ParseBizModel, remote bundles are read with range requests instead of being downloaded.
+func InspectBundle(ctx context.Context, bizUrl fileutil.FileUrl) (*BundleInfo, error) {
+	bundle, err := openBundleLazily(ctx, bizUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer bundle.Close()
+
+	manifest, err := bundle.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	info := &BundleInfo{
+		BizName:    manifest["Ark-Biz-Name"],
+		BizVersion: manifest["Ark-Biz-Version"],
+		BizUrl:     bizUrl,
+		Type:       bundle.Type,
+		Manifest:   manifest,
+		Libs:       []string{},
+	}
+	if bundle.Path != "" {
+		if info.Digest, err = bundle.Digest(); err != nil {
+			return nil, err
+		}
+	}
+
+	// only the names of files are read, which is cheap for remote bundles
+	err = fs.WalkDir(bundle, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(name, ".jar") {
+			info.Libs = append(info.Libs, path.Base(name))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(info.Libs)
+	return info, nil
+}
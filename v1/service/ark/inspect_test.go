@@ -0,0 +1,63 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectBundle(t *testing.T) {
+	bizUrl := mockBizJar(t)
+	info, err := InspectBundle(context.Background(), bizUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, "biz", info.BizName)
+	assert.Equal(t, "1.0.0", info.BizVersion)
+	assert.Equal(t, BundleTypeJar, info.Type)
+	assert.True(t, strings.HasPrefix(info.Digest, "sha256:"))
+	assert.Equal(t, []string{"bar-1.0.0.jar", "foo-1.0.0.jar"}, info.Libs)
+}
+
+func TestInspectBundle_Remote(t *testing.T) {
+	content, err := os.ReadFile(strings.TrimPrefix(string(mockBizJar(t)), osutil.GetLocalFileProtocol()))
+	assert.Nil(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			t.Error("remote bundle should be read with range requests")
+		}
+		http.ServeContent(w, r, "biz-ark-biz.jar", time.Unix(0, 0), bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	info, err := InspectBundle(context.Background(), fileutil.FileUrl(server.URL+"/biz-ark-biz.jar"))
+	assert.Nil(t, err)
+	assert.Equal(t, "biz", info.BizName)
+	assert.Equal(t, BundleTypeJar, info.Type)
+	assert.Equal(t, "", info.Digest)
+
+	model, err := ParseBizModel(context.Background(), fileutil.FileUrl(server.URL+"/biz-ark-biz.jar"))
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", model.BizVersion)
+}
@@ -0,0 +1,138 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+)
+
+// ErrSignatureMismatch means the signature of bundle is not signed by the configured public key, or the bundle is tampered.
+var ErrSignatureMismatch = errors.New("signature mismatch")
+
+// Digest return the sha256 digest of bundle in the format of sha256:{hex}.
+// The digest of exploded directory is the digest of its files, sorted by their slash separated paths.
+func (b *Bundle) Digest() (string, error) {
+	if b.Type != BundleTypeDirectory {
+		return fileutil.Digest(b.Path)
+	}
+
+	hash := sha256.New()
+	err := fs.WalkDir(b.FS, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(b.FS, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(hash, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ParsePublicKey parse the PEM encoded PKIX public key, RSA, ECDSA and Ed25519 keys are supported.
+func ParsePublicKey(content []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("invalid public key: no PEM block found")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// VerifySignature verify the detached signature of bundle file at localPath.
+// Ed25519 signatures are made over the file, RSA (PKCS #1 v1.5) and ECDSA signatures over its sha256 digest,
+// which are what `openssl pkeyutl -sign -rawin` and `openssl dgst -sha256 -sign` produce respectively.
+// The signature could be raw bytes or base64 encoded.
+func VerifySignature(localPath string, signature []byte, publicKey crypto.PublicKey) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("signature of exploded directory is not supported, sign the packaged bundle instead")
+	}
+
+	signature = decodeSignature(signature)
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(content)
+
+	verified := false
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		verified = ed25519.Verify(key, content, signature)
+	case *rsa.PublicKey:
+		verified = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		verified = ecdsa.VerifyASN1(key, digest[:], signature)
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	if !verified {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// decodeSignature return the decoded signature if it's base64 encoded, otherwise the raw signature.
+func decodeSignature(signature []byte) []byte {
+	trimmed := bytes.TrimSpace(signature)
+	if decoded, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return signature
+}
+
+// SignatureUrl return the url of detached signature next to the bundle, e.g. foo-ark-biz.jar.sig
+// The signature of maven coordinates can not be located and must be given explicitly.
+func SignatureUrl(bizUrl fileutil.FileUrl) (fileutil.FileUrl, bool) {
+	if bizUrl.GetFileUrlType() == fileutil.FileUrlTypeMaven {
+		return "", false
+	}
+	rawUrl, _, _ := strings.Cut(string(bizUrl), "#")
+	return fileutil.FileUrl(rawUrl + ".sig"), true
+}
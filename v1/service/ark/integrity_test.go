@@ -0,0 +1,117 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePublicKey(t *testing.T, publicKey crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestBundleDigest(t *testing.T) {
+	bizUrl := mockBizJar(t)
+	localPath := strings.TrimPrefix(string(bizUrl), osutil.GetLocalFileProtocol())
+	expected, err := fileutil.Digest(localPath)
+	assert.Nil(t, err)
+
+	model, err := ParseBizModel(context.Background(), bizUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, model.Digest)
+
+	// the digest of exploded directory changes with the content of its files
+	bizDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(bizDir, "META-INF"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(bizDir, ManifestPath), []byte("Ark-Biz-Name: biz\n"), 0644))
+	bundle, err := OpenLocalBundle(bizDir)
+	assert.Nil(t, err)
+	before, err := bundle.Digest()
+	assert.Nil(t, err)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(bizDir, "application.yml"), []byte("foo: bar"), 0644))
+	after, err := bundle.Digest()
+	assert.Nil(t, err)
+	assert.NotEqual(t, before, after)
+}
+
+func TestVerifySignature(t *testing.T) {
+	localPath := strings.TrimPrefix(string(mockBizJar(t)), osutil.GetLocalFileProtocol())
+	content, err := os.ReadFile(localPath)
+	assert.Nil(t, err)
+	digest := sha256.Sum256(content)
+
+	ed25519Public, ed25519Private, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecdsaPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaPrivate, crypto.SHA256, digest[:])
+	assert.Nil(t, err)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaPrivate, digest[:])
+	assert.Nil(t, err)
+
+	for name, signed := range map[string]struct {
+		publicKey crypto.PublicKey
+		signature []byte
+	}{
+		"ed25519": {ed25519Public, ed25519.Sign(ed25519Private, content)},
+		"rsa":     {&rsaPrivate.PublicKey, rsaSignature},
+		"ecdsa":   {&ecdsaPrivate.PublicKey, []byte(base64.StdEncoding.EncodeToString(ecdsaSignature) + "\n")},
+	} {
+		publicKey, err := ParsePublicKey(encodePublicKey(t, signed.publicKey))
+		assert.Nil(t, err, name)
+		assert.Nil(t, VerifySignature(localPath, signed.signature, publicKey), name)
+	}
+
+	// tampered bundle
+	assert.Nil(t, os.WriteFile(localPath, append(content, 0), 0644))
+	assert.ErrorIs(t, VerifySignature(localPath, rsaSignature, &rsaPrivate.PublicKey), ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature(localPath, ed25519.Sign(ed25519Private, content), ed25519Public), ErrSignatureMismatch)
+
+	_, err = ParsePublicKey([]byte("not a key"))
+	assert.NotNil(t, err)
+}
+
+func TestSignatureUrl(t *testing.T) {
+	signatureUrl, ok := SignatureUrl("https://artifacts.example/foo-ark-biz.jar#sha256=abc")
+	assert.True(t, ok)
+	assert.Equal(t, fileutil.FileUrl("https://artifacts.example/foo-ark-biz.jar.sig"), signatureUrl)
+
+	_, ok = SignatureUrl("mvn://com.foo:foo:1.0.0")
+	assert.False(t, ok)
+}
@@ -0,0 +1,123 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+)
+
+// DeployRecord is the record of biz bundle deployed by arkctl.
+type DeployRecord struct {
+	Time       time.Time        `json:"time"`
+	BizName    string           `json:"bizName"`
+	BizVersion string           `json:"bizVersion"`
+	BizUrl     fileutil.FileUrl `json:"bizUrl"`
+
+	// Target is the base the biz is deployed to, like 127.0.0.1:1238 or pod default/foo.
+	Target string `json:"target"`
+
+	// Digest is the sha256 digest of bundle.
+	Digest string `json:"digest,omitempty"`
+
+	// SbomDigest is the sha256 digest of SBOM generated for bundle, and SbomPath is where it's saved.
+	SbomDigest string `json:"sbomDigest,omitempty"`
+	SbomPath   string `json:"sbomPath,omitempty"`
+}
+
+// DefaultDeployRecordPath return the path of deploy records at ~/.arkctl/deploys.jsonl.
+func DefaultDeployRecordPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".arkctl", "deploys.jsonl")
+}
+
+// AppendDeployRecord append the record to the json lines file at recordPath.
+func AppendDeployRecord(recordPath string, record DeployRecord) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(recordPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadDeployRecords return the records at recordPath in the order they are appended.
+// The malformed lines, like the one half written, are skipped.
+func ReadDeployRecords(recordPath string) ([]DeployRecord, error) {
+	file, err := os.Open(recordPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []DeployRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		record := DeployRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// FindDeployRecord return the latest record of biz, the empty bizVersion or target matches any.
+func FindDeployRecord(records []DeployRecord, bizName, bizVersion, target string) (DeployRecord, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.BizName == bizName &&
+			(bizVersion == "" || record.BizVersion == bizVersion) &&
+			(target == "" || record.Target == target) {
+			return record, true
+		}
+	}
+	return DeployRecord{}, false
+}
+
+// FindPreviousDeployRecord return the latest record of biz in a version other than the current one,
+// which is the version to redeploy if the current one fails. The empty target matches any.
+func FindPreviousDeployRecord(records []DeployRecord, bizName, currentVersion, target string) (DeployRecord, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if record.BizName == bizName && record.BizVersion != currentVersion &&
+			(target == "" || record.Target == target) {
+			return record, true
+		}
+	}
+	return DeployRecord{}, false
+}
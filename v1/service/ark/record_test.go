@@ -0,0 +1,82 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployRecords(t *testing.T) {
+	recordPath := filepath.Join(t.TempDir(), "arkctl", "deploys.jsonl")
+	records, err := ReadDeployRecords(recordPath)
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	first := DeployRecord{
+		Time:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		BizName:    "biz",
+		BizVersion: "1.0.0",
+		BizUrl:     "file:///tmp/biz-ark-biz.jar",
+		Target:     "127.0.0.1:1238",
+		Digest:     "sha256:abc",
+	}
+	second := first
+	second.SbomDigest = "sha256:def"
+	assert.Nil(t, AppendDeployRecord(recordPath, first))
+	assert.Nil(t, AppendDeployRecord(recordPath, second))
+
+	// a half written line is skipped
+	file, err := os.OpenFile(recordPath, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	_, err = file.WriteString(`{"bizName":"bi`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	records, err = ReadDeployRecords(recordPath)
+	assert.Nil(t, err)
+	assert.Equal(t, []DeployRecord{first, second}, records)
+
+	found, ok := FindDeployRecord(records, "biz", "1.0.0", "127.0.0.1:1238")
+	assert.True(t, ok)
+	assert.Equal(t, second, found)
+	_, ok = FindDeployRecord(records, "biz", "2.0.0", "")
+	assert.False(t, ok)
+}
+
+func TestFindPreviousDeployRecord(t *testing.T) {
+	records := []DeployRecord{
+		{BizName: "biz", BizVersion: "1.0.0", Target: "127.0.0.1:1238"},
+		{BizName: "biz", BizVersion: "1.0.0", Target: "pod default/base"},
+		{BizName: "other", BizVersion: "0.1.0", Target: "127.0.0.1:1238"},
+		{BizName: "biz", BizVersion: "1.0.1", Target: "127.0.0.1:1238"},
+	}
+
+	// the failing version deployed before is not the previous one
+	previous, ok := FindPreviousDeployRecord(records, "biz", "1.0.1", "127.0.0.1:1238")
+	assert.True(t, ok)
+	assert.Equal(t, records[0], previous)
+
+	previous, ok = FindPreviousDeployRecord(records, "biz", "1.0.2", "")
+	assert.True(t, ok)
+	assert.Equal(t, records[3], previous)
+
+	_, ok = FindPreviousDeployRecord(records, "other", "0.1.0", "")
+	assert.False(t, ok)
+}
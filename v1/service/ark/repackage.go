@@ -0,0 +1,283 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+)
+
+const (
+	// manifestLineLength is the max length in bytes of manifest lines, longer ones are continued in next line.
+	manifestLineLength = 72
+
+	// arkConfigDir is where the embedded ark config properties are.
+	arkConfigDir = "conf/ark/"
+)
+
+// RepackageOptions are the biz attributes rewritten by Repackage, empty ones are kept as they are.
+type RepackageOptions struct {
+	BizName        string
+	BizVersion     string
+	WebContextPath string
+}
+
+// manifestAttributes return the manifest attributes to rewrite.
+func (o RepackageOptions) manifestAttributes() [][2]string {
+	return nonEmptyAttributes([][2]string{
+		{"Ark-Biz-Name", o.BizName},
+		{"Ark-Biz-Version", o.BizVersion},
+		{"Web-Context-Path", o.WebContextPath},
+	})
+}
+
+// propertyAttributes return the embedded ark config properties to rewrite.
+func (o RepackageOptions) propertyAttributes() [][2]string {
+	return nonEmptyAttributes([][2]string{
+		{"bizName", o.BizName},
+		{"bizVersion", o.BizVersion},
+		{"webContextPath", o.WebContextPath},
+	})
+}
+
+func nonEmptyAttributes(attributes [][2]string) [][2]string {
+	var result [][2]string
+	for _, attribute := range attributes {
+		if attribute[1] != "" {
+			result = append(result, attribute)
+		}
+	}
+	return result
+}
+
+// Repackage copy the biz bundle given by bizUrl to output with the biz attributes rewritten.
+// The manifest and the embedded ark config under conf/ark are rewritten, all other entries are copied byte-identical.
+func Repackage(ctx context.Context, bizUrl fileutil.FileUrl, output string, opts RepackageOptions) (*BizModel, error) {
+	if len(opts.manifestAttributes()) == 0 {
+		return nil, errors.New("nothing to repackage, at least one of biz name, biz version and web context path is required")
+	}
+
+	bundle, err := OpenBundle(ctx, bizUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer bundle.Close()
+	if bundle.Type == BundleTypeDirectory {
+		return nil, fmt.Errorf("repackaging exploded biz directory %s is not supported, package it first", bundle.Path)
+	}
+	if same, _ := sameFile(bundle.Path, output); same {
+		return nil, fmt.Errorf("output %s should not be the same as the bundle", output)
+	}
+	zipReader := bundle.FS.(*zip.ReadCloser)
+
+	// write to a temp file, so that the output is never half written
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	writer := zip.NewWriter(tmpFile)
+	if err := repackageEntries(zipReader, writer, opts); err != nil {
+		tmpFile.Close()
+		return nil, err
+	}
+	if err := writer.SetComment(zipReader.Comment); err != nil {
+		tmpFile.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		tmpFile.Close()
+		return nil, err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpFile.Name(), output); err != nil {
+		return nil, err
+	}
+
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBizModel(ctx, fileutil.FileUrl(osutil.GetLocalFileProtocol()+absOutput))
+}
+
+func repackageEntries(zipReader *zip.ReadCloser, writer *zip.Writer, opts RepackageOptions) error {
+	for _, file := range zipReader.File {
+		var rewrite func([]byte) []byte
+		switch {
+		case file.Name == ManifestPath:
+			rewrite = func(content []byte) []byte {
+				return RewriteManifest(content, opts.manifestAttributes())
+			}
+		case strings.HasPrefix(file.Name, arkConfigDir) && path.Ext(file.Name) == ".properties":
+			rewrite = func(content []byte) []byte {
+				return rewriteProperties(content, opts.propertyAttributes())
+			}
+		default:
+			if err := writer.Copy(file); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", file.Name, err)
+			}
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+
+		// keep the name, method, time and attributes of entry, the sizes and checksum are computed by writer
+		header := file.FileHeader
+		header.CRC32, header.CompressedSize64, header.UncompressedSize64 = 0, 0, 0
+		header.CompressedSize, header.UncompressedSize = 0, 0
+		entryWriter, err := writer.CreateHeader(&header)
+		if err != nil {
+			return err
+		}
+		if _, err := entryWriter.Write(rewrite(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RewriteManifest set the attributes in the main section of manifest, the missing ones are appended to the section.
+// The order, line endings and other attributes of manifest are kept.
+func RewriteManifest(content []byte, attributes [][2]string) []byte {
+	newline := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	// main section ends at the first empty line
+	mainEnd := len(lines)
+	for i, line := range lines {
+		if line == "" {
+			mainEnd = i
+			break
+		}
+	}
+
+	var result []string
+	written := map[string]bool{}
+	for i := 0; i < mainEnd; i++ {
+		// join the continuation lines of attribute
+		end := i + 1
+		for end < mainEnd && strings.HasPrefix(lines[end], " ") {
+			end++
+		}
+		key, _, _ := strings.Cut(lines[i], ":")
+		replaced := false
+		for _, attribute := range attributes {
+			// attribute names of manifest are case-insensitive
+			if strings.EqualFold(strings.TrimSpace(key), attribute[0]) {
+				result = append(result, wrapManifestLine(attribute[0]+": "+attribute[1])...)
+				written[attribute[0]] = true
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, lines[i:end]...)
+		}
+		i = end - 1
+	}
+	for _, attribute := range attributes {
+		if !written[attribute[0]] {
+			result = append(result, wrapManifestLine(attribute[0]+": "+attribute[1])...)
+		}
+	}
+
+	result = append(result, lines[mainEnd:]...)
+	if mainEnd == len(lines) {
+		// manifest must end with a new line
+		result = append(result, "")
+	}
+	return []byte(strings.Join(result, newline))
+}
+
+// wrapManifestLine split the line longer than 72 bytes into continuation lines.
+func wrapManifestLine(line string) []string {
+	var lines []string
+	for len(line) > manifestLineLength {
+		// never split a multi-byte character
+		cut := manifestLineLength
+		for cut > 1 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		lines = append(lines, line[:cut])
+		line = " " + line[cut:]
+	}
+	return append(lines, line)
+}
+
+// rewriteProperties set the value of existing keys in properties, the comments and other keys are kept.
+func rewriteProperties(content []byte, attributes [][2]string) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		separator := strings.IndexAny(trimmed, "=: \t")
+		if separator < 0 {
+			continue
+		}
+		for _, attribute := range attributes {
+			if trimmed[:separator] != attribute[0] {
+				continue
+			}
+			ending := line[len(strings.TrimRight(line, "\r\n")):]
+			lines[i] = attribute[0] + "=" + escapeProperty(attribute[1]) + ending
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+func escapeProperty(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(value)
+}
+
+func sameFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(infoA, infoB), nil
+}
@@ -0,0 +1,115 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ark
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/osutil"
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func readRawEntries(t *testing.T, localPath string) map[string][]byte {
+	reader, err := zip.OpenReader(localPath)
+	assert.Nil(t, err)
+	defer reader.Close()
+
+	entries := map[string][]byte{}
+	for _, file := range reader.File {
+		raw, err := file.OpenRaw()
+		assert.Nil(t, err)
+		entries[file.Name], err = io.ReadAll(raw)
+		assert.Nil(t, err)
+	}
+	return entries
+}
+
+func TestRepackage(t *testing.T) {
+	bizPath := filepath.Join(t.TempDir(), "biz-ark-biz.jar")
+	assert.Nil(t, os.WriteFile(bizPath, ziptest.Zip(t, map[string][]byte{
+		ManifestPath: []byte("Manifest-Version: 1.0\r\n" +
+			"Ark-Biz-Name: biz\r\n" +
+			"Ark-Biz-Version: 1.0.0\r\n" +
+			"Main-Class: com.alipay.sofa.web.biz1.Biz1Applicati\r\n" +
+			" on\r\n" +
+			"\r\n"),
+		"conf/ark/bootstrap.properties": []byte("# biz config\nbizName=biz\nbizVersion = 1.0.0\nfoo=bar\n"),
+		"com/biz/Biz.class":             mockClass(52),
+		"lib/foo-1.0.0.jar":             ziptest.Zip(t, map[string][]byte{"com/foo/Foo.class": mockClass(52)}),
+	}), 0644))
+
+	output := filepath.Join(t.TempDir(), "out", "biz2-ark-biz.jar")
+	model, err := Repackage(context.Background(), fileutil.FileUrl(osutil.GetLocalFileProtocol()+bizPath), output, RepackageOptions{
+		BizName:        "biz2",
+		BizVersion:     "1.0.0-dev.20240101",
+		WebContextPath: "/biz2",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "biz2", model.BizName)
+	assert.Equal(t, "1.0.0-dev.20240101", model.BizVersion)
+
+	before, after := readRawEntries(t, bizPath), readRawEntries(t, output)
+	assert.Equal(t, len(before), len(after))
+	for _, name := range []string{"com/biz/Biz.class", "lib/foo-1.0.0.jar"} {
+		assert.Equal(t, before[name], after[name], name)
+	}
+
+	bundle, err := OpenLocalBundle(output)
+	assert.Nil(t, err)
+	defer bundle.Close()
+	manifest, err := bundle.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, Manifest{
+		"Manifest-Version": "1.0",
+		"Ark-Biz-Name":     "biz2",
+		"Ark-Biz-Version":  "1.0.0-dev.20240101",
+		"Main-Class":       "com.alipay.sofa.web.biz1.Biz1Application",
+		"Web-Context-Path": "/biz2",
+	}, manifest)
+
+	properties, err := fs.ReadFile(bundle, "conf/ark/bootstrap.properties")
+	assert.Nil(t, err)
+	assert.Equal(t, "# biz config\nbizName=biz2\nbizVersion=1.0.0-dev.20240101\nfoo=bar\n", string(properties))
+
+	_, err = Repackage(context.Background(), fileutil.FileUrl(osutil.GetLocalFileProtocol()+bizPath), bizPath, RepackageOptions{BizName: "biz2"})
+	assert.NotNil(t, err)
+	_, err = Repackage(context.Background(), fileutil.FileUrl(osutil.GetLocalFileProtocol()+bizPath), output, RepackageOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestRewriteManifest_LongValue(t *testing.T) {
+	rewritten := RewriteManifest([]byte("Manifest-Version: 1.0\n"), [][2]string{{"Ark-Biz-Name", strings.Repeat("a", 100)}})
+	lines := strings.Split(string(rewritten), "\n")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), manifestLineLength)
+	}
+	assert.Equal(t, strings.Repeat("a", 100), ParseManifest(rewritten)["Ark-Biz-Name"])
+	assert.True(t, strings.HasSuffix(string(rewritten), "\n"))
+}
+
+func TestRewriteManifest_LowercaseKey(t *testing.T) {
+	manifest := "Manifest-Version: 1.0\nweb-context-path: biz1\nArk-Biz-Name: biz1\n"
+	rewritten := RewriteManifest([]byte(manifest), [][2]string{{"Web-Context-Path", "biz2"}})
+	assert.Equal(t, "Manifest-Version: 1.0\nWeb-Context-Path: biz2\nArk-Biz-Name: biz1\n", string(rewritten))
+}
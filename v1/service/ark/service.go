@@ -31,7 +31,8 @@ type Service interface {
 	ParseBizModel(ctx context.Context, bizUrl fileutil.FileUrl) (*BizModel, error)
 
 	// InstallBiz call the remote ark container to install biz.
-	// The precondition is that the biz file is already uploaded to the ark container or file hosting service (e.g. oss).
+	// The precondition is that the biz file is already uploaded to the ark container or file hosting service (e.g. oss),
+	// see fileutil.FileUtils.Upload and fileutil.FileUtils.Presign.
 	InstallBiz(ctx context.Context, req InstallBizRequest) error
 
 	// UnInstallBiz call the remote ark container to install biz.
@@ -107,6 +107,10 @@ type BizModel struct {
 
 	// BizUrl is the location of source code.
 	BizUrl fileutil.FileUrl `json:"bizUrl,omitempty"`
+
+	// Digest is the sha256 digest of bundle computed at parse time, in the format of sha256:{hex}.
+	// It's not sent to ark container.
+	Digest string `json:"-"`
 }
 
 type BizInfo struct {
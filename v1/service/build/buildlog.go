@@ -0,0 +1,69 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	buildLogDir    = "logs"
+	buildLogPrefix = "build-"
+	buildLogSuffix = ".log"
+
+	// keptBuildLogs is the count of latest build logs kept in project.
+	keptBuildLogs = 10
+)
+
+// CreateBuildLog create the file keeping the whole output of build started at now,
+// which is .arkctl/logs/build-{timestamp}.log in project dir, the logs older than the latest ones are removed.
+func CreateBuildLog(dir string, now time.Time) (*os.File, error) {
+	stateDir, err := ensureStateDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	logDir := filepath.Join(stateDir, buildLogDir)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, err
+	}
+	pruneBuildLogs(logDir, keptBuildLogs-1)
+
+	// the timestamps are sorted as the names, and the builds in a second are told apart by milliseconds
+	name := buildLogPrefix + now.Format("20060102-150405.000") + buildLogSuffix
+	return os.Create(filepath.Join(logDir, name))
+}
+
+// pruneBuildLogs remove the build logs in logDir except the latest kept ones, failing to remove them is ignored.
+func pruneBuildLogs(logDir string, kept int) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return
+	}
+	var logs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), buildLogPrefix) && strings.HasSuffix(entry.Name(), buildLogSuffix) {
+			logs = append(logs, entry.Name())
+		}
+	}
+	sort.Strings(logs)
+	for len(logs) > kept {
+		_ = os.Remove(filepath.Join(logDir, logs[0]))
+		logs = logs[1:]
+	}
+}
@@ -0,0 +1,41 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBuildLog(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	for i := 0; i < keptBuildLogs+2; i++ {
+		file, err := CreateBuildLog(dir, start.Add(time.Duration(i)*time.Second))
+		assert.Nil(t, err)
+		assert.Nil(t, file.Close())
+	}
+
+	logs, err := os.ReadDir(filepath.Join(dir, StateDir, buildLogDir))
+	assert.Nil(t, err)
+	assert.Len(t, logs, keptBuildLogs)
+	assert.Equal(t, "build-20240102-150407.000.log", logs[0].Name())
+	assert.Equal(t, "build-20240102-150416.000.log", logs[len(logs)-1].Name())
+	assert.FileExists(t, filepath.Join(dir, StateDir, ".gitignore"))
+}
@@ -0,0 +1,75 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// ChangedModules return the directories of modules relative to dir, which contain the changed build inputs.
+// The files are relative to dir, and "." is returned if the build inputs of root project are changed.
+func ChangedModules(dir string, tool Tool, files []string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var moduleDirs []string
+	switch tool {
+	case ToolGradle:
+		if moduleDirs, err = LoadGradleProjects(dir); err != nil {
+			return nil, err
+		}
+	default:
+		modules, err := LoadMavenReactor(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, module := range modules {
+			moduleDirs = append(moduleDirs, module.Dir)
+		}
+	}
+
+	changed := map[string]bool{}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		// the file belongs to the innermost module containing it
+		owner := ""
+		for _, moduleDir := range moduleDirs {
+			if inModule, err := dirMatcher(moduleDir); err == nil && inModule(path) && len(moduleDir) > len(owner) {
+				owner = moduleDir
+			}
+		}
+		if owner == "" {
+			continue
+		}
+		if relative, err := filepath.Rel(owner, path); err != nil || !isBuildInput(filepath.ToSlash(relative)) {
+			continue
+		}
+		module, err := filepath.Rel(dir, owner)
+		if err != nil {
+			return nil, err
+		}
+		changed[module] = true
+	}
+
+	modules := make([]string, 0, len(changed))
+	for module := range changed {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
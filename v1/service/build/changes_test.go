@@ -0,0 +1,44 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedModules(t *testing.T) {
+	dir := mockMavenProject(t)
+
+	modules, err := ChangedModules(dir, ToolMaven, []string{
+		"biz-a/src/main/java/Foo.java",
+		"nested/biz-b/pom.xml",
+		"nested/biz-b/README.md",
+		"README.md",
+		"docs/index.md",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"biz-a", filepath.Join("nested", "biz-b")}, modules)
+
+	modules, err = ChangedModules(dir, ToolMaven, []string{"pom.xml", "biz-a/src/main/resources/application.properties"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".", "biz-a"}, modules)
+
+	modules, err = ChangedModules(dir, ToolMaven, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, modules)
+}
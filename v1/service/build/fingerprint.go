@@ -0,0 +1,230 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// StateDir is the directory in project where arkctl keeps its state, which should not be committed.
+	StateDir = ".arkctl"
+
+	buildRecordFile = "builds.json"
+)
+
+// buildOutputDirs are the directories where the build tools put their outputs, right under the module root.
+var buildOutputDirs = map[string]bool{
+	"target": true,
+	"build":  true,
+	"out":    true,
+}
+
+// isSkippedDir return true if the directory at slash separated relative path in project dir never contains build inputs,
+// which are the hidden directories like .git and .idea except .mvn with maven.config in it, node_modules,
+// and the build outputs of modules. The directories under src are never skipped, since they are packages and resources.
+func isSkippedDir(dir, relative string) bool {
+	if relative == "." || isUnderSrc(relative) {
+		return false
+	}
+	name := path.Base(relative)
+	switch {
+	case strings.HasPrefix(name, ".") && name != ".mvn", name == "node_modules":
+		return true
+	case buildOutputDirs[name]:
+		return isModuleRoot(filepath.Join(dir, filepath.FromSlash(path.Dir(relative))))
+	}
+	return false
+}
+
+// isModuleRoot return true if there is a build script of maven or gradle in dir.
+func isModuleRoot(dir string) bool {
+	for _, name := range []string{pomFile, "build.gradle", "build.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnderSrc return true if the slash separated relative path is in a src directory.
+func isUnderSrc(relative string) bool {
+	return strings.HasPrefix(relative, "src/") || strings.Contains(relative, "/src/")
+}
+
+// isBuildInput return true if the file at slash separated relative path affects the build,
+// which are the build scripts, and the sources and resources under src.
+func isBuildInput(relative string) bool {
+	name := filepath.Base(relative)
+	switch {
+	case name == pomFile, name == "gradle.properties", name == "maven.config", name == "jvm.config":
+		return true
+	case strings.HasPrefix(name, "build.gradle"), strings.HasPrefix(name, "settings.gradle"):
+		return true
+	}
+	return isUnderSrc(relative)
+}
+
+// Fingerprint return the digest of build inputs of project in dir together with the build tool and options,
+// so that the build could be skipped if it's not changed since last build.
+func Fingerprint(dir string, tool Tool, opts Options) (string, error) {
+	hash := sha256.New()
+	optsJson, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(hash, "%s\n%s\n", tool, optsJson)
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		if entry.IsDir() {
+			if isSkippedDir(dir, relative) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !isBuildInput(relative) {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		fileHash := sha256.New()
+		if _, err := io.Copy(fileHash, file); err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s  %s\n", hex.EncodeToString(fileHash.Sum(nil)), relative)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// BuildRecord is the record of last build, kept in .arkctl/builds.json of project.
+type BuildRecord struct {
+	Fingerprint string    `json:"fingerprint"`
+	Bundles     []string  `json:"bundles"`
+	Time        time.Time `json:"time"`
+}
+
+// loadBuildRecord return the record of last build of module in project dir, the module is the relative path
+// of sub module or "." for the whole project.
+func loadBuildRecord(dir, module string) (BuildRecord, bool) {
+	records, err := readBuildRecords(dir)
+	if err != nil {
+		return BuildRecord{}, false
+	}
+	record, ok := records[module]
+	return record, ok
+}
+
+// ReusableBundles return the bundles of last build if the fingerprint matches and the bundles still exist.
+func ReusableBundles(dir, module, fingerprint string) ([]string, bool) {
+	record, ok := loadBuildRecord(dir, module)
+	if !ok || record.Fingerprint != fingerprint || len(record.Bundles) == 0 {
+		return nil, false
+	}
+	for _, bundle := range record.Bundles {
+		info, err := os.Stat(bundle)
+		// the bundle rebuilt or touched after the record is not what's recorded
+		if err != nil || info.ModTime().After(record.Time) {
+			return nil, false
+		}
+	}
+	return record.Bundles, true
+}
+
+// SaveBuildRecord save the record of build of module in project dir.
+func SaveBuildRecord(dir, module string, record BuildRecord) error {
+	records, err := readBuildRecords(dir)
+	if err != nil {
+		records = map[string]BuildRecord{}
+	}
+	records[module] = record
+
+	stateDir, err := ensureStateDir(dir)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(stateDir, buildRecordFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), filepath.Join(stateDir, buildRecordFile))
+}
+
+// ensureStateDir create the state directory of project in dir if it's not yet, and return its path.
+func ensureStateDir(dir string) (string, error) {
+	stateDir := filepath.Join(dir, StateDir)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", err
+	}
+	// keep the state out of version control
+	if _, err := os.Stat(filepath.Join(stateDir, ".gitignore")); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(filepath.Join(stateDir, ".gitignore"), []byte("*\n"), 0644); err != nil {
+			return "", err
+		}
+	}
+	return stateDir, nil
+}
+
+func readBuildRecords(dir string) (map[string]BuildRecord, error) {
+	content, err := os.ReadFile(filepath.Join(dir, StateDir, buildRecordFile))
+	if err != nil {
+		return nil, err
+	}
+	records := map[string]BuildRecord{}
+	if err := json.Unmarshal(content, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
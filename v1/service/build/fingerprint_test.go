@@ -0,0 +1,109 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := mockMavenProject(t)
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "com", "foo", "Foo.java"), "class Foo {}")
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "resources", "application.properties"), "foo=bar")
+
+	fingerprint, err := Fingerprint(dir, ToolMaven, Options{})
+	assert.Nil(t, err)
+
+	// build outputs, vcs files and other files never affect the build
+	writeFile(t, filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar"), "jar")
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/master")
+	writeFile(t, filepath.Join(dir, "README.md"), "readme")
+	unchanged, err := Fingerprint(dir, ToolMaven, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, fingerprint, unchanged)
+
+	withProfile, err := Fingerprint(dir, ToolMaven, Options{Profiles: []string{"dev"}})
+	assert.Nil(t, err)
+	assert.NotEqual(t, fingerprint, withProfile)
+
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "resources", "application.properties"), "foo=baz")
+	changed, err := Fingerprint(dir, ToolMaven, Options{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, fingerprint, changed)
+
+	writeFile(t, filepath.Join(dir, ".mvn", "maven.config"), "-T 4")
+	configured, err := Fingerprint(dir, ToolMaven, Options{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, changed, configured)
+
+	// the packages named like build outputs are sources
+	for _, pkg := range []string{"build", "out", "target"} {
+		writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "x", pkg, "Foo.java"), "class Foo {}")
+		withPackage, err := Fingerprint(dir, ToolMaven, Options{})
+		assert.Nil(t, err)
+		assert.NotEqual(t, configured, withPackage, pkg)
+		configured = withPackage
+	}
+}
+
+func TestIsSkippedDir(t *testing.T) {
+	dir := mockMavenProject(t)
+	assert.False(t, isSkippedDir(dir, "."))
+	assert.True(t, isSkippedDir(dir, ".git"))
+	assert.False(t, isSkippedDir(dir, ".mvn"))
+	assert.True(t, isSkippedDir(dir, "biz-a/target"))
+	assert.True(t, isSkippedDir(dir, "biz-a/node_modules"))
+	// not a module root
+	assert.False(t, isSkippedDir(dir, "docs/build"))
+	assert.False(t, isSkippedDir(dir, "biz-a/src/main/java/x/build"))
+	assert.False(t, isSkippedDir(dir, "biz-a/src/main/java/x/out"))
+	assert.False(t, isSkippedDir(dir, "biz-a/src/main/resources/.well-known"))
+}
+
+func TestReusableBundles(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "target", "biz-ark-biz.jar")
+	writeFile(t, bundle, "jar")
+
+	_, ok := ReusableBundles(dir, ".", "sha256:1")
+	assert.False(t, ok)
+
+	assert.Nil(t, SaveBuildRecord(dir, ".", BuildRecord{Fingerprint: "sha256:1", Bundles: []string{bundle}, Time: time.Now()}))
+	assert.Nil(t, SaveBuildRecord(dir, "sub", BuildRecord{Fingerprint: "sha256:2", Bundles: []string{bundle}, Time: time.Now()}))
+	assert.FileExists(t, filepath.Join(dir, StateDir, ".gitignore"))
+
+	bundles, ok := ReusableBundles(dir, ".", "sha256:1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{bundle}, bundles)
+	_, ok = ReusableBundles(dir, ".", "sha256:2")
+	assert.False(t, ok)
+	_, ok = ReusableBundles(dir, "sub", "sha256:2")
+	assert.True(t, ok)
+
+	// the bundle rebuilt after the record
+	later := time.Now().Add(time.Hour)
+	assert.Nil(t, os.Chtimes(bundle, later, later))
+	_, ok = ReusableBundles(dir, ".", "sha256:1")
+	assert.False(t, ok)
+
+	assert.Nil(t, os.Remove(bundle))
+	_, ok = ReusableBundles(dir, "sub", "sha256:2")
+	assert.False(t, ok)
+}
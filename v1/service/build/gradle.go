@@ -0,0 +1,100 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gradleSettingsFiles are the names of gradle settings script.
+var gradleSettingsFiles = []string{"settings.gradle", "settings.gradle.kts"}
+
+var (
+	// gradleInclude matches include statements like include 'a', ':b:c' or include("a", "b")
+	gradleInclude = regexp.MustCompile(`(?m)^\s*include\s*\(?((?:\s*['"][^'"]+['"]\s*,?)+)\)?`)
+
+	// gradleProjectDir matches project dir overrides like project(':a').projectDir = file('modules/a')
+	gradleProjectDir = regexp.MustCompile(`project\(\s*['"]([^'"]+)['"]\s*\)\.projectDir\s*=\s*(?:new\s+File\(\s*settingsDir\s*,\s*|file\(\s*)['"]([^'"]+)['"]`)
+
+	gradleQuoted = regexp.MustCompile(`['"]([^'"]+)['"]`)
+)
+
+// LoadGradleProjects return the directories of root project in dir and the projects included in its settings script.
+// Only the literal include statements and project dir overrides are recognized, since the script is not evaluated.
+func LoadGradleProjects(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	projects := []string{dir}
+
+	var settings []byte
+	for _, name := range gradleSettingsFiles {
+		if settings, err = os.ReadFile(filepath.Join(dir, name)); err == nil {
+			break
+		}
+	}
+	if settings == nil {
+		return projects, nil
+	}
+
+	projectDirs := map[string]string{}
+	for _, match := range gradleProjectDir.FindAllStringSubmatch(string(settings), -1) {
+		projectDirs[":"+strings.TrimPrefix(match[1], ":")] = match[2]
+	}
+	for _, include := range gradleInclude.FindAllStringSubmatch(string(settings), -1) {
+		for _, quoted := range gradleQuoted.FindAllStringSubmatch(include[1], -1) {
+			projectPath := ":" + strings.TrimPrefix(quoted[1], ":")
+			projectDir, ok := projectDirs[projectPath]
+			if !ok {
+				// the project :a:b is in a/b by default
+				projectDir = strings.ReplaceAll(strings.TrimPrefix(projectPath, ":"), ":", "/")
+			}
+			if !filepath.IsAbs(projectDir) {
+				projectDir = filepath.Join(dir, filepath.FromSlash(projectDir))
+			}
+			projects = append(projects, projectDir)
+		}
+	}
+	return projects, nil
+}
+
+// LocateGradleBizBundles return the newest biz bundles in build/libs of the gradle projects in searchDir.
+// The bundles modified before since are ignored as stale ones.
+func LocateGradleBizBundles(projectDir, searchDir string, since time.Time) ([]string, error) {
+	inSearchDir, err := dirMatcher(searchDir)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := LoadGradleProjects(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []string
+	for _, project := range projects {
+		if !inSearchDir(project) {
+			continue
+		}
+		if found := findBizBundle(filepath.Join(project, "build", "libs"), "", since); found != nil {
+			bundles = append(bundles, found.Path)
+		}
+	}
+	return bundles, nil
+}
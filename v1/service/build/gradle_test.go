@@ -0,0 +1,61 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocateGradleBizBundles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "settings.gradle"), `
+rootProject.name = 'demo'
+include 'biz-a', ':libs:common'
+include(
+    "biz-b"
+)
+project(':biz-b').projectDir = file('modules/biz-b')
+`)
+	projects, err := LoadGradleProjects(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		dir,
+		filepath.Join(dir, "biz-a"),
+		filepath.Join(dir, "libs", "common"),
+		filepath.Join(dir, "modules", "biz-b"),
+	}, projects)
+
+	bundleA := filepath.Join(dir, "biz-a", "build", "libs", "biz-a-1.0.0-ark-biz.jar")
+	bundleB := filepath.Join(dir, "modules", "biz-b", "build", "libs", "biz-b-1.0.0-ark-biz.jar")
+	staleB := filepath.Join(dir, "modules", "biz-b", "build", "libs", "biz-b-0.9.0-ark-biz.jar")
+	writeFile(t, bundleA, "a")
+	writeFile(t, bundleB, "b")
+	writeFile(t, staleB, "stale")
+	stale := time.Now().Add(-time.Hour)
+	assert.Nil(t, os.Chtimes(staleB, stale, stale))
+
+	bundles, err := LocateGradleBizBundles(dir, dir, time.Now().Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{bundleA, bundleB}, bundles)
+
+	bundles, err = LocateGradleBizBundles(dir, filepath.Join(dir, "modules"), time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{bundleB}, bundles)
+}
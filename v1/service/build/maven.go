@@ -0,0 +1,350 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// BizBundleSuffix is the suffix of biz bundles built by koupleless-base-build-plugin or sofa-ark-maven-plugin.
+	BizBundleSuffix = "-ark-biz.jar"
+
+	pomFile = "pom.xml"
+)
+
+// MavenModule is a module in maven reactor.
+type MavenModule struct {
+	// Dir is the absolute directory of module.
+	Dir string
+
+	GroupId    string
+	ArtifactId string
+	Version    string
+	Packaging  string
+
+	// FinalName is the name of built artifacts without extension, ${artifactId}-${version} by default.
+	FinalName string
+
+	// BuildDir is the absolute build directory of module, target by default.
+	BuildDir string
+}
+
+// pom is the part of pom.xml used to locate built artifacts.
+type pom struct {
+	GroupId    string `xml:"groupId"`
+	ArtifactId string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Packaging  string `xml:"packaging"`
+	Parent     struct {
+		GroupId string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties pomProperties `xml:"properties"`
+	Modules    []string      `xml:"modules>module"`
+	Profiles   []struct {
+		Activation struct {
+			ActiveByDefault bool `xml:"activeByDefault"`
+		} `xml:"activation"`
+		Modules []string `xml:"modules>module"`
+	} `xml:"profiles>profile"`
+	Build struct {
+		FinalName string `xml:"finalName"`
+		Directory string `xml:"directory"`
+	} `xml:"build"`
+}
+
+// pomProperties are the properties of pom, like <revision>1.0.0</revision>.
+type pomProperties map[string]string
+
+func (p *pomProperties) UnmarshalXML(decoder *xml.Decoder, _ xml.StartElement) error {
+	*p = pomProperties{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := decoder.DecodeElement(&value, &element); err != nil {
+				return err
+			}
+			(*p)[element.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func readPom(dir string) (*pom, error) {
+	content, err := os.ReadFile(filepath.Join(dir, pomFile))
+	if err != nil {
+		return nil, err
+	}
+	project := &pom{}
+	if err := xml.Unmarshal(content, project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, pomFile), err)
+	}
+	return project, nil
+}
+
+// IsMavenProject return true if there is a pom.xml in dir.
+func IsMavenProject(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, pomFile))
+	return err == nil
+}
+
+// LoadMavenReactor return the modules built by maven in dir, which are the project itself and its modules recursively,
+// including the modules of profiles active by default. The modules are returned in the order they are declared.
+func LoadMavenReactor(dir string) ([]MavenModule, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var modules []MavenModule
+	err = loadMavenModule(dir, pomProperties{}, pom{}, map[string]bool{}, &modules)
+	return modules, err
+}
+
+func loadMavenModule(dir string, inherited pomProperties, parent pom, visited map[string]bool, modules *[]MavenModule) error {
+	if visited[dir] {
+		return nil
+	}
+	visited[dir] = true
+
+	project, err := readPom(dir)
+	if err != nil {
+		return err
+	}
+
+	// the properties and build settings of parent are inherited by modules
+	properties := pomProperties{}
+	for name, value := range inherited {
+		properties[name] = value
+	}
+	for name, value := range project.Properties {
+		properties[name] = value
+	}
+	if project.GroupId == "" {
+		project.GroupId = firstNonEmpty(project.Parent.GroupId, parent.GroupId)
+	}
+	if project.Version == "" {
+		project.Version = firstNonEmpty(project.Parent.Version, parent.Version)
+	}
+	if project.Packaging == "" {
+		project.Packaging = "jar"
+	}
+	if project.Build.FinalName == "" {
+		project.Build.FinalName = parent.Build.FinalName
+	}
+	if project.Build.Directory == "" {
+		project.Build.Directory = parent.Build.Directory
+	}
+
+	interpolate := func(value string) string {
+		return interpolatePom(value, project, dir, properties)
+	}
+	module := MavenModule{
+		Dir:        dir,
+		GroupId:    interpolate(project.GroupId),
+		ArtifactId: interpolate(project.ArtifactId),
+		Version:    interpolate(project.Version),
+		Packaging:  project.Packaging,
+		FinalName:  interpolate(firstNonEmpty(project.Build.FinalName, "${project.artifactId}-${project.version}")),
+		BuildDir:   interpolate(firstNonEmpty(project.Build.Directory, "${project.basedir}/target")),
+	}
+	if !filepath.IsAbs(module.BuildDir) {
+		module.BuildDir = filepath.Join(dir, module.BuildDir)
+	}
+	*modules = append(*modules, module)
+
+	moduleNames := project.Modules
+	for _, profile := range project.Profiles {
+		if profile.Activation.ActiveByDefault {
+			moduleNames = append(moduleNames, profile.Modules...)
+		}
+	}
+	for _, name := range moduleNames {
+		moduleDir := filepath.Join(dir, filepath.FromSlash(strings.TrimSpace(name)))
+		// a module could be given by the path of its pom
+		if strings.HasSuffix(moduleDir, ".xml") {
+			moduleDir = filepath.Dir(moduleDir)
+		}
+		if err := loadMavenModule(moduleDir, properties, *project, visited, modules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var pomExpression = regexp.MustCompile(`\$\{([^}]+)}`)
+
+// interpolatePom replace the expressions like ${project.version} and ${revision} in value.
+// The unknown expressions are kept as they are.
+func interpolatePom(value string, project *pom, dir string, properties pomProperties) string {
+	for i := 0; i < 10 && strings.Contains(value, "${"); i++ {
+		value = pomExpression.ReplaceAllStringFunc(value, func(expression string) string {
+			name := expression[2 : len(expression)-1]
+			switch strings.TrimPrefix(name, "project.") {
+			case "groupId":
+				return project.GroupId
+			case "artifactId":
+				return project.ArtifactId
+			case "version":
+				return project.Version
+			case "basedir":
+				return dir
+			case "build.directory":
+				return firstNonEmpty(project.Build.Directory, filepath.Join(dir, "target"))
+			}
+			if property, ok := properties[name]; ok {
+				return property
+			}
+			return expression
+		})
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// BuiltBundle is a biz bundle built by a module.
+type BuiltBundle struct {
+	// Module is the maven module building the bundle, empty for gradle projects.
+	Module  MavenModule
+	Path    string
+	ModTime time.Time
+}
+
+// FindBizBundles return the biz bundles in the build directory of modules, without walking the project tree.
+// The bundle named after the final name of module is preferred, otherwise the newest one.
+// The bundles modified before since are considered stale and ignored, unless since is zero.
+func FindBizBundles(modules []MavenModule, since time.Time) []BuiltBundle {
+	var bundles []BuiltBundle
+	for _, module := range modules {
+		if found := findBizBundle(module.BuildDir, module.FinalName+BizBundleSuffix, since); found != nil {
+			found.Module = module
+			bundles = append(bundles, *found)
+		}
+	}
+	return bundles
+}
+
+// findBizBundle return the bundle named preferredName in dir if any, otherwise the newest bundle in dir.
+func findBizBundle(dir, preferredName string, since time.Time) *BuiltBundle {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found *BuiltBundle
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), BizBundleSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || (!since.IsZero() && info.ModTime().Before(since)) {
+			continue
+		}
+		candidate := &BuiltBundle{Path: filepath.Join(dir, entry.Name()), ModTime: info.ModTime()}
+		switch {
+		case found == nil:
+			found = candidate
+		case entry.Name() == preferredName:
+			found = candidate
+		case filepath.Base(found.Path) != preferredName && candidate.ModTime.After(found.ModTime):
+			found = candidate
+		}
+	}
+	return found
+}
+
+// mavenBuiltJar matches the jars reported by maven like [INFO] Building jar: /path/to/foo-ark-biz.jar
+var mavenBuiltJar = regexp.MustCompile(`Building jar:\s+(\S+` + regexp.QuoteMeta(BizBundleSuffix) + `)\s*$`)
+
+// ParseMavenOutput return the biz bundles reported in maven output lines, in the order they are built.
+func ParseMavenOutput(lines []string) []string {
+	seen := map[string]bool{}
+	var bundles []string
+	for _, line := range lines {
+		match := mavenBuiltJar.FindStringSubmatch(line)
+		if match != nil && !seen[match[1]] {
+			seen[match[1]] = true
+			bundles = append(bundles, match[1])
+		}
+	}
+	return bundles
+}
+
+// LocateBizBundles return the biz bundles built by maven in projectDir, limited to the modules in searchDir.
+// The bundles reported in maven output are preferred, otherwise the bundles are looked up in the build directory
+// of reactor modules, and those modified before since are ignored as stale ones.
+func LocateBizBundles(projectDir, searchDir string, mavenOutput []string, since time.Time) ([]string, error) {
+	inSearchDir, err := dirMatcher(searchDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []string
+	for _, bundle := range ParseMavenOutput(mavenOutput) {
+		if _, err := os.Stat(bundle); err == nil && inSearchDir(bundle) {
+			bundles = append(bundles, bundle)
+		}
+	}
+	if len(bundles) > 0 {
+		return bundles, nil
+	}
+
+	modules, err := LoadMavenReactor(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	var searched []MavenModule
+	for _, module := range modules {
+		if inSearchDir(module.Dir) {
+			searched = append(searched, module)
+		}
+	}
+	for _, bundle := range FindBizBundles(searched, since) {
+		bundles = append(bundles, bundle.Path)
+	}
+	return bundles, nil
+}
+
+// dirMatcher return a func telling whether the path is dir or in dir.
+func dirMatcher(dir string) (func(path string) bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return func(path string) bool {
+		relative, err := filepath.Rel(dir, path)
+		return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+	}, nil
+}
@@ -0,0 +1,129 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func mockMavenProject(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pom.xml"), `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+    <groupId>com.foo</groupId>
+    <artifactId>parent</artifactId>
+    <version>${revision}</version>
+    <packaging>pom</packaging>
+    <properties>
+        <revision>1.0.0</revision>
+    </properties>
+    <modules>
+        <module>biz-a</module>
+    </modules>
+    <profiles>
+        <profile>
+            <id>default</id>
+            <activation><activeByDefault>true</activeByDefault></activation>
+            <modules><module>nested/biz-b/pom.xml</module></modules>
+        </profile>
+    </profiles>
+</project>`)
+	writeFile(t, filepath.Join(dir, "biz-a", "pom.xml"), `<project>
+    <parent><groupId>com.foo</groupId><artifactId>parent</artifactId><version>${revision}</version></parent>
+    <artifactId>biz-a</artifactId>
+</project>`)
+	writeFile(t, filepath.Join(dir, "nested", "biz-b", "pom.xml"), `<project>
+    <parent><groupId>com.foo</groupId><artifactId>parent</artifactId><version>${revision}</version></parent>
+    <artifactId>biz-b</artifactId>
+    <build>
+        <finalName>${project.artifactId}-bundle</finalName>
+        <directory>${project.basedir}/out</directory>
+    </build>
+</project>`)
+	return dir
+}
+
+func TestLoadMavenReactor(t *testing.T) {
+	dir := mockMavenProject(t)
+	modules, err := LoadMavenReactor(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []MavenModule{
+		{Dir: dir, GroupId: "com.foo", ArtifactId: "parent", Version: "1.0.0", Packaging: "pom", FinalName: "parent-1.0.0", BuildDir: filepath.Join(dir, "target")},
+		{Dir: filepath.Join(dir, "biz-a"), GroupId: "com.foo", ArtifactId: "biz-a", Version: "1.0.0", Packaging: "jar", FinalName: "biz-a-1.0.0", BuildDir: filepath.Join(dir, "biz-a", "target")},
+		{Dir: filepath.Join(dir, "nested", "biz-b"), GroupId: "com.foo", ArtifactId: "biz-b", Version: "1.0.0", Packaging: "jar", FinalName: "biz-b-bundle", BuildDir: filepath.Join(dir, "nested", "biz-b", "out")},
+	}, modules)
+
+	_, err = LoadMavenReactor(t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestFindBizBundles(t *testing.T) {
+	dir := mockMavenProject(t)
+	modules, err := LoadMavenReactor(dir)
+	assert.Nil(t, err)
+
+	stale := time.Now().Add(-time.Hour)
+	writeFile(t, filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar"), "a")
+	writeFile(t, filepath.Join(dir, "biz-a", "target", "biz-a-0.9.0-ark-biz.jar"), "old")
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "biz-a", "target", "biz-a-0.9.0-ark-biz.jar"), stale, stale))
+	writeFile(t, filepath.Join(dir, "nested", "biz-b", "out", "biz-b-bundle-ark-biz.jar"), "b")
+	// the bundles out of build directory are never picked
+	writeFile(t, filepath.Join(dir, "node_modules", "foo-ark-biz.jar"), "foo")
+
+	bundles := FindBizBundles(modules, time.Time{})
+	assert.Equal(t, 2, len(bundles))
+	assert.Equal(t, filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar"), bundles[0].Path)
+	assert.Equal(t, filepath.Join(dir, "nested", "biz-b", "out", "biz-b-bundle-ark-biz.jar"), bundles[1].Path)
+
+	// only the stale bundle is left
+	assert.Nil(t, os.Remove(filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar")))
+	bundles = FindBizBundles(modules[:2], time.Now().Add(-time.Minute))
+	assert.Empty(t, bundles)
+}
+
+func TestLocateBizBundles(t *testing.T) {
+	dir := mockMavenProject(t)
+	bundleA := filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar")
+	bundleB := filepath.Join(dir, "nested", "biz-b", "out", "biz-b-bundle-ark-biz.jar")
+	writeFile(t, bundleA, "a")
+	writeFile(t, bundleB, "b")
+
+	bundles, err := LocateBizBundles(dir, dir, nil, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{bundleA, bundleB}, bundles)
+
+	bundles, err = LocateBizBundles(dir, filepath.Join(dir, "nested"), nil, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{bundleB}, bundles)
+
+	// the bundles reported by maven are preferred
+	bundles, err = LocateBizBundles(dir, dir, []string{
+		"[INFO] Building jar: " + bundleB,
+		"[INFO] Building jar: " + filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0.jar"),
+		"[INFO] Building jar: " + bundleB,
+	}, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{bundleB}, bundles)
+}
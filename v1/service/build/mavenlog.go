@@ -0,0 +1,300 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModuleState is the state of module in maven reactor, the finished ones are named as in reactor summary.
+type ModuleState string
+
+const (
+	ModuleBuilding ModuleState = "BUILDING"
+	ModuleSuccess  ModuleState = "SUCCESS"
+	ModuleFailure  ModuleState = "FAILURE"
+	ModuleSkipped  ModuleState = "SKIPPED"
+)
+
+// ReactorModule is a module built by maven, as reported in its output.
+type ReactorModule struct {
+	// Name is the name of module, which is the artifactId unless <name> is given.
+	Name string
+
+	// Index is the 1-based position of module in reactor, and Total is the count of modules.
+	// Both are 0 if maven does not report them, which is the case before 3.6.
+	Index int
+	Total int
+
+	State ModuleState
+
+	// Goal is the plugin goal running in module, like compiler:compile.
+	Goal string
+
+	Start    time.Time
+	Duration time.Duration
+}
+
+var (
+	// mavenAnsiEscape matches the color codes of maven output.
+	mavenAnsiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	// mavenLevel matches the level prefix of maven output like [INFO] and its message.
+	mavenLevel = regexp.MustCompile(`^\[(DEBUG|INFO|WARNING|WARN|ERROR|FATAL)\] ?(.*)$`)
+
+	// mavenBuilding matches the start of module like Building foo-biz 1.0.0 [2/3], where jars built are excluded by colon.
+	mavenBuilding = regexp.MustCompile(`^Building ([^:]+?) (\S+)(?:\s+\[(\d+)/(\d+)\])?$`)
+
+	// mavenProject matches the header of module like ------< com.example:foo-biz >------, followed by its Building line.
+	mavenProject = regexp.MustCompile(`^-+< [^:\s]+:(\S+) >-+$`)
+
+	// mavenGoal matches the plugin goal like --- compiler:3.11.0:compile (default-compile) @ foo-biz ---
+	mavenGoal = regexp.MustCompile(`^--- (\S+) \(\S+\) @ (\S+) ---$`)
+
+	// mavenSummary matches the module in reactor summary like foo-biz ........ SUCCESS [  1.234 s]
+	mavenSummary = regexp.MustCompile(`^(.+?) \.+ ?(SUCCESS|FAILURE|SKIPPED)\b`)
+
+	// mavenFailedProject matches the module failing the build like Failed to execute goal ... on project foo-biz: ...
+	mavenFailedProject = regexp.MustCompile(`on project ([^:\s]+):`)
+)
+
+// MavenLog parse the output of maven line by line, tracking the modules in reactor and the errors.
+// It's safe for concurrent use.
+type MavenLog struct {
+	// OnModule is called when a module starts, runs a goal or finishes.
+	OnModule func(module ReactorModule)
+
+	mu            sync.Mutex
+	lines         []string
+	modules       []*ReactorModule
+	errors        [][]string
+	inErrorBlock  bool
+	inSummary     bool
+	failedProject string
+	now           func() time.Time
+
+	// parallel is true if maven builds modules in threads with -T, whose outputs are interleaved.
+	parallel bool
+	// project is the artifactId in the header of module whose Building line is not seen yet.
+	project string
+	// byArtifact is the modules by their artifactIds, which are what goals are reported with.
+	byArtifact map[string]*ReactorModule
+}
+
+// NewMavenLog return an empty maven log.
+func NewMavenLog() *MavenLog {
+	return &MavenLog{now: time.Now, byArtifact: map[string]*ReactorModule{}}
+}
+
+// Write parse a line of maven output.
+func (l *MavenLog) Write(line string) {
+	l.mu.Lock()
+	var changed []ReactorModule
+	defer func() {
+		l.mu.Unlock()
+		// the callback may take a while to render, which should not block other writers
+		for _, module := range changed {
+			if l.OnModule != nil {
+				l.OnModule(module)
+			}
+		}
+	}()
+
+	line = mavenAnsiEscape.ReplaceAllString(line, "")
+	l.lines = append(l.lines, line)
+
+	match := mavenLevel.FindStringSubmatch(line)
+	if match == nil {
+		// the lines without level continue the error, like the stack traces
+		if l.inErrorBlock {
+			l.errors[len(l.errors)-1] = append(l.errors[len(l.errors)-1], line)
+		}
+		return
+	}
+	level, message := match[1], strings.TrimSpace(match[2])
+
+	if level == "ERROR" || level == "FATAL" {
+		if !l.inErrorBlock {
+			l.errors = append(l.errors, nil)
+			l.inErrorBlock = true
+		}
+		l.errors[len(l.errors)-1] = append(l.errors[len(l.errors)-1], line)
+		if project := mavenFailedProject.FindStringSubmatch(message); project != nil && l.failedProject == "" {
+			l.failedProject = project[1]
+		}
+		return
+	}
+	l.inErrorBlock = false
+
+	switch {
+	case strings.HasPrefix(message, "Using the MultiThreadedBuilder"):
+		l.parallel = true
+	case strings.HasPrefix(message, "Reactor Summary"):
+		l.inSummary = true
+	case message == "BUILD SUCCESS" || message == "BUILD FAILURE":
+		l.inSummary = false
+		// the single module builds have no reactor summary
+		state := ModuleSuccess
+		if message == "BUILD FAILURE" {
+			state = ModuleFailure
+		}
+		for _, module := range l.building() {
+			l.finish(module, state)
+			changed = append(changed, *module)
+		}
+	case l.inSummary:
+		if summary := mavenSummary.FindStringSubmatch(message); summary != nil {
+			module := l.module(summary[1])
+			if module.State != ModuleState(summary[2]) {
+				l.finish(module, ModuleState(summary[2]))
+				changed = append(changed, *module)
+			}
+		}
+	default:
+		if project := mavenProject.FindStringSubmatch(message); project != nil {
+			l.project = project[1]
+		} else if goal := mavenGoal.FindStringSubmatch(message); goal != nil {
+			if module := l.goalModule(goal[2]); module != nil {
+				module.Goal = shortGoal(goal[1])
+				changed = append(changed, *module)
+			}
+		} else if building := mavenBuilding.FindStringSubmatch(message); building != nil {
+			// maven reports no end of module until the summary, a module ends when the next one starts,
+			// unless the modules are built in parallel, which end in the summary only
+			if !l.parallel {
+				for _, module := range l.building() {
+					l.finish(module, ModuleSuccess)
+					changed = append(changed, *module)
+				}
+			}
+			module := l.module(building[1])
+			if l.project != "" {
+				l.byArtifact[l.project] = module
+				l.project = ""
+			}
+			module.Index, _ = strconv.Atoi(building[3])
+			module.Total, _ = strconv.Atoi(building[4])
+			module.State = ModuleBuilding
+			module.Start = l.now()
+			changed = append(changed, *module)
+		}
+	}
+}
+
+// building return the modules being built, at most one unless the modules are built in parallel.
+func (l *MavenLog) building() []*ReactorModule {
+	var modules []*ReactorModule
+	for _, module := range l.modules {
+		if module.State == ModuleBuilding {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
+// goalModule return the module being built which runs the goal reported with artifactId, or nil if there is none.
+// The module is the only one being built if maven reports no header of module, which is the case before 3.6.
+func (l *MavenLog) goalModule(artifactId string) *ReactorModule {
+	module, ok := l.byArtifact[artifactId]
+	if !ok {
+		if building := l.building(); len(building) == 1 {
+			module = building[0]
+		}
+	}
+	if module == nil || module.State != ModuleBuilding {
+		return nil
+	}
+	return module
+}
+
+// module return the module named name, which is added if it's not yet.
+func (l *MavenLog) module(name string) *ReactorModule {
+	for _, module := range l.modules {
+		if module.Name == name {
+			return module
+		}
+	}
+	module := &ReactorModule{Name: name}
+	l.modules = append(l.modules, module)
+	return module
+}
+
+func (l *MavenLog) finish(module *ReactorModule, state ModuleState) {
+	if module.State == ModuleBuilding {
+		module.Duration = l.now().Sub(module.Start)
+	}
+	module.State = state
+	module.Goal = ""
+}
+
+// shortGoal return the goal like compiler:compile, without the version of plugin.
+func shortGoal(goal string) string {
+	parts := strings.Split(goal, ":")
+	if len(parts) < 2 {
+		return goal
+	}
+	// the plugins are named like maven-compiler-plugin and spring-boot-maven-plugin
+	plugin := strings.TrimSuffix(strings.TrimSuffix(parts[0], "-plugin"), "-maven")
+	return strings.TrimPrefix(plugin, "maven-") + ":" + parts[len(parts)-1]
+}
+
+// Lines return all lines written, without colors.
+func (l *MavenLog) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+// Modules return the modules reported so far, in the order they are built.
+func (l *MavenLog) Modules() []ReactorModule {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	modules := make([]ReactorModule, 0, len(l.modules))
+	for _, module := range l.modules {
+		modules = append(modules, *module)
+	}
+	return modules
+}
+
+// Errors return the blocks of consecutive error lines, with the lines continuing them like the stack traces.
+func (l *MavenLog) Errors() [][]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	errors := make([][]string, 0, len(l.errors))
+	for _, block := range l.errors {
+		errors = append(errors, append([]string(nil), block...))
+	}
+	return errors
+}
+
+// FailedModule return the module failing the build, which is the artifactId reported in errors if any,
+// otherwise the name of module failed in reactor summary, or empty if no module fails.
+func (l *MavenLog) FailedModule() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failedProject != "" {
+		return l.failedProject
+	}
+	for _, module := range l.modules {
+		if module.State == ModuleFailure {
+			return module.Name
+		}
+	}
+	return ""
+}
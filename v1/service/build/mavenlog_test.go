@@ -0,0 +1,176 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMavenLog_Reactor(t *testing.T) {
+	output := []string{
+		"[INFO] Scanning for projects...",
+		"[INFO] ------------------------------------------------------------------------",
+		"[INFO] Reactor Build Order:",
+		"[INFO] ",
+		"[INFO] foo-parent                                                         [pom]",
+		"[INFO] foo-facade                                                         [jar]",
+		"[INFO] foo-biz                                                            [jar]",
+		"[INFO] ",
+		"[INFO] ---------------------< com.example:foo-parent >----------------------",
+		"[INFO] Building foo-parent 1.0.0-SNAPSHOT                                 [1/3]",
+		"[INFO] --------------------------------[ pom ]---------------------------------",
+		"[INFO] ",
+		"[INFO] --- clean:3.2.0:clean (default-clean) @ foo-parent ---",
+		"[INFO] ---------------------< com.example:foo-facade >----------------------",
+		"[INFO] Building foo-facade 1.0.0-SNAPSHOT                                 [2/3]",
+		"[INFO] --------------------------------[ jar ]---------------------------------",
+		"[INFO] --- maven-compiler-plugin:3.8.1:compile (default-compile) @ foo-facade ---",
+		"[WARNING] deprecated api is used",
+		"[INFO] -------------------------------------------------------------",
+		"[ERROR] COMPILATION ERROR : ",
+		"[INFO] -------------------------------------------------------------",
+		"[ERROR] /foo/facade/src/main/java/Foo.java:[10,5] cannot find symbol",
+		"  symbol:   class Bar",
+		"  location: class Foo",
+		"[INFO] 1 error",
+		"[INFO] ------------------------------------------------------------------------",
+		"[INFO] Reactor Summary for foo-parent 1.0.0-SNAPSHOT:",
+		"[INFO] ",
+		"[INFO] foo-parent ......................................... SUCCESS [  0.120 s]",
+		"[INFO] foo-facade ......................................... FAILURE [  1.234 s]",
+		"[INFO] foo-biz ............................................ SKIPPED",
+		"[INFO] ------------------------------------------------------------------------",
+		"[INFO] BUILD FAILURE",
+		"[INFO] ------------------------------------------------------------------------",
+		"[ERROR] Failed to execute goal org.apache.maven.plugins:maven-compiler-plugin:3.8.1:compile (default-compile) on project foo-facade: Compilation failure",
+		"[ERROR] -> [Help 1]",
+	}
+
+	mavenLog := NewMavenLog()
+	var events []string
+	mavenLog.OnModule = func(module ReactorModule) {
+		events = append(events, module.Name+" "+string(module.State)+" "+module.Goal)
+	}
+	for _, line := range output {
+		mavenLog.Write(line)
+	}
+
+	assert.Equal(t, []string{
+		"foo-parent BUILDING ",
+		"foo-parent BUILDING clean:clean",
+		"foo-parent SUCCESS ",
+		"foo-facade BUILDING ",
+		"foo-facade BUILDING compiler:compile",
+		"foo-facade FAILURE ",
+		"foo-biz SKIPPED ",
+	}, events)
+
+	modules := mavenLog.Modules()
+	assert.Len(t, modules, 3)
+	assert.Equal(t, 2, modules[1].Index)
+	assert.Equal(t, 3, modules[1].Total)
+
+	assert.Equal(t, [][]string{
+		{"[ERROR] COMPILATION ERROR : "},
+		{
+			"[ERROR] /foo/facade/src/main/java/Foo.java:[10,5] cannot find symbol",
+			"  symbol:   class Bar",
+			"  location: class Foo",
+		},
+		{
+			"[ERROR] Failed to execute goal org.apache.maven.plugins:maven-compiler-plugin:3.8.1:compile (default-compile) on project foo-facade: Compilation failure",
+			"[ERROR] -> [Help 1]",
+		},
+	}, mavenLog.Errors())
+	assert.Equal(t, "foo-facade", mavenLog.FailedModule())
+	assert.Len(t, mavenLog.Lines(), len(output))
+}
+
+func TestMavenLog_Parallel(t *testing.T) {
+	mavenLog := NewMavenLog()
+	var events []string
+	mavenLog.OnModule = func(module ReactorModule) {
+		events = append(events, module.Name+" "+string(module.State)+" "+module.Goal)
+	}
+	for _, line := range []string{
+		"[INFO] Scanning for projects...",
+		"[INFO] Using the MultiThreadedBuilder implementation with a thread count of 4",
+		"[INFO] ---------------------< com.example:foo-facade >----------------------",
+		"[INFO] Building Foo Facade 1.0.0-SNAPSHOT                                 [1/3]",
+		"[INFO] --------------------------------[ jar ]---------------------------------",
+		"[INFO] -----------------------< com.example:foo-biz >-----------------------",
+		"[INFO] Building foo-biz 1.0.0-SNAPSHOT                                    [2/3]",
+		"[INFO] --------------------------------[ jar ]---------------------------------",
+		"[INFO] --- compiler:3.11.0:compile (default-compile) @ foo-facade ---",
+		"[INFO] --- compiler:3.11.0:compile (default-compile) @ foo-biz ---",
+		"[INFO] ----------------------< com.example:foo-web >-----------------------",
+		"[INFO] Building foo-web 1.0.0-SNAPSHOT                                    [3/3]",
+		"[INFO] --------------------------------[ jar ]---------------------------------",
+		"[INFO] --- jar:3.3.0:jar (default-jar) @ foo-facade ---",
+		"[INFO] --- compiler:3.11.0:compile (default-compile) @ foo-web ---",
+		"[INFO] ------------------------------------------------------------------------",
+		"[INFO] Reactor Summary for foo-parent 1.0.0-SNAPSHOT:",
+		"[INFO] ",
+		"[INFO] Foo Facade ......................................... SUCCESS [  1.120 s]",
+		"[INFO] foo-biz ............................................ FAILURE [  1.234 s]",
+		"[INFO] foo-web ............................................ SUCCESS [  1.345 s]",
+		"[INFO] ------------------------------------------------------------------------",
+		"[INFO] BUILD FAILURE",
+	} {
+		mavenLog.Write(line)
+	}
+
+	// the modules built in parallel end in the summary, the goals are told apart by artifactIds
+	assert.Equal(t, []string{
+		"Foo Facade BUILDING ",
+		"foo-biz BUILDING ",
+		"Foo Facade BUILDING compiler:compile",
+		"foo-biz BUILDING compiler:compile",
+		"foo-web BUILDING ",
+		"Foo Facade BUILDING jar:jar",
+		"foo-web BUILDING compiler:compile",
+		"Foo Facade SUCCESS ",
+		"foo-biz FAILURE ",
+		"foo-web SUCCESS ",
+	}, events)
+	assert.Equal(t, "foo-biz", mavenLog.FailedModule())
+}
+
+func TestMavenLog_SingleModule(t *testing.T) {
+	mavenLog := NewMavenLog()
+	now := time.Now()
+	mavenLog.now = func() time.Time { return now }
+	for _, line := range []string{
+		"\x1b[1;34mINFO\x1b[m] ignored",
+		"[INFO] Building foo-biz 1.0.0",
+		"[INFO] Building jar: /foo/target/foo-biz-1.0.0-ark-biz.jar",
+		"[INFO] BUILD SUCCESS",
+	} {
+		now = now.Add(time.Second)
+		mavenLog.Write(line)
+	}
+
+	assert.Equal(t, []ReactorModule{{
+		Name:     "foo-biz",
+		State:    ModuleSuccess,
+		Start:    mavenLog.Modules()[0].Start,
+		Duration: 2 * time.Second,
+	}}, mavenLog.Modules())
+	assert.Empty(t, mavenLog.Errors())
+	assert.Equal(t, "", mavenLog.FailedModule())
+}
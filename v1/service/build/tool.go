@@ -0,0 +1,156 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Tool is the build tool of project.
+type Tool string
+
+const (
+	ToolMaven  Tool = "maven"
+	ToolGradle Tool = "gradle"
+)
+
+// gradleBizTask is the task of koupleless gradle plugin producing the biz bundle.
+const gradleBizTask = "bizJar"
+
+// gradleFiles are the files marking a gradle project.
+var gradleFiles = []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts", "gradlew"}
+
+// ParseTool parse the name of build tool, like maven, mvn, gradle.
+func ParseTool(name string) (Tool, error) {
+	switch name {
+	case "maven", "mvn":
+		return ToolMaven, nil
+	case "gradle":
+		return ToolGradle, nil
+	default:
+		return "", fmt.Errorf("unsupported build tool %s, should be one of maven and gradle", name)
+	}
+}
+
+// DetectTool return the build tool of project in dir, maven is preferred if both pom.xml and gradle files exist.
+func DetectTool(dir string) (Tool, error) {
+	if IsMavenProject(dir) {
+		return ToolMaven, nil
+	}
+	if IsGradleProject(dir) {
+		return ToolGradle, nil
+	}
+	return "", fmt.Errorf("%s is neither a maven project nor a gradle project", dir)
+}
+
+// IsGradleProject return true if there is a gradle build script, settings script or wrapper in dir.
+func IsGradleProject(dir string) bool {
+	for _, name := range gradleFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Options are the options of build invocation.
+type Options struct {
+	// Profiles are the maven profiles activated.
+	Profiles []string
+
+	// Args are the extra args passed to build tool as they are.
+	Args []string
+
+	// Offline build without accessing remote repositories.
+	Offline bool
+
+	// Settings is the path of maven settings.xml.
+	Settings string
+
+	// Modules are the directories of modules to build relative to project, the modules they depend on are also built.
+	// All modules are built if empty.
+	Modules []string
+
+	// AlsoMakeDependents build the modules depending on Modules as well, which is supported by maven only.
+	AlsoMakeDependents bool
+
+	// KeepGoing build the modules not depending on the failed ones, instead of stopping at the first failure.
+	KeepGoing bool
+}
+
+// Command return the executable and args building the biz bundles of project in dir, tests are skipped.
+// The maven and gradle wrappers of project are preferred over the installed ones.
+func (t Tool) Command(dir string, opts Options) (string, []string) {
+	switch t {
+	case ToolGradle:
+		var args []string
+		if len(opts.Modules) == 0 {
+			args = append(args, "clean", gradleBizTask)
+		}
+		for _, module := range opts.Modules {
+			// gradle builds the projects a task depends on by itself
+			projectPath := ":" + strings.ReplaceAll(strings.Trim(filepath.ToSlash(module), "/"), "/", ":")
+			args = append(args, projectPath+":clean", projectPath+":"+gradleBizTask)
+		}
+		args = append(args, "-x", "test")
+		if opts.Offline {
+			args = append(args, "--offline")
+		}
+		if opts.KeepGoing {
+			args = append(args, "--continue")
+		}
+		return wrapperOr(dir, "gradlew", "gradle"), append(args, opts.Args...)
+	default:
+		args := []string{"clean", "package", "-Dmaven.test.skip=true"}
+		if len(opts.Profiles) > 0 {
+			args = append(args, "-P", strings.Join(opts.Profiles, ","))
+		}
+		if opts.Offline {
+			args = append(args, "--offline")
+		}
+		if opts.Settings != "" {
+			args = append(args, "--settings", opts.Settings)
+		}
+		if len(opts.Modules) > 0 {
+			modules := make([]string, 0, len(opts.Modules))
+			for _, module := range opts.Modules {
+				modules = append(modules, filepath.ToSlash(module))
+			}
+			args = append(args, "--projects", strings.Join(modules, ","), "--also-make")
+			if opts.AlsoMakeDependents {
+				args = append(args, "--also-make-dependents")
+			}
+		}
+		if opts.KeepGoing {
+			args = append(args, "--fail-at-end")
+		}
+		return wrapperOr(dir, "mvnw", "mvn"), append(args, opts.Args...)
+	}
+}
+
+// wrapperOr return the path of wrapper script in dir if it exists, otherwise the executable.
+func wrapperOr(dir, wrapper, executable string) string {
+	if runtime.GOOS == "windows" {
+		wrapper += map[string]string{"mvnw": ".cmd", "gradlew": ".bat"}[wrapper]
+	}
+	if _, err := os.Stat(filepath.Join(dir, wrapper)); err == nil {
+		return filepath.Join(dir, wrapper)
+	}
+	return executable
+}
@@ -0,0 +1,100 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTool(t *testing.T) {
+	tool, err := DetectTool(mockMavenProject(t))
+	assert.Nil(t, err)
+	assert.Equal(t, ToolMaven, tool)
+
+	gradleDir := t.TempDir()
+	writeFile(t, filepath.Join(gradleDir, "build.gradle.kts"), "")
+	tool, err = DetectTool(gradleDir)
+	assert.Nil(t, err)
+	assert.Equal(t, ToolGradle, tool)
+
+	_, err = DetectTool(t.TempDir())
+	assert.NotNil(t, err)
+
+	tool, err = ParseTool("mvn")
+	assert.Nil(t, err)
+	assert.Equal(t, ToolMaven, tool)
+	_, err = ParseTool("ant")
+	assert.NotNil(t, err)
+}
+
+func TestCommand_Maven(t *testing.T) {
+	dir := mockMavenProject(t)
+	executable, args := ToolMaven.Command(dir, Options{})
+	assert.Equal(t, "mvn", executable)
+	assert.Equal(t, []string{"clean", "package", "-Dmaven.test.skip=true"}, args)
+
+	writeFile(t, filepath.Join(dir, "mvnw"), "")
+	writeFile(t, filepath.Join(dir, "mvnw.cmd"), "")
+	executable, args = ToolMaven.Command(dir, Options{
+		Profiles: []string{"dev", "fast"},
+		Args:     []string{"-T", "4"},
+		Offline:  true,
+		Settings: "/tmp/settings.xml",
+		Modules:  []string{filepath.Join("nested", "biz-b")},
+	})
+	assert.Contains(t, executable, filepath.Join(dir, "mvnw"))
+	assert.Equal(t, []string{
+		"clean", "package", "-Dmaven.test.skip=true",
+		"-P", "dev,fast",
+		"--offline",
+		"--settings", "/tmp/settings.xml",
+		"--projects", "nested/biz-b", "--also-make",
+		"-T", "4",
+	}, args)
+
+	_, args = ToolMaven.Command(dir, Options{
+		Modules:            []string{"biz-a", "shared"},
+		AlsoMakeDependents: true,
+		KeepGoing:          true,
+	})
+	assert.Equal(t, []string{
+		"clean", "package", "-Dmaven.test.skip=true",
+		"--projects", "biz-a,shared", "--also-make", "--also-make-dependents",
+		"--fail-at-end",
+	}, args)
+}
+
+func TestCommand_Gradle(t *testing.T) {
+	dir := t.TempDir()
+	executable, args := ToolGradle.Command(dir, Options{})
+	assert.Equal(t, "gradle", executable)
+	assert.Equal(t, []string{"clean", "bizJar", "-x", "test"}, args)
+
+	writeFile(t, filepath.Join(dir, "gradlew"), "")
+	writeFile(t, filepath.Join(dir, "gradlew.bat"), "")
+	executable, args = ToolGradle.Command(dir, Options{
+		Args:    []string{"--parallel"},
+		Offline: true,
+		Modules: []string{filepath.Join("modules", "biz-b")},
+	})
+	assert.Contains(t, executable, filepath.Join(dir, "gradlew"))
+	assert.Equal(t, []string{":modules:biz-b:clean", ":modules:biz-b:bizJar", "-x", "test", "--offline", "--parallel"}, args)
+
+	_, args = ToolGradle.Command(dir, Options{Modules: []string{"biz-a", "biz-b"}, KeepGoing: true})
+	assert.Equal(t, []string{":biz-a:clean", ":biz-a:bizJar", ":biz-b:clean", ":biz-b:bizJar", "-x", "test", "--continue"}, args)
+}
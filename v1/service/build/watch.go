@@ -0,0 +1,162 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher watches the build inputs of project, and reports the changes in batches.
+type Watcher struct {
+	dir      string
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+	changes  chan []string
+	errors   chan error
+}
+
+// Watch watch the build inputs in dir recursively, the same ones as Fingerprint.
+// The changes are reported once no more change happens in debounce, so that a burst of saves triggers one build.
+func Watch(dir string, debounce time.Duration) (*Watcher, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		dir:      dir,
+		debounce: debounce,
+		watcher:  watcher,
+		changes:  make(chan []string),
+		errors:   make(chan error, 1),
+	}
+	if err := w.addDirs(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Changes return the batches of changed files, in slash separated paths relative to project.
+// The channel is closed when the watcher is closed.
+func (w *Watcher) Changes() <-chan []string {
+	return w.changes
+}
+
+// Errors return the errors of watching, which are not fatal.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stop watching.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// addDirs watch root and the directories in it, since fsnotify is not recursive.
+func (w *Watcher) addDirs(root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		relative, relErr := filepath.Rel(w.dir, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// removed while walking
+			return nil
+		case err != nil:
+			return err
+		case !entry.IsDir():
+			return nil
+		case relErr != nil:
+			return relErr
+		case isSkippedDir(w.dir, filepath.ToSlash(relative)):
+			return filepath.SkipDir
+		}
+		return w.watcher.Add(path)
+	})
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+
+	pending := map[string]bool{}
+	var fire <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			relative, err := filepath.Rel(w.dir, event.Name)
+			if err != nil {
+				continue
+			}
+			relative = filepath.ToSlash(relative)
+
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				if event.Has(fsnotify.Create) && !isSkippedDir(w.dir, relative) {
+					// the files created before the new directory is watched are reported with the directory
+					if err := w.addDirs(event.Name); err != nil {
+						w.reportError(err)
+					}
+					pending[relative+"/"] = true
+					fire = time.After(w.debounce)
+				}
+				continue
+			}
+			if isBuildInput(relative) {
+				pending[relative] = true
+				fire = time.After(w.debounce)
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.reportError(err)
+
+		case <-fire:
+			changed := make([]string, 0, len(pending))
+			for path := range pending {
+				changed = append(changed, path)
+			}
+			sort.Strings(changed)
+			pending = map[string]bool{}
+			fire = nil
+			w.changes <- changed
+		}
+	}
+}
+
+// reportError report the error without blocking, the errors are dropped if nobody reads them.
+func (w *Watcher) reportError(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
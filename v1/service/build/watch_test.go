@@ -0,0 +1,73 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nextChanges(t *testing.T, w *Watcher) []string {
+	select {
+	case changed := <-w.Changes():
+		return changed
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+		return nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := mockMavenProject(t)
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "Foo.java"), "class Foo {}")
+
+	w, err := Watch(dir, 100*time.Millisecond)
+	assert.Nil(t, err)
+	defer w.Close()
+
+	// a burst of saves is reported in one batch
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "Foo.java"), "class Foo { int a; }")
+	writeFile(t, filepath.Join(dir, "biz-a", "pom.xml"), "<project/>")
+	assert.Equal(t, []string{"biz-a/pom.xml", "biz-a/src/main/java/Foo.java"}, nextChanges(t, w))
+
+	// build outputs and other files are ignored
+	writeFile(t, filepath.Join(dir, "biz-a", "target", "biz-a-1.0.0-ark-biz.jar"), "jar")
+	writeFile(t, filepath.Join(dir, "README.md"), "readme")
+	select {
+	case changed := <-w.Changes():
+		t.Fatalf("unexpected changes %v", changed)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// new directories are watched too
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "biz-a", "src", "main", "java", "bar"), 0755))
+	assert.Equal(t, []string{"biz-a/src/main/java/bar/"}, nextChanges(t, w))
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "bar", "Bar.java"), "class Bar {}")
+	assert.Equal(t, []string{"biz-a/src/main/java/bar/Bar.java"}, nextChanges(t, w))
+
+	// so are the packages named like build outputs
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "biz-a", "src", "main", "java", "build"), 0755))
+	assert.Equal(t, []string{"biz-a/src/main/java/build/"}, nextChanges(t, w))
+	writeFile(t, filepath.Join(dir, "biz-a", "src", "main", "java", "build", "Build.java"), "class Build {}")
+	assert.Equal(t, []string{"biz-a/src/main/java/build/Build.java"}, nextChanges(t, w))
+
+	assert.Nil(t, w.Close())
+	_, ok := <-w.Changes()
+	assert.False(t, ok)
+}
@@ -0,0 +1,236 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/classfile"
+	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/runtime"
+	"github.com/koupleless/arkctl/v1/service/ark"
+)
+
+// Options is the options of linting a biz bundle.
+type Options struct {
+	// IncludeLibs also lint the classes of embedded lib jars, by default only the classes of biz itself are linted.
+	IncludeLibs bool
+
+	// Suppress are the suppressed findings, in the format of {ruleId} or {ruleId}:{classNamePattern}.
+	Suppress []string
+}
+
+// Location is where the finding is found.
+type Location struct {
+	// Jar is the embedded lib jar containing the class, empty if the class belongs to biz itself.
+	Jar string `json:"jar,omitempty"`
+
+	// Class is the full qualified name of class.
+	Class string `json:"class"`
+
+	// Method is the name of method.
+	Method string `json:"method"`
+
+	// SourceFile is the path of source file relative to source root, e.g. com/foo/Foo.java
+	SourceFile string `json:"sourceFile,omitempty"`
+
+	// Line is the source line, 0 if the class is compiled without line numbers.
+	Line int `json:"line,omitempty"`
+}
+
+func (l Location) String() string {
+	location := l.Class + "." + l.Method
+	if l.Line > 0 {
+		location = fmt.Sprintf("%s(%s:%d)", location, path.Base(l.SourceFile), l.Line)
+	}
+	if l.Jar != "" {
+		location = location + " in " + l.Jar
+	}
+	return location
+}
+
+// Finding is an anti-pattern found in biz bundle.
+type Finding struct {
+	RuleID   string   `json:"ruleId"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Location Location `json:"location"`
+}
+
+// Report is the result of linting a biz bundle.
+type Report struct {
+	Bundle     string    `json:"bundle"`
+	Findings   []Finding `json:"findings"`
+	Suppressed int       `json:"suppressed"`
+}
+
+// Count return the number of findings at least as severe as given severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity.Rank() >= severity.Rank() {
+			count++
+		}
+	}
+	return count
+}
+
+// Lint scan the bytecode of biz bundle given by bizUrl and report koupleless anti-patterns.
+func Lint(ctx context.Context, bizUrl fileutil.FileUrl, opts Options) (report *Report, err error) {
+	defer runtime.RecoverFromError(&err)()
+	logger := contextutil.GetLogger(ctx)
+
+	bundle := runtime.MustReturnResult(ark.OpenBundle(ctx, bizUrl))
+	defer bundle.Close()
+
+	classes := runtime.MustReturnResult(readClasses(bundle, ""))
+	superClasses := map[string]string{}
+	for _, class := range classes {
+		superClasses[class.ThisClass] = class.SuperClass
+	}
+
+	report = &Report{Bundle: string(bizUrl), Findings: []Finding{}}
+	for _, class := range classes {
+		if class.jar != "" && !opts.IncludeLibs {
+			continue
+		}
+		for i := range class.Methods {
+			method := &class.Methods[i]
+			if method.Code == nil {
+				continue
+			}
+			instructions, err := method.Code.Instructions()
+			if err != nil {
+				logger.WithError(err).Warnf("skip malformed method %s.%s", class.ThisClass, method.Name)
+				continue
+			}
+
+			scope := &methodScope{
+				class:        class.ClassFile,
+				instructions: instructions,
+				isBundleClass: func(name string) bool {
+					_, ok := superClasses[name]
+					return ok
+				},
+				superClassOf: func(name string) string {
+					return superClasses[name]
+				},
+			}
+			for _, rule := range Rules {
+				for _, violation := range rule.check(scope) {
+					finding := Finding{
+						RuleID:   rule.ID,
+						Severity: rule.Severity,
+						Message:  violation.message,
+						Location: class.locate(method, violation.offset),
+					}
+					if isSuppressed(finding, opts.Suppress) {
+						report.Suppressed++
+						continue
+					}
+					report.Findings = append(report.Findings, finding)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Severity.Rank() > report.Findings[j].Severity.Rank()
+	})
+	return report, nil
+}
+
+// bundleClass is a class packaged in biz bundle.
+type bundleClass struct {
+	*classfile.ClassFile
+	jar string
+}
+
+// locate return the location of bytecode at offset in method.
+func (c *bundleClass) locate(method *classfile.Member, offset int) Location {
+	location := Location{
+		Jar:    c.jar,
+		Class:  javaName(c.ThisClass),
+		Method: method.Name,
+	}
+	if c.SourceFile != "" {
+		location.SourceFile = path.Join(path.Dir(c.ThisClass), c.SourceFile)
+	}
+	location.Line = method.Code.LineOf(offset)
+	return location
+}
+
+// readClasses parse all classes in bundle, including those in nested jars.
+func readClasses(fsys fs.FS, jar string) ([]*bundleClass, error) {
+	var classes []*bundleClass
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+
+		case entry.IsDir() || strings.HasPrefix(name, "META-INF/versions/") || path.Base(name) == "module-info.class":
+			return nil
+
+		case strings.HasSuffix(name, ".class"):
+			content, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			class, err := classfile.Parse(bytes.NewReader(content))
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			classes = append(classes, &bundleClass{ClassFile: class, jar: jar})
+
+		case strings.HasSuffix(name, ".jar") && jar == "":
+			nested, err := ark.OpenNestedJar(fsys, name)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", name, err)
+			}
+			nestedClasses, err := readClasses(nested, name)
+			if err != nil {
+				return err
+			}
+			classes = append(classes, nestedClasses...)
+		}
+		return nil
+	})
+	return classes, err
+}
+
+// isSuppressed return true if the finding matches any of suppressions
+// in the format of {ruleId} or {ruleId}:{classNamePattern}.
+func isSuppressed(finding Finding, suppressions []string) bool {
+	for _, suppression := range suppressions {
+		ruleID, classPattern, hasPattern := strings.Cut(strings.TrimSpace(suppression), ":")
+		if ruleID != finding.RuleID && ruleID != "*" {
+			continue
+		}
+		if !hasPattern {
+			return true
+		}
+		if matched, _ := path.Match(classPattern, finding.Location.Class); matched {
+			return true
+		}
+	}
+	return false
+}
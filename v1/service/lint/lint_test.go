@@ -0,0 +1,148 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/classfile"
+	"github.com/koupleless/arkctl/common/classfile/classfiletest"
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockBizJar(t *testing.T) fileutil.FileUrl {
+	worker := classfiletest.NewClass("com/biz/Worker", 52).
+		Method("start", "()V",
+			classfiletest.Member(classfile.OpInvokevirtual, "com/biz/WorkerThread", "start", "()V"),
+			classfiletest.Member(classfile.OpInvokestatic, "java/util/concurrent/Executors", "newFixedThreadPool", "(I)Ljava/util/concurrent/ExecutorService;"),
+			classfiletest.Member(classfile.OpInvokestatic, "java/util/concurrent/Executors", "newFixedThreadPool", "(ILjava/util/concurrent/ThreadFactory;)Ljava/util/concurrent/ExecutorService;"),
+		).
+		Method("startDaemon", "()V",
+			classfiletest.Op(classfile.OpIconst1),
+			classfiletest.Member(classfile.OpInvokevirtual, "java/lang/Thread", "setDaemon", "(Z)V"),
+			classfiletest.Member(classfile.OpInvokevirtual, "java/lang/Thread", "start", "()V"),
+		)
+
+	registry := classfiletest.NewClass("com/biz/Registry", 52).
+		Method("register", "()V",
+			classfiletest.Ldc("com.mysql.cj.jdbc.Driver"),
+			classfiletest.Member(classfile.OpInvokestatic, "java/lang/Class", "forName", "(Ljava/lang/String;)Ljava/lang/Class;"),
+			classfiletest.Member(classfile.OpInvokevirtual, "java/lang/Runtime", "addShutdownHook", "(Ljava/lang/Thread;)V"),
+			classfiletest.Member(classfile.OpGetstatic, "com/base/SharedCache", "CACHE", "Ljava/util/Map;"),
+			classfiletest.Member(classfile.OpInvokeinterface, "java/util/Map", "put", "(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;"),
+			classfiletest.Member(classfile.OpGetstatic, "com/biz/LocalCache", "CACHE", "Ljava/util/Map;"),
+			classfiletest.Member(classfile.OpInvokeinterface, "java/util/Map", "put", "(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;"),
+		)
+
+	lib := ziptest.Zip(t, map[string][]byte{
+		"com/lib/Hook.class": classfiletest.NewClass("com/lib/Hook", 52).
+			Method("install", "()V",
+				classfiletest.Member(classfile.OpInvokevirtual, "java/lang/Runtime", "addShutdownHook", "(Ljava/lang/Thread;)V"),
+			).Bytes(),
+	})
+
+	return ziptest.BizJar(t, "biz-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":       []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+		"com/biz/Worker.class":       worker.Bytes(),
+		"com/biz/WorkerThread.class": classfiletest.NewClass("com/biz/WorkerThread", 52).Extends("java/lang/Thread").Bytes(),
+		"com/biz/Registry.class":     registry.Bytes(),
+		"com/biz/LocalCache.class":   classfiletest.NewClass("com/biz/LocalCache", 52).Bytes(),
+		"lib/hook-1.0.0.jar":         lib,
+		"com/biz/application.yaml":   []byte("foo: bar"),
+	})
+}
+
+func ruleIDs(report *Report) []string {
+	ids := []string{}
+	for _, finding := range report.Findings {
+		ids = append(ids, finding.RuleID)
+	}
+	return ids
+}
+
+func TestLint(t *testing.T) {
+	report, err := Lint(context.Background(), mockBizJar(t), Options{})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		"non-daemon-thread",
+		"jdbc-driver-registration",
+		"shutdown-hook",
+		"default-thread-factory",
+		"static-cache-in-shared-class",
+	}, ruleIDs(report))
+	assert.Equal(t, 3, report.Count(SeverityError))
+	assert.Equal(t, 5, report.Count(SeverityWarning))
+
+	for _, finding := range report.Findings {
+		switch finding.RuleID {
+		case "non-daemon-thread":
+			assert.Equal(t, "com.biz.WorkerThread.start() is called without setDaemon(true)", finding.Message)
+			assert.Equal(t, Location{Class: "com.biz.Worker", Method: "start", SourceFile: "com/biz/Worker.java", Line: 1}, finding.Location)
+		case "static-cache-in-shared-class":
+			assert.Equal(t, "put is called on static field com.base.SharedCache.CACHE", finding.Message)
+			assert.Equal(t, 5, finding.Location.Line)
+		case "jdbc-driver-registration":
+			assert.Equal(t, "JDBC driver com.mysql.cj.jdbc.Driver is loaded by Class.forName", finding.Message)
+		}
+	}
+}
+
+func TestLint_IncludeLibsAndSuppress(t *testing.T) {
+	report, err := Lint(context.Background(), mockBizJar(t), Options{
+		IncludeLibs: true,
+		Suppress: []string{
+			"non-daemon-thread",
+			"shutdown-hook:com.biz.*",
+			"default-thread-factory:com.other.*",
+		},
+	})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		"jdbc-driver-registration",
+		"shutdown-hook",
+		"default-thread-factory",
+		"static-cache-in-shared-class",
+	}, ruleIDs(report))
+	assert.Equal(t, 2, report.Suppressed)
+
+	for _, finding := range report.Findings {
+		if finding.RuleID == "shutdown-hook" {
+			assert.Equal(t, "lib/hook-1.0.0.jar", finding.Location.Jar)
+		}
+	}
+}
+
+func TestWriteSarif(t *testing.T) {
+	report, err := Lint(context.Background(), mockBizJar(t), Options{})
+	assert.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, report.WriteSarif(buf, "src/main/java"))
+
+	sarif := &sarifLog{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	assert.Equal(t, len(Rules), len(sarif.Runs[0].Tool.Driver.Rules))
+	assert.Equal(t, len(report.Findings), len(sarif.Runs[0].Results))
+
+	result := sarif.Runs[0].Results[0]
+	assert.Equal(t, Rules[result.RuleIndex].ID, result.RuleID)
+	assert.Contains(t, result.Locations[0].PhysicalLocation.ArtifactLocation.URI, "src/main/java/com/biz/")
+}
@@ -0,0 +1,288 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/classfile"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Rank return the rank of severity, the higher the more severe.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityNote:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Rule is a koupleless anti-pattern which may leak memory when the biz module is uninstalled.
+type Rule struct {
+	ID          string   `json:"id"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	Help        string   `json:"help"`
+
+	// check report the violations in given method.
+	check func(scope *methodScope) []violation
+}
+
+// Rules are all the rules supported by arkctl lint.
+var Rules = []*Rule{
+	{
+		ID:          "non-daemon-thread",
+		Severity:    SeverityError,
+		Description: "Module starts a non-daemon thread.",
+		Help:        "Threads started by module keep the biz classloader alive after uninstall, make them daemon and stop them when the biz is stopped.",
+		check:       checkNonDaemonThread,
+	},
+	{
+		ID:          "default-thread-factory",
+		Severity:    SeverityWarning,
+		Description: "Module creates an executor with the default thread factory.",
+		Help:        "Executors created with the default thread factory start non-daemon threads, pass a daemon ThreadFactory and shutdown the executor when the biz is stopped.",
+		check:       checkDefaultThreadFactory,
+	},
+	{
+		ID:          "jdbc-driver-registration",
+		Severity:    SeverityError,
+		Description: "Module registers a JDBC driver.",
+		Help:        "DriverManager is shared with base, drivers registered by module must be deregistered when the biz is stopped.",
+		check:       checkJdbcDriverRegistration,
+	},
+	{
+		ID:          "shutdown-hook",
+		Severity:    SeverityError,
+		Description: "Module registers a jvm shutdown hook.",
+		Help:        "Shutdown hooks are held by the jvm until it exits, listen to biz uninstall events instead.",
+		check:       checkShutdownHook,
+	},
+	{
+		ID:          "static-cache-in-shared-class",
+		Severity:    SeverityWarning,
+		Description: "Module keeps state in a static field of a class shared with base.",
+		Help:        "Objects put into static fields of base classes outlive the module, clean them up when the biz is stopped.",
+		check:       checkStaticCacheInSharedClass,
+	},
+}
+
+// violation is where a rule is violated in method.
+type violation struct {
+	// offset is the bytecode offset of the violating instruction.
+	offset  int
+	message string
+}
+
+// methodScope is the method being checked.
+type methodScope struct {
+	class        *classfile.ClassFile
+	instructions []classfile.Instruction
+
+	// current is the index of instruction being checked.
+	current int
+
+	// isBundleClass return true if the class is packaged in biz bundle.
+	isBundleClass func(name string) bool
+
+	// superClassOf return the super class of class packaged in biz bundle.
+	superClassOf func(name string) string
+}
+
+// each call fn with every instruction in method, and collect the returned messages as violations.
+func (s *methodScope) each(fn func(instruction classfile.Instruction) string) []violation {
+	var violations []violation
+	for i, instruction := range s.instructions {
+		s.current = i
+		if message := fn(instruction); message != "" {
+			violations = append(violations, violation{offset: instruction.Offset, message: message})
+		}
+	}
+	return violations
+}
+
+func (s *methodScope) memberRef(instruction classfile.Instruction) (classfile.MemberRef, bool) {
+	if !instruction.IsInvoke() && !instruction.IsFieldAccess() {
+		return classfile.MemberRef{}, false
+	}
+	return s.class.MemberRef(instruction.Index())
+}
+
+// previous return the instruction before the one being checked.
+func (s *methodScope) previous() (classfile.Instruction, bool) {
+	if s.current == 0 {
+		return classfile.Instruction{}, false
+	}
+	return s.instructions[s.current-1], true
+}
+
+// isSubClassOf return true if class is or extends given super class.
+func (s *methodScope) isSubClassOf(class, super string) bool {
+	for depth := 0; class != "" && depth < 32; depth++ {
+		if class == super {
+			return true
+		}
+		class = s.superClassOf(class)
+	}
+	return false
+}
+
+// hasDaemonCall return true if the method calls setDaemon(true).
+func (s *methodScope) hasDaemonCall() bool {
+	for i, instruction := range s.instructions {
+		ref, ok := s.memberRef(instruction)
+		if ok && ref.Name == "setDaemon" && ref.Descriptor == "(Z)V" &&
+			i > 0 && s.instructions[i-1].Opcode == classfile.OpIconst1 {
+			return true
+		}
+	}
+	return false
+}
+
+func javaName(internalName string) string {
+	return strings.ReplaceAll(internalName, "/", ".")
+}
+
+func checkNonDaemonThread(scope *methodScope) []violation {
+	if scope.hasDaemonCall() {
+		return nil
+	}
+	return scope.each(func(instruction classfile.Instruction) string {
+		ref, ok := scope.memberRef(instruction)
+		switch {
+		case !ok:
+			return ""
+		case ref.Name == "start" && ref.Descriptor == "()V" && scope.isSubClassOf(ref.Owner, "java/lang/Thread"):
+			return fmt.Sprintf("%s.start() is called without setDaemon(true)", javaName(ref.Owner))
+		case ref.Owner == "java/util/Timer" && ref.Name == "<init>" &&
+			(ref.Descriptor == "()V" || ref.Descriptor == "(Ljava/lang/String;)V"):
+			return "java.util.Timer is created with a non-daemon thread"
+		default:
+			return ""
+		}
+	})
+}
+
+var executorFactories = map[string]bool{
+	"newFixedThreadPool":               true,
+	"newCachedThreadPool":              true,
+	"newSingleThreadExecutor":          true,
+	"newScheduledThreadPool":           true,
+	"newSingleThreadScheduledExecutor": true,
+}
+
+func checkDefaultThreadFactory(scope *methodScope) []violation {
+	return scope.each(func(instruction classfile.Instruction) string {
+		ref, ok := scope.memberRef(instruction)
+		if ok && ref.Owner == "java/util/concurrent/Executors" && executorFactories[ref.Name] &&
+			!strings.Contains(ref.Descriptor, "Ljava/util/concurrent/ThreadFactory;") {
+			return fmt.Sprintf("Executors.%s is called without a ThreadFactory", ref.Name)
+		}
+		return ""
+	})
+}
+
+func checkJdbcDriverRegistration(scope *methodScope) []violation {
+	return scope.each(func(instruction classfile.Instruction) string {
+		ref, ok := scope.memberRef(instruction)
+		switch {
+		case !ok:
+			return ""
+		case ref.Owner == "java/sql/DriverManager" && ref.Name == "registerDriver":
+			return "DriverManager.registerDriver is called"
+		case ref.Owner == "java/lang/Class" && ref.Name == "forName":
+			previous, ok := scope.previous()
+			if !ok || (previous.Opcode != classfile.OpLdc && previous.Opcode != classfile.OpLdcW) {
+				return ""
+			}
+			if driver := scope.class.String(previous.Index()); strings.HasSuffix(driver, "Driver") {
+				return fmt.Sprintf("JDBC driver %s is loaded by Class.forName", driver)
+			}
+		}
+		return ""
+	})
+}
+
+func checkShutdownHook(scope *methodScope) []violation {
+	return scope.each(func(instruction classfile.Instruction) string {
+		ref, ok := scope.memberRef(instruction)
+		if ok && ref.Owner == "java/lang/Runtime" && ref.Name == "addShutdownHook" {
+			return "Runtime.addShutdownHook is called"
+		}
+		return ""
+	})
+}
+
+var mutatingMethods = map[string]bool{
+	"put":             true,
+	"putAll":          true,
+	"putIfAbsent":     true,
+	"computeIfAbsent": true,
+	"compute":         true,
+	"merge":           true,
+	"add":             true,
+	"addAll":          true,
+	"offer":           true,
+	"push":            true,
+}
+
+// isContainerDescriptor return true if the field descriptor is a collection or cache type.
+func isContainerDescriptor(descriptor string) bool {
+	if !strings.HasPrefix(descriptor, "L") {
+		return false
+	}
+	for _, suffix := range []string{"Map;", "List;", "Set;", "Collection;", "Queue;", "Cache;"} {
+		if strings.HasSuffix(descriptor, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkStaticCacheInSharedClass(scope *methodScope) []violation {
+	// the last container read from static field of shared class
+	var container *classfile.MemberRef
+	return scope.each(func(instruction classfile.Instruction) string {
+		ref, ok := scope.memberRef(instruction)
+		switch {
+		case !ok:
+			return ""
+		case instruction.Opcode == classfile.OpPutstatic && !scope.isBundleClass(ref.Owner):
+			return fmt.Sprintf("static field %s.%s is assigned", javaName(ref.Owner), ref.Name)
+		case instruction.Opcode == classfile.OpGetstatic && !scope.isBundleClass(ref.Owner) && isContainerDescriptor(ref.Descriptor):
+			container = &ref
+		case instruction.IsInvoke() && mutatingMethods[ref.Name] && container != nil:
+			message := fmt.Sprintf("%s is called on static field %s.%s", ref.Name, javaName(container.Owner), container.Name)
+			container = nil
+			return message
+		}
+		return ""
+	})
+}
@@ -0,0 +1,80 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/koupleless/arkctl/common/cmdutil"
+	"github.com/koupleless/arkctl/common/contextutil"
+)
+
+// CommandStage is a stage running a command in Dir, e.g. the tests or lint configured by project.
+type CommandStage struct {
+	StageName string
+	Dir       string
+	Command   []string
+
+	// Output is called with each line of stdout, the output is discarded if nil.
+	Output func(line string)
+}
+
+func (c *CommandStage) Name() string {
+	return c.StageName
+}
+
+func (c *CommandStage) Inputs() []Ref {
+	return nil
+}
+
+func (c *CommandStage) Outputs() []Ref {
+	return nil
+}
+
+func (c *CommandStage) Skip(_ *contextutil.Context) string {
+	return ""
+}
+
+func (c *CommandStage) Plan(_ *contextutil.Context) ([]string, error) {
+	return []string{fmt.Sprintf("run %s in %s", strings.Join(c.Command, " "), c.Dir)}, nil
+}
+
+func (c *CommandStage) Run(ctx *contextutil.Context) error {
+	if len(c.Command) == 0 {
+		return errors.New("no command configured")
+	}
+	command := cmdutil.BuildCommandWithWorkDir(ctx, c.Dir, c.Command[0], c.Command[1:]...)
+	if err := command.Exec(); err != nil {
+		return err
+	}
+	for line := range command.Output() {
+		if c.Output != nil {
+			c.Output(line)
+		}
+	}
+
+	// the stderr of command is reported as error, but only the exit code tells whether it fails
+	stderr := &strings.Builder{}
+	for err := range command.Wait() {
+		stderr.WriteString(err.Error())
+	}
+	<-command.Done()
+	if err := command.GetExitError(); err != nil {
+		return fmt.Errorf("%s: %s%s", command, err, stderr)
+	}
+	return nil
+}
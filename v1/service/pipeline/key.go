@@ -0,0 +1,63 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pipeline
+
+import (
+	"context"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+)
+
+// Ref is a Key of any type, which is used to declare the inputs and outputs of stage.
+type Ref interface {
+	// String return the name of key.
+	String() string
+
+	// Present return true if the value of key is in ctx.
+	Present(ctx context.Context) bool
+}
+
+// Key is a typed key of the value shared by stages through context, e.g.
+//
+//	var KeyBizModel = pipeline.Key[*ark.BizModel]("ark.BizModel")
+type Key[T any] string
+
+// Get return the value of key in ctx, and whether it is present.
+func (k Key[T]) Get(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(k).(T)
+	return value, ok
+}
+
+// MustGet return the value of key in ctx, it panics if the value is not present,
+// which is prevented by declaring the key as an input of stage.
+func (k Key[T]) MustGet(ctx context.Context) T {
+	return ctx.Value(k).(T)
+}
+
+// Put set the value of key in ctx.
+func (k Key[T]) Put(ctx *contextutil.Context, value T) {
+	ctx.Put(k, value)
+}
+
+// Present return true if the value of key is in ctx.
+func (k Key[T]) Present(ctx context.Context) bool {
+	_, ok := k.Get(ctx)
+	return ok
+}
+
+// String return the name of key.
+func (k Key[T]) String() string {
+	return string(k)
+}
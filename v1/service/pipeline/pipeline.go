@@ -0,0 +1,321 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+)
+
+// Stage is a step of pipeline.
+type Stage interface {
+	// Name is the unique name of stage, e.g. build
+	Name() string
+
+	// Inputs are the keys the stage requires, which are put into context by the stages before.
+	Inputs() []Ref
+
+	// Outputs are the keys the stage puts into context.
+	Outputs() []Ref
+
+	// Skip return why the stage is skipped in ctx, or empty if the stage should run.
+	Skip(ctx *contextutil.Context) string
+
+	// Run execute the stage.
+	Run(ctx *contextutil.Context) error
+}
+
+// Planner is a Stage which could tell what it will do without doing it, for dry run.
+type Planner interface {
+	// Plan return the operations the stage will do. It changes nothing,
+	// but puts the outputs resolved without side effect into context for the stages after.
+	Plan(ctx *contextutil.Context) ([]string, error)
+}
+
+// Func is a Stage defined by functions, SkipFunc and PlanFunc are optional.
+type Func struct {
+	StageName string
+	In        []Ref
+	Out       []Ref
+	SkipFunc  func(ctx *contextutil.Context) string
+	RunFunc   func(ctx *contextutil.Context) error
+	PlanFunc  func(ctx *contextutil.Context) ([]string, error)
+}
+
+func (f *Func) Name() string {
+	return f.StageName
+}
+
+func (f *Func) Inputs() []Ref {
+	return f.In
+}
+
+func (f *Func) Outputs() []Ref {
+	return f.Out
+}
+
+func (f *Func) Skip(ctx *contextutil.Context) string {
+	if f.SkipFunc == nil {
+		return ""
+	}
+	return f.SkipFunc(ctx)
+}
+
+func (f *Func) Run(ctx *contextutil.Context) error {
+	return f.RunFunc(ctx)
+}
+
+func (f *Func) Plan(ctx *contextutil.Context) ([]string, error) {
+	if f.PlanFunc == nil {
+		return nil, nil
+	}
+	return f.PlanFunc(ctx)
+}
+
+// Status is the status of stage after the pipeline runs.
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusSkipped   Status = "skipped"
+	StatusPlanned   Status = "planned"
+
+	// StatusNotRun is the status of stages after the failed one.
+	StatusNotRun Status = "not-run"
+)
+
+// Result is the result of a stage.
+type Result struct {
+	Stage    string
+	Status   Status
+	Reason   string   // why the stage is skipped
+	Plan     []string // the operations planned in dry run
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Report is the results of all stages in order.
+type Report struct {
+	Results  []Result
+	Duration time.Duration
+}
+
+// Succeeded return true if no stage failed.
+func (r *Report) Succeeded() bool {
+	return r.Failed() == nil
+}
+
+// Failed return the result of failed stage, or nil if no stage failed.
+func (r *Report) Failed() *Result {
+	for i := range r.Results {
+		if r.Results[i].Status == StatusFailed {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// Pipeline runs stages in order, and stops at the first failed one.
+type Pipeline struct {
+	stages []Stage
+	only   map[string]bool
+	skip   map[string]bool
+
+	// OnStart is called before a stage runs, skipped stages excluded.
+	OnStart func(stage Stage)
+
+	// OnFinish is called after a stage runs or is skipped.
+	OnFinish func(result Result)
+}
+
+// New return a pipeline of stages.
+func New(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Stages return the stages in order.
+func (p *Pipeline) Stages() []Stage {
+	return p.stages
+}
+
+// Insert add stage right after the stage named after, or to the end if after is empty.
+func (p *Pipeline) Insert(stage Stage, after string) error {
+	if p.index(stage.Name()) >= 0 {
+		return fmt.Errorf("duplicated stage %s", stage.Name())
+	}
+	if after == "" {
+		p.stages = append(p.stages, stage)
+		return nil
+	}
+
+	i := p.index(after)
+	if i < 0 {
+		return fmt.Errorf("stage %s runs after unknown stage %s, should be one of %s", stage.Name(), after, p.names())
+	}
+	p.stages = append(p.stages[:i+1], append([]Stage{stage}, p.stages[i+1:]...)...)
+	return nil
+}
+
+// Select run only the given stages if only is not empty, and skip the given ones.
+func (p *Pipeline) Select(only, skip []string) error {
+	p.only, p.skip = map[string]bool{}, map[string]bool{}
+	for _, selection := range []struct {
+		names    []string
+		selected map[string]bool
+	}{{only, p.only}, {skip, p.skip}} {
+		for _, name := range selection.names {
+			if p.index(name) < 0 {
+				return fmt.Errorf("unknown stage %s, should be one of %s", name, p.names())
+			}
+			selection.selected[name] = true
+		}
+	}
+	return nil
+}
+
+// Split return the pipeline of stages before the stage named name, and the pipeline of the rest,
+// both with the selection and hooks of p. The rest is empty if there is no such stage.
+func (p *Pipeline) Split(name string) (*Pipeline, *Pipeline) {
+	i := p.index(name)
+	if i < 0 {
+		i = len(p.stages)
+	}
+	head, tail := *p, *p
+	head.stages = append([]Stage(nil), p.stages[:i]...)
+	tail.stages = append([]Stage(nil), p.stages[i:]...)
+	return &head, &tail
+}
+
+// Run execute the stages in order, the stages after the failed one are not run.
+func (p *Pipeline) Run(ctx *contextutil.Context) *Report {
+	return p.execute(ctx, StatusSucceeded, func(stage Stage) ([]string, error) {
+		return nil, stage.Run(ctx)
+	})
+}
+
+// Plan tell what the stages will do in order without doing it, the stages not implementing Planner are skipped.
+func (p *Pipeline) Plan(ctx *contextutil.Context) *Report {
+	return p.execute(ctx, StatusPlanned, func(stage Stage) ([]string, error) {
+		planner, ok := stage.(Planner)
+		if !ok {
+			return []string{"can not be planned"}, nil
+		}
+		return planner.Plan(ctx)
+	})
+}
+
+// execute do the selected stages in order, stop at the failed one.
+func (p *Pipeline) execute(ctx *contextutil.Context, done Status, do func(stage Stage) ([]string, error)) *Report {
+	report := &Report{}
+	start := time.Now()
+	failed := false
+	for i, stage := range p.stages {
+		result := Result{Stage: stage.Name(), Start: time.Now()}
+		switch {
+		case failed:
+			result.Status = StatusNotRun
+		case len(p.only) > 0 && !p.only[stage.Name()]:
+			result.Status, result.Reason = StatusSkipped, "not selected"
+		case p.skip[stage.Name()]:
+			result.Status, result.Reason = StatusSkipped, "skipped by user"
+		default:
+			if result.Reason = stage.Skip(ctx); result.Reason != "" {
+				result.Status = StatusSkipped
+				break
+			}
+
+			if p.OnStart != nil {
+				p.OnStart(stage)
+			}
+			result.Err = p.checkInputs(ctx, i)
+			if result.Err == nil {
+				result.Err = ctx.Err()
+			}
+			if result.Err == nil {
+				result.Plan, result.Err = do(stage)
+			}
+			result.Duration = time.Since(result.Start)
+			result.Status = done
+			if result.Err != nil {
+				result.Status, failed = StatusFailed, true
+			}
+		}
+
+		report.Results = append(report.Results, result)
+		if p.OnFinish != nil {
+			p.OnFinish(result)
+		}
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// MissingInputError is the error of running a stage without its input, usually because its provider is not selected.
+type MissingInputError struct {
+	Stage string
+	Input string
+
+	// Provider is the stage before providing the input, empty if there is none.
+	Provider string
+}
+
+func (e *MissingInputError) Error() string {
+	if e.Provider == "" {
+		return fmt.Sprintf("stage %s requires %s, which is not provided by any stage before", e.Stage, e.Input)
+	}
+	return fmt.Sprintf("stage %s requires %s, which is provided by stage %s", e.Stage, e.Input, e.Provider)
+}
+
+// checkInputs return an error telling which stage provides the missing input of the i-th stage.
+func (p *Pipeline) checkInputs(ctx *contextutil.Context, i int) error {
+	stage := p.stages[i]
+	for _, input := range stage.Inputs() {
+		if input.Present(ctx) {
+			continue
+		}
+		missing := &MissingInputError{Stage: stage.Name(), Input: input.String()}
+		for _, provider := range p.stages[:i] {
+			for _, output := range provider.Outputs() {
+				if output.String() == input.String() && missing.Provider == "" {
+					missing.Provider = provider.Name()
+				}
+			}
+		}
+		return missing
+	}
+	return nil
+}
+
+func (p *Pipeline) index(name string) int {
+	for i, stage := range p.stages {
+		if stage.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Pipeline) names() string {
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.Name())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,232 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koupleless/arkctl/common/contextutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var keyGreeting = Key[string]("greeting")
+
+func recordingStage(name string, ran *[]string, in, out []Ref, run func(ctx *contextutil.Context) error) Stage {
+	return &Func{
+		StageName: name,
+		In:        in,
+		Out:       out,
+		RunFunc: func(ctx *contextutil.Context) error {
+			*ran = append(*ran, name)
+			if run != nil {
+				return run(ctx)
+			}
+			return nil
+		},
+	}
+}
+
+func statuses(report *Report) map[string]Status {
+	result := map[string]Status{}
+	for _, r := range report.Results {
+		result[r.Stage] = r.Status
+	}
+	return result
+}
+
+func TestKey(t *testing.T) {
+	ctx := contextutil.NewContext(context.Background())
+	_, ok := keyGreeting.Get(ctx)
+	assert.False(t, ok)
+	assert.False(t, keyGreeting.Present(ctx))
+
+	keyGreeting.Put(ctx, "hello")
+	assert.Equal(t, "hello", keyGreeting.MustGet(ctx))
+	assert.True(t, keyGreeting.Present(ctx))
+
+	// keys of the same name but different types do not collide with plain string keys
+	assert.Nil(t, ctx.Value("greeting"))
+}
+
+func TestPipeline_Run(t *testing.T) {
+	var ran []string
+	p := New(
+		recordingStage("produce", &ran, nil, []Ref{keyGreeting}, func(ctx *contextutil.Context) error {
+			keyGreeting.Put(ctx, "hello")
+			return nil
+		}),
+		&Func{StageName: "optional", SkipFunc: func(*contextutil.Context) string { return "not configured" }},
+		recordingStage("consume", &ran, []Ref{keyGreeting}, nil, nil),
+		recordingStage("fail", &ran, nil, nil, func(*contextutil.Context) error { return errors.New("boom") }),
+		recordingStage("after", &ran, nil, nil, nil),
+	)
+	var started []string
+	p.OnStart = func(stage Stage) { started = append(started, stage.Name()) }
+
+	report := p.Run(contextutil.NewContext(context.Background()))
+	assert.Equal(t, []string{"produce", "consume", "fail"}, ran)
+	assert.Equal(t, []string{"produce", "consume", "fail"}, started)
+	assert.Equal(t, map[string]Status{
+		"produce":  StatusSucceeded,
+		"optional": StatusSkipped,
+		"consume":  StatusSucceeded,
+		"fail":     StatusFailed,
+		"after":    StatusNotRun,
+	}, statuses(report))
+	assert.Equal(t, "not configured", report.Results[1].Reason)
+	assert.False(t, report.Succeeded())
+	assert.Equal(t, "fail", report.Failed().Stage)
+	assert.EqualError(t, report.Failed().Err, "boom")
+}
+
+func TestPipeline_Select(t *testing.T) {
+	var ran []string
+	newPipeline := func() *Pipeline {
+		ran = nil
+		return New(
+			recordingStage("produce", &ran, nil, []Ref{keyGreeting}, func(ctx *contextutil.Context) error {
+				keyGreeting.Put(ctx, "hello")
+				return nil
+			}),
+			recordingStage("consume", &ran, []Ref{keyGreeting}, nil, nil),
+			recordingStage("other", &ran, nil, nil, nil),
+		)
+	}
+
+	p := newPipeline()
+	assert.Nil(t, p.Select(nil, []string{"other"}))
+	report := p.Run(contextutil.NewContext(context.Background()))
+	assert.True(t, report.Succeeded())
+	assert.Equal(t, []string{"produce", "consume"}, ran)
+	assert.Equal(t, StatusSkipped, statuses(report)["other"])
+
+	// the missing input tells which stage provides it
+	p = newPipeline()
+	assert.Nil(t, p.Select([]string{"consume"}, nil))
+	report = p.Run(contextutil.NewContext(context.Background()))
+	assert.Empty(t, ran)
+	assert.EqualError(t, report.Failed().Err, "stage consume requires greeting, which is provided by stage produce")
+	assert.ErrorAs(t, report.Failed().Err, new(*MissingInputError))
+
+	p = newPipeline()
+	assert.EqualError(t, p.Select([]string{"unknown"}, nil), "unknown stage unknown, should be one of produce, consume, other")
+}
+
+func TestPipeline_Insert(t *testing.T) {
+	var ran []string
+	p := New(recordingStage("a", &ran, nil, nil, nil), recordingStage("c", &ran, nil, nil, nil))
+	assert.Nil(t, p.Insert(recordingStage("b", &ran, nil, nil, nil), "a"))
+	assert.Nil(t, p.Insert(recordingStage("d", &ran, nil, nil, nil), ""))
+	assert.EqualError(t, p.Insert(recordingStage("a", &ran, nil, nil, nil), ""), "duplicated stage a")
+	assert.Error(t, p.Insert(recordingStage("e", &ran, nil, nil, nil), "unknown"))
+
+	p.Run(contextutil.NewContext(context.Background()))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ran)
+}
+
+func TestPipeline_Split(t *testing.T) {
+	var ran []string
+	p := New(
+		recordingStage("a", &ran, nil, nil, nil),
+		recordingStage("b", &ran, nil, nil, nil),
+		recordingStage("c", &ran, nil, nil, nil),
+	)
+	assert.Nil(t, p.Select(nil, []string{"c"}))
+
+	head, tail := p.Split("b")
+	head.Run(contextutil.NewContext(context.Background()))
+	assert.Equal(t, []string{"a"}, ran)
+	report := tail.Run(contextutil.NewContext(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, ran)
+	assert.Equal(t, map[string]Status{"b": StatusSucceeded, "c": StatusSkipped}, statuses(report))
+
+	head, tail = p.Split("unknown")
+	assert.Len(t, head.Stages(), 3)
+	assert.Empty(t, tail.Stages())
+}
+
+func TestPipeline_Cancelled(t *testing.T) {
+	var ran []string
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := New(recordingStage("a", &ran, nil, nil, nil)).Run(contextutil.NewContext(cancelled))
+	assert.Empty(t, ran)
+	assert.ErrorIs(t, report.Failed().Err, context.Canceled)
+}
+
+func TestPipeline_Plan(t *testing.T) {
+	var ran []string
+	p := New(
+		&Func{
+			StageName: "produce",
+			Out:       []Ref{keyGreeting},
+			RunFunc: func(*contextutil.Context) error {
+				ran = append(ran, "produce")
+				return nil
+			},
+			PlanFunc: func(ctx *contextutil.Context) ([]string, error) {
+				keyGreeting.Put(ctx, "hello")
+				return []string{"say hello"}, nil
+			},
+		},
+		&Func{
+			StageName: "consume",
+			In:        []Ref{keyGreeting},
+			RunFunc: func(*contextutil.Context) error {
+				ran = append(ran, "consume")
+				return nil
+			},
+			PlanFunc: func(ctx *contextutil.Context) ([]string, error) {
+				return []string{"hear " + keyGreeting.MustGet(ctx)}, nil
+			},
+		},
+		&CommandStage{StageName: "echo", Dir: "/tmp", Command: []string{"echo", "hi"}},
+	)
+
+	report := p.Plan(contextutil.NewContext(context.Background()))
+	assert.Empty(t, ran)
+	assert.True(t, report.Succeeded())
+	assert.Equal(t, map[string]Status{"produce": StatusPlanned, "consume": StatusPlanned, "echo": StatusPlanned}, statuses(report))
+	assert.Equal(t, []string{"say hello"}, report.Results[0].Plan)
+	assert.Equal(t, []string{"hear hello"}, report.Results[1].Plan)
+	assert.Equal(t, []string{"run echo hi in /tmp"}, report.Results[2].Plan)
+}
+
+func TestCommandStage(t *testing.T) {
+	var output []string
+	stage := &CommandStage{
+		StageName: "echo",
+		Dir:       t.TempDir(),
+		Command:   []string{"sh", "-c", "echo hello"},
+		Output:    func(line string) { output = append(output, line) },
+	}
+	assert.Nil(t, stage.Run(contextutil.NewContext(context.Background())))
+	assert.Equal(t, []string{"hello"}, output)
+
+	stage.Command = []string{"sh", "-c", "exit 3"}
+	assert.Error(t, stage.Run(contextutil.NewContext(context.Background())))
+
+	// the stderr finishes waiting before the command exits, which still fails by its exit code
+	stage.Command = []string{"sh", "-c", "echo failed >&2; exit 1"}
+	for i := 0; i < 50; i++ {
+		err := stage.Run(contextutil.NewContext(context.Background()))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "exit status 1")
+			assert.Contains(t, err.Error(), "failed")
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Getter send a GET request to url, return the status code of response.
+type Getter func(ctx context.Context, url string) (int, error)
+
+// Probe is a url of biz probed until it responds with one of the expected status.
+type Probe struct {
+	Url      string
+	Status   []int
+	Timeout  time.Duration // 0 probes until ctx is done
+	Interval time.Duration // between the retries
+}
+
+// Url return the url of path under the web context path of biz, served on host:port.
+func Url(host string, port int, webContextPath, path string) string {
+	var segments []string
+	for _, segment := range []string{webContextPath, path} {
+		if segment = strings.Trim(segment, "/"); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return fmt.Sprintf("http://%s:%d/%s", host, port, strings.Join(segments, "/"))
+}
+
+// Check GET the url of probe until it responds with one of the expected status,
+// return the error telling the last response if it does not in time.
+func Check(ctx context.Context, get Getter, probe Probe) error {
+	if probe.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, probe.Timeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	for {
+		status, err := get(ctx, probe.Url)
+		switch {
+		case err == nil && slices.Contains(probe.Status, status):
+			return nil
+		case err == nil:
+			lastErr = fmt.Errorf("responds %d, expecting %s", status, strings.Trim(fmt.Sprint(probe.Status), "[]"))
+		case ctx.Err() == nil || lastErr == nil:
+			// the request cancelled by timeout tells nothing new
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("probe %s failed in %s: %w", probe.Url, probe.Timeout, lastErr)
+		case <-time.After(probe.Interval):
+		}
+	}
+}
+
+// HttpGet is the Getter sending the request from local.
+func HttpGet(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
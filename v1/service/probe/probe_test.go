@@ -0,0 +1,87 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrl(t *testing.T) {
+	assert.Equal(t, "http://127.0.0.1:8080/biz1/actuator/health", Url("127.0.0.1", 8080, "biz1", "/actuator/health"))
+	assert.Equal(t, "http://127.0.0.1:8080/biz1/actuator/health", Url("127.0.0.1", 8080, "/biz1/", "actuator/health"))
+	assert.Equal(t, "http://127.0.0.1:8080/actuator/health", Url("127.0.0.1", 8080, "/", "/actuator/health"))
+	assert.Equal(t, "http://127.0.0.1:8080/biz1", Url("127.0.0.1", 8080, "biz1", ""))
+}
+
+func TestCheck(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the biz is starting for the first two requests
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := Check(context.Background(), HttpGet, Probe{
+		Url:      server.URL + "/biz1/actuator/health",
+		Status:   []int{http.StatusOK},
+		Timeout:  5 * time.Second,
+		Interval: time.Millisecond,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCheck_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	url := server.URL + "/biz1/actuator/health"
+	err := Check(context.Background(), HttpGet, Probe{
+		Url:      url,
+		Status:   []int{http.StatusOK, http.StatusNoContent},
+		Timeout:  50 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	})
+	assert.EqualError(t, err, "probe "+url+" failed in 50ms: responds 404, expecting 200 204")
+}
+
+func TestCheck_Unreachable(t *testing.T) {
+	get := func(ctx context.Context, url string) (int, error) {
+		return 0, &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	}
+	err := Check(context.Background(), get, Probe{
+		Url:      "http://127.0.0.1:8080/biz1",
+		Status:   []int{http.StatusOK},
+		Timeout:  20 * time.Millisecond,
+		Interval: 5 * time.Millisecond,
+	})
+	assert.EqualError(t, err, "probe http://127.0.0.1:8080/biz1 failed in 20ms: dial: connection refused")
+	assert.True(t, errors.As(err, new(*net.OpError)))
+}
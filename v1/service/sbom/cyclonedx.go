@@ -0,0 +1,128 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/koupleless/arkctl/v1/constant"
+
+	"github.com/google/uuid"
+)
+
+// cyclonedx json format, see https://cyclonedx.org/docs/1.5/json/
+type cyclonedxBom struct {
+	BomFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cyclonedxMetadata     `json:"metadata"`
+	Components   []cyclonedxComponent  `json:"components"`
+	Dependencies []cyclonedxDependency `json:"dependencies"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cyclonedxTools     `json:"tools"`
+	Component cyclonedxComponent `json:"component"`
+}
+
+type cyclonedxTools struct {
+	Components []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxComponent struct {
+	Type       string              `json:"type"`
+	BomRef     string              `json:"bom-ref,omitempty"`
+	Group      string              `json:"group,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Purl       string              `json:"purl,omitempty"`
+	Hashes     []cyclonedxHash     `json:"hashes,omitempty"`
+	Properties []cyclonedxProperty `json:"properties,omitempty"`
+}
+
+type cyclonedxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cyclonedxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cyclonedxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func (c Component) cyclonedx(componentType, bomRef string) cyclonedxComponent {
+	component := cyclonedxComponent{
+		Type:    componentType,
+		BomRef:  bomRef,
+		Group:   c.Group,
+		Name:    c.Name,
+		Version: c.Version,
+		Purl:    c.Purl(),
+	}
+	if c.Sha1 != "" {
+		component.Hashes = append(component.Hashes, cyclonedxHash{Alg: "SHA-1", Content: c.Sha1})
+	}
+	if c.Sha256 != "" {
+		component.Hashes = append(component.Hashes, cyclonedxHash{Alg: "SHA-256", Content: c.Sha256})
+	}
+	if c.File != "" {
+		component.Properties = append(component.Properties, cyclonedxProperty{Name: "arkctl:file", Value: c.File})
+	}
+	if c.IdentifiedBy != "" {
+		component.Properties = append(component.Properties, cyclonedxProperty{Name: "arkctl:identifiedBy", Value: string(c.IdentifiedBy)})
+	}
+	return component
+}
+
+// WriteCycloneDX write the SBOM in CycloneDX 1.5 json format.
+func (s *SBOM) WriteCycloneDX(w io.Writer) error {
+	rootRef := "biz:" + s.Root.Name
+	bom := cyclonedxBom{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: cyclonedxMetadata{
+			Timestamp: s.Created.Format(time.RFC3339),
+			Tools: cyclonedxTools{Components: []cyclonedxComponent{{
+				Type:    "application",
+				Name:    "arkctl",
+				Version: constant.Version,
+			}}},
+			Component: s.Root.cyclonedx("application", rootRef),
+		},
+		Components:   make([]cyclonedxComponent, 0, len(s.Components)),
+		Dependencies: []cyclonedxDependency{{Ref: rootRef}},
+	}
+	for _, component := range s.Components {
+		// the file is unique in bundle, while the same artifact might be embedded twice
+		ref := "lib:" + component.File
+		bom.Components = append(bom.Components, component.cyclonedx("library", ref))
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, ref)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
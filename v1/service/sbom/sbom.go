@@ -0,0 +1,218 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/runtime"
+	"github.com/koupleless/arkctl/v1/service/ark"
+)
+
+// Format is the output format of SBOM.
+type Format string
+
+const (
+	FormatCycloneDXJson Format = "cyclonedx-json"
+	FormatSpdxJson      Format = "spdx-json"
+)
+
+// Identification is how a component is identified.
+type Identification string
+
+const (
+	// IdentifiedByPom means the component is identified by META-INF/maven/*/*/pom.properties in jar.
+	IdentifiedByPom Identification = "pom.properties"
+
+	// IdentifiedByHash means the jar has no maven metadata, the component could only be identified by its hashes,
+	// the name and version are guessed from the file name.
+	IdentifiedByHash Identification = "hash"
+)
+
+// Component is a software component in biz bundle.
+type Component struct {
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+
+	// File is the slash separated path of jar in bundle, e.g. lib/foo-1.0.0.jar, empty for the biz itself.
+	File string `json:"file,omitempty"`
+
+	// Sha1 and Sha256 are the hex encoded hashes of jar, Sha1 is what maven central searches by.
+	Sha1   string `json:"sha1,omitempty"`
+	Sha256 string `json:"sha256,omitempty"`
+
+	IdentifiedBy Identification `json:"identifiedBy,omitempty"`
+}
+
+// Purl return the package url of component, empty if it's not identified as a maven artifact.
+// see https://github.com/package-url/purl-spec
+func (c Component) Purl() string {
+	if c.Group == "" || c.Version == "" {
+		return ""
+	}
+	return fmt.Sprintf("pkg:maven/%s/%s@%s", c.Group, c.Name, c.Version)
+}
+
+// SBOM is the software bill of materials of biz bundle, with the biz itself as root component.
+type SBOM struct {
+	Bundle     fileutil.FileUrl `json:"bundle"`
+	Created    time.Time        `json:"created"`
+	Root       Component        `json:"root"`
+	Components []Component      `json:"components"`
+}
+
+// Generate generate the SBOM of biz bundle given by bizUrl, the embedded lib jars are recorded as components.
+func Generate(ctx context.Context, bizUrl fileutil.FileUrl) (sbom *SBOM, err error) {
+	defer runtime.RecoverFromError(&err)()
+
+	bundle := runtime.MustReturnResult(ark.OpenBundle(ctx, bizUrl))
+	defer bundle.Close()
+	manifest := runtime.MustReturnResult(bundle.Manifest())
+
+	sbom = &SBOM{
+		Bundle:  bizUrl,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Root: Component{
+			Name:    manifest["Ark-Biz-Name"],
+			Version: manifest["Ark-Biz-Version"],
+		},
+		Components: []Component{},
+	}
+	if sbom.Root.Name == "" {
+		return nil, fmt.Errorf("%s is not a biz bundle: Ark-Biz-Name not found in manifest", bizUrl)
+	}
+	// the tree digest of exploded directory is not a file hash, which is what sbom expects
+	if bundle.Type != ark.BundleTypeDirectory {
+		sbom.Root.Sha256 = strings.TrimPrefix(runtime.MustReturnResult(bundle.Digest()), "sha256:")
+	}
+	// the group of biz is only known if the biz module is packaged with its maven metadata
+	if pom, found := findPomProperties(bundle, sbom.Root.Name); found {
+		sbom.Root.Group = pom["groupId"]
+		sbom.Root.IdentifiedBy = IdentifiedByPom
+	}
+
+	runtime.Must(fs.WalkDir(bundle, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(name, ".jar") {
+			return err
+		}
+		content, err := fs.ReadFile(bundle, name)
+		if err != nil {
+			return err
+		}
+		sbom.Components = append(sbom.Components, identifyJar(name, content))
+		return nil
+	}))
+	sort.Slice(sbom.Components, func(i, j int) bool {
+		return sbom.Components[i].File < sbom.Components[j].File
+	})
+	return sbom, nil
+}
+
+// identifyJar identify the embedded jar by its maven metadata, or by its hashes if there is none.
+func identifyJar(name string, content []byte) Component {
+	sha1Sum, sha256Sum := sha1.Sum(content), sha256.Sum256(content)
+	component := Component{
+		File:   name,
+		Sha1:   hex.EncodeToString(sha1Sum[:]),
+		Sha256: hex.EncodeToString(sha256Sum[:]),
+	}
+
+	artifactId, version := ark.SplitJarName(path.Base(name))
+	if jar, err := zip.NewReader(bytes.NewReader(content), int64(len(content))); err == nil {
+		if pom, found := findPomProperties(jar, artifactId); found && pom["artifactId"] != "" {
+			component.Group = pom["groupId"]
+			component.Name = pom["artifactId"]
+			component.Version = pom["version"]
+			component.IdentifiedBy = IdentifiedByPom
+			return component
+		}
+	}
+
+	component.Name = artifactId
+	component.Version = version
+	component.IdentifiedBy = IdentifiedByHash
+	return component
+}
+
+// findPomProperties return the pom.properties of artifact in jar.
+// Shaded jars may contain several ones, the one of artifactId is preferred, otherwise the first one.
+func findPomProperties(fsys fs.FS, artifactId string) (map[string]string, bool) {
+	matches, err := fs.Glob(fsys, "META-INF/maven/*/*/pom.properties")
+	if err != nil || len(matches) == 0 {
+		return nil, false
+	}
+	sort.Strings(matches)
+	match := matches[0]
+	for _, candidate := range matches {
+		if path.Base(path.Dir(candidate)) == artifactId {
+			match = candidate
+			break
+		}
+	}
+
+	file, err := fsys.Open(match)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, false
+	}
+	return parseProperties(content), true
+}
+
+// parseProperties parse the simple key=value properties, which is what maven writes into pom.properties.
+func parseProperties(content []byte) map[string]string {
+	properties := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		separator := strings.IndexAny(line, "=:")
+		if separator < 0 {
+			continue
+		}
+		properties[strings.TrimSpace(line[:separator])] = strings.TrimSpace(line[separator+1:])
+	}
+	return properties
+}
+
+// Write write the SBOM in given format.
+func (s *SBOM) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatCycloneDXJson:
+		return s.WriteCycloneDX(w)
+	case FormatSpdxJson:
+		return s.WriteSpdx(w)
+	default:
+		return fmt.Errorf("unknown sbom format %s, should be one of %s and %s", format, FormatCycloneDXJson, FormatSpdxJson)
+	}
+}
@@ -0,0 +1,101 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/koupleless/arkctl/common/fileutil"
+	"github.com/koupleless/arkctl/common/ziptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockBizJar(t *testing.T) (fileutil.FileUrl, []byte) {
+	shaded := ziptest.Zip(t, map[string][]byte{
+		"META-INF/maven/com.relocated/inner/pom.properties": []byte("groupId=com.relocated\nartifactId=inner\nversion=0.1\n"),
+		"META-INF/maven/com.foo/foo-core/pom.properties":    []byte("#Generated by Maven\ngroupId=com.foo\nartifactId=foo-core\nversion=1.2.3\n"),
+	})
+	unknown := ziptest.Zip(t, map[string][]byte{"com/bar/Bar.class": []byte("bar")})
+	bizUrl := ziptest.BizJar(t, "biz-ark-biz.jar", map[string][]byte{
+		"META-INF/MANIFEST.MF":                      []byte("Ark-Biz-Name: biz\nArk-Biz-Version: 1.0.0\n"),
+		"META-INF/maven/com.biz/biz/pom.properties": []byte("groupId=com.biz\nartifactId=biz\nversion=1.0.0\n"),
+		"lib/foo-core-1.2.3.jar":                    shaded,
+		"lib/bar-utils-2.0.0-SNAPSHOT.jar":          unknown,
+		"com/biz/Biz.class":                         []byte("biz"),
+	})
+	return bizUrl, unknown
+}
+
+func TestGenerate(t *testing.T) {
+	bizUrl, unknown := mockBizJar(t)
+	sbom, err := Generate(context.Background(), bizUrl)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "pkg:maven/com.biz/biz@1.0.0", sbom.Root.Purl())
+	assert.NotEmpty(t, sbom.Root.Sha256)
+	assert.Equal(t, 2, len(sbom.Components))
+
+	unknownSha1 := sha1.Sum(unknown)
+	assert.Equal(t, "lib/bar-utils-2.0.0-SNAPSHOT.jar", sbom.Components[0].File)
+	assert.Equal(t, "bar-utils", sbom.Components[0].Name)
+	assert.Equal(t, "2.0.0-SNAPSHOT", sbom.Components[0].Version)
+	assert.Equal(t, IdentifiedByHash, sbom.Components[0].IdentifiedBy)
+	assert.Equal(t, hex.EncodeToString(unknownSha1[:]), sbom.Components[0].Sha1)
+	assert.Equal(t, "", sbom.Components[0].Purl())
+
+	assert.Equal(t, "lib/foo-core-1.2.3.jar", sbom.Components[1].File)
+	assert.Equal(t, IdentifiedByPom, sbom.Components[1].IdentifiedBy)
+	assert.Equal(t, "pkg:maven/com.foo/foo-core@1.2.3", sbom.Components[1].Purl())
+}
+
+func TestWrite(t *testing.T) {
+	bizUrl, _ := mockBizJar(t)
+	sbom, err := Generate(context.Background(), bizUrl)
+	assert.Nil(t, err)
+	sbom.Created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, sbom.Write(buf, FormatCycloneDXJson))
+	bom := &cyclonedxBom{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), bom))
+	assert.Equal(t, "CycloneDX", bom.BomFormat)
+	assert.Equal(t, "2024-01-01T00:00:00Z", bom.Metadata.Timestamp)
+	assert.Equal(t, "pkg:maven/com.biz/biz@1.0.0", bom.Metadata.Component.Purl)
+	assert.Equal(t, 2, len(bom.Components))
+	assert.Equal(t, []string{"lib:lib/bar-utils-2.0.0-SNAPSHOT.jar", "lib:lib/foo-core-1.2.3.jar"}, bom.Dependencies[0].DependsOn)
+
+	buf.Reset()
+	assert.Nil(t, sbom.Write(buf, FormatSpdxJson))
+	document := &spdxDocument{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), document))
+	assert.Equal(t, "SPDX-2.3", document.SpdxVersion)
+	assert.Equal(t, "biz-1.0.0", document.Name)
+	assert.Equal(t, 3, len(document.Packages))
+	assert.Equal(t, "SPDXRef-Lib-lib-foo-core-1.2.3.jar", document.Packages[2].SPDXID)
+	assert.Equal(t, "pkg:maven/com.foo/foo-core@1.2.3", document.Packages[2].ExternalRefs[0].ReferenceLocator)
+	assert.Equal(t, spdxRelationship{
+		SpdxElementId:      "SPDXRef-Biz-biz",
+		RelationshipType:   "CONTAINS",
+		RelatedSpdxElement: "SPDXRef-Lib-lib-foo-core-1.2.3.jar",
+	}, document.Relationships[2])
+
+	assert.NotNil(t, sbom.Write(buf, "xml"))
+}
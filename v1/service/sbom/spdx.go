@@ -0,0 +1,146 @@
+/**
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/koupleless/arkctl/v1/constant"
+
+	"github.com/google/uuid"
+)
+
+// spdx json format, see https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SpdxVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	PackageFileName  string            `json:"packageFileName,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SpdxElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+func (c Component) spdx(spdxId string) spdxPackage {
+	pkg := spdxPackage{
+		SPDXID:           spdxId,
+		Name:             c.Name,
+		VersionInfo:      c.Version,
+		PackageFileName:  c.File,
+		DownloadLocation: "NOASSERTION",
+	}
+	if c.Sha1 != "" {
+		pkg.Checksums = append(pkg.Checksums, spdxChecksum{Algorithm: "SHA1", ChecksumValue: c.Sha1})
+	}
+	if c.Sha256 != "" {
+		pkg.Checksums = append(pkg.Checksums, spdxChecksum{Algorithm: "SHA256", ChecksumValue: c.Sha256})
+	}
+	if purl := c.Purl(); purl != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		})
+	}
+	return pkg
+}
+
+// spdxId return the SPDX identifier of element, which only allows letters, numbers, dots and dashes.
+func spdxId(name string) string {
+	return "SPDXRef-" + strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// WriteSpdx write the SBOM in SPDX 2.3 json format.
+func (s *SBOM) WriteSpdx(w io.Writer) error {
+	name := s.Root.Name
+	if s.Root.Version != "" {
+		name += "-" + s.Root.Version
+	}
+	rootId := spdxId("Biz-" + s.Root.Name)
+	document := spdxDocument{
+		SpdxVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "https://koupleless.io/spdxdocs/" + name + "-" + uuid.NewString(),
+		CreationInfo: spdxCreationInfo{
+			Created:  s.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: arkctl-" + constant.Version},
+		},
+		Packages: []spdxPackage{s.Root.spdx(rootId)},
+		Relationships: []spdxRelationship{{
+			SpdxElementId:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSpdxElement: rootId,
+		}},
+	}
+	for _, component := range s.Components {
+		id := spdxId("Lib-" + component.File)
+		document.Packages = append(document.Packages, component.spdx(id))
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SpdxElementId:      rootId,
+			RelationshipType:   "CONTAINS",
+			RelatedSpdxElement: id,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}